@@ -0,0 +1,96 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Command cmpview is an interactive terminal viewer for the differences
+// between two JSON-encoded values.
+//
+// Usage:
+//
+//	cmpview [-ignore REGEXP] X.json Y.json
+//	cmpview [-ignore REGEXP] -report REPORT.json
+//
+// The two values are compared with cmp.Equal and presented as a tree of the
+// nodes that it compared, where every node is numbered and annotated with
+// the number of differences beneath it. Alternatively, the -report flag reads
+// the differences of an earlier comparison as serialized by cmp.DiffJSON,
+// in which case the tree only contains the differences.
+// Subtrees may be expanded and collapsed, paths may be searched, and nodes
+// whose path matches the -ignore regular expression are hidden
+// (and excluded from the differences) until shown with the "i" command.
+// Type "h" at the prompt for a list of commands.
+//
+// Paths are formatted as by cmp.Path, such as `["items"][3]["name"]`,
+// and the elements of arrays are aligned in the same way as by cmp.Diff,
+// such that `[?->3]` is an element that only exists in Y.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+)
+
+func main() {
+	ignore := flag.String("ignore", "", "regular expression of paths to ignore")
+	report := flag.String("report", "", "read the differences from a report produced by cmp.DiffJSON")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [-ignore REGEXP] X.json Y.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [-ignore REGEXP] -report REPORT.json\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if (*report == "" && flag.NArg() != 2) || (*report != "" && flag.NArg() != 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var rx *regexp.Regexp
+	if *ignore != "" {
+		var err error
+		if rx, err = regexp.Compile(*ignore); err != nil {
+			fatalf("invalid -ignore pattern: %v", err)
+		}
+	}
+	var root *node
+	if *report != "" {
+		var diffs []reportDifference
+		if err := decodeFile(*report, &diffs); err != nil {
+			fatalf("%v", err)
+		}
+		root = buildReportTree(diffs, rx)
+	} else {
+		var x, y interface{}
+		if err := decodeFile(flag.Arg(0), &x); err != nil {
+			fatalf("%v", err)
+		}
+		if err := decodeFile(flag.Arg(1), &y); err != nil {
+			fatalf("%v", err)
+		}
+		root = buildTree(x, y, rx)
+	}
+
+	v := newViewer(root, os.Stdout)
+	if err := v.run(os.Stdin); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func decodeFile(name string, v interface{}) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "cmpview: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,270 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// node is a node in the tree of a comparison between two values.
+type node struct {
+	name       string // Path step relative to the parent (e.g., `["key"]`)
+	path       string // Path from the root
+	x, y       string // Formatted values of the node
+	hasX, hasY bool   // Whether the value exists in x or y
+	equal      bool   // Whether the values are equal, excluding ignored nodes
+	ignored    bool   // Whether the node matches an ignore pattern
+	expanded   bool
+	parent     *node
+	children   []*node
+}
+
+// buildTree compares x and y with cmp and returns the root of the tree of
+// the nodes that cmp compared, such that the tree agrees with cmp.Diff
+// (e.g., in how the elements of arrays are aligned).
+// Nodes whose path matches ignore are ignored by the comparison.
+func buildTree(x, y interface{}, ignore *regexp.Regexp) *node {
+	b := new(treeBuilder)
+	opts := []cmp.Option{cmp.Reporter(b)}
+	if ignore != nil {
+		opts = append(opts, cmp.FilterPath(func(p cmp.Path) bool {
+			return len(p) > 1 && ignore.MatchString(pathName(p))
+		}, cmp.Ignore()))
+	}
+	cmp.Equal(x, y, opts...)
+	return b.root
+}
+
+// treeBuilder is a cmp reporter that builds the tree of a comparison.
+type treeBuilder struct {
+	root  *node
+	stack []*node // Current node for each step; repeated for elided steps
+	done  []bool  // Whether a result was reported for each node in stack
+}
+
+func (b *treeBuilder) PushStep(ps cmp.PathStep) {
+	if len(b.stack) == 0 {
+		b.root = newNode(nil, "", ps)
+		b.stack, b.done = append(b.stack, b.root), append(b.done, false)
+		return
+	}
+	parent := b.stack[len(b.stack)-1]
+	name, ok := stepName(ps)
+	if !ok {
+		b.stack, b.done = append(b.stack, parent), append(b.done, true)
+		return
+	}
+	n := newNode(parent, name, ps)
+	parent.children = append(parent.children, n)
+	b.stack, b.done = append(b.stack, n), append(b.done, false)
+}
+
+func (b *treeBuilder) Report(_ cmp.Path, r cmp.Result) {
+	n := b.stack[len(b.stack)-1]
+	n.equal = r.Equal()
+	n.ignored = r.ByIgnore()
+	for i := len(b.stack) - 1; i >= 0 && b.stack[i] == n; i-- {
+		b.done[i] = true
+	}
+}
+
+func (b *treeBuilder) PopStep() {
+	i := len(b.stack) - 1
+	if n := b.stack[i]; !b.done[i] {
+		n.equal = n.childrenEqual()
+	}
+	b.stack, b.done = b.stack[:i], b.done[:i]
+}
+
+func newNode(parent *node, name string, ps cmp.PathStep) *node {
+	n := &node{name: name, parent: parent}
+	if parent != nil {
+		n.path = parent.path + name
+	}
+	vx, vy := ps.Values()
+	n.x, n.hasX = formatValue(vx)
+	n.y, n.hasY = formatValue(vy)
+	return n
+}
+
+// formatValue formats v as JSON, and reports whether the value exists.
+func formatValue(v reflect.Value) (string, bool) {
+	if !v.IsValid() {
+		return "", false
+	}
+	if !v.CanInterface() {
+		return v.String(), true
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface()), true
+	}
+	return string(b), true
+}
+
+// stepName returns the name of ps within a path, as formatted by
+// the GoString method of cmp.Path. It reports false for steps that
+// cmp.Path elides, which are type assertions to unnamed types.
+func stepName(ps cmp.PathStep) (string, bool) {
+	if ta, ok := ps.(cmp.TypeAssertion); ok && ta.Type().PkgPath() == "" {
+		return "", false
+	}
+	return ps.String(), true
+}
+
+// pathName returns the path of the last step of p from the root.
+func pathName(p cmp.Path) string {
+	var s string
+	for _, ps := range p[1:] {
+		if name, ok := stepName(ps); ok {
+			s += name
+		}
+	}
+	return s
+}
+
+// reportDifference is a difference in a report produced by cmp.DiffJSON.
+type reportDifference struct {
+	Path string  `json:"path"`
+	Kind string  `json:"kind"`
+	Old  *string `json:"old"`
+	New  *string `json:"new"`
+}
+
+// buildReportTree returns the root of the tree of the differences in a report
+// produced by cmp.DiffJSON, which only contains the unequal nodes.
+// Nodes whose path matches ignore are marked as ignored.
+func buildReportTree(diffs []reportDifference, ignore *regexp.Regexp) *node {
+	root := &node{name: "", equal: true}
+	for _, d := range diffs {
+		n := root
+		for _, name := range splitPath(d.Path) {
+			var c *node
+			for _, cc := range n.children {
+				if cc.name == name {
+					c = cc
+				}
+			}
+			if c == nil {
+				c = &node{name: name, path: n.path + name, parent: n}
+				c.ignored = ignore != nil && ignore.MatchString(c.path)
+				n.children = append(n.children, c)
+			}
+			n = c
+		}
+		if d.Old != nil {
+			n.x, n.hasX = *d.Old, true
+		}
+		if d.New != nil {
+			n.y, n.hasY = *d.New, true
+		}
+	}
+	var markEqual func(n *node)
+	markEqual = func(n *node) {
+		for _, c := range n.children {
+			markEqual(c)
+		}
+		n.equal = len(n.children) > 0 && n.childrenEqual()
+		if n == root && len(n.children) == 0 {
+			n.equal = true
+		}
+	}
+	markEqual(root)
+	return root
+}
+
+// splitPath splits the GoString of a cmp.Path into the names of its steps,
+// excluding the root. Indexing steps (e.g., `["key"]` or `[1->2]`) and
+// selector steps (e.g., ".Field" or ".(T)") are split, while any other
+// syntax (e.g., indirections and transformations) is kept as a single step.
+func splitPath(s string) []string {
+	switch {
+	case strings.HasPrefix(s, "root"):
+		s = s[len("root"):]
+	case strings.HasPrefix(s, "{"):
+		if i := strings.IndexByte(s, '}'); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	var names []string
+	for len(s) > 0 {
+		n := stepLen(s)
+		if n <= 0 {
+			return append(names, s)
+		}
+		names = append(names, s[:n])
+		s = s[n:]
+	}
+	return names
+}
+
+// stepLen returns the length of the indexing or selector step
+// at the start of s, or zero if there is none.
+func stepLen(s string) int {
+	switch {
+	case strings.HasPrefix(s, ".("):
+		if i := strings.IndexByte(s, ')'); i >= 0 {
+			return i + 1
+		}
+	case strings.HasPrefix(s, "."):
+		i := 1
+		for i < len(s) && s[i] != '.' && s[i] != '[' && s[i] != '(' && s[i] != ')' {
+			i++
+		}
+		return i
+	case strings.HasPrefix(s, "["):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '"':
+				for i++; i < len(s) && s[i] != '"'; i++ {
+					if s[i] == '\\' {
+						i++
+					}
+				}
+			case ']':
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+func (n *node) childrenEqual() bool {
+	for _, c := range n.children {
+		if !c.equal && !c.ignored {
+			return false
+		}
+	}
+	return true
+}
+
+// numDiffs reports the number of unequal leaf nodes that are not ignored.
+func (n *node) numDiffs() int {
+	if n.ignored || n.equal {
+		return 0
+	}
+	if len(n.children) == 0 {
+		return 1
+	}
+	var nd int
+	for _, c := range n.children {
+		nd += c.numDiffs()
+	}
+	return nd
+}
+
+// walk calls f on n and all of its descendants in depth-first order.
+func (n *node) walk(f func(*node)) {
+	f(n)
+	for _, c := range n.children {
+		c.walk(f)
+	}
+}
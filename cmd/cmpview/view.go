@@ -0,0 +1,161 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const helpText = `commands:
+  p           print the tree
+  e N         expand node N
+  c N         collapse node N
+  d           expand every node with differences and collapse the rest
+  /TEXT       search for nodes whose path contains TEXT
+  i           toggle showing ignored nodes
+  h           show this help
+  q           quit
+`
+
+// viewer is an interactive, line-oriented view of a comparison tree.
+type viewer struct {
+	root        *node
+	out         io.Writer
+	showIgnored bool
+	lines       []*node // Nodes in the order of the last printed tree
+}
+
+func newViewer(root *node, out io.Writer) *viewer {
+	root.expanded = true
+	return &viewer{root: root, out: out}
+}
+
+// exec executes a single command and reports whether to quit.
+func (v *viewer) exec(cmd string) (quit bool) {
+	cmd = strings.TrimSpace(cmd)
+	switch {
+	case cmd == "" || cmd == "p":
+		v.print()
+	case cmd == "q":
+		return true
+	case cmd == "h" || cmd == "?":
+		fmt.Fprint(v.out, helpText)
+	case cmd == "i":
+		v.showIgnored = !v.showIgnored
+		v.print()
+	case cmd == "d":
+		v.root.walk(func(n *node) { n.expanded = !n.equal && !n.ignored })
+		v.root.expanded = true
+		v.print()
+	case strings.HasPrefix(cmd, "/"):
+		v.search(cmd[1:])
+	case strings.HasPrefix(cmd, "e ") || strings.HasPrefix(cmd, "c "):
+		i, err := strconv.Atoi(strings.TrimSpace(cmd[2:]))
+		if err != nil || i < 0 || i >= len(v.lines) {
+			fmt.Fprintf(v.out, "invalid node number: %s\n", cmd[2:])
+			return false
+		}
+		v.lines[i].expanded = cmd[0] == 'e'
+		v.print()
+	default:
+		fmt.Fprintf(v.out, "unknown command: %q (type h for help)\n", cmd)
+	}
+	return false
+}
+
+// print prints the expanded portion of the tree, numbering each node.
+func (v *viewer) print() {
+	v.lines = v.lines[:0]
+	var printNode func(n *node, depth int)
+	printNode = func(n *node, depth int) {
+		if n.ignored && !v.showIgnored {
+			return
+		}
+		mark := " "
+		if len(n.children) > 0 {
+			mark = "+"
+			if n.expanded {
+				mark = "-"
+			}
+		}
+		name := n.name
+		if n.parent == nil {
+			name = "(root)"
+		}
+		line := fmt.Sprintf("%4d %s%s %s %s", len(v.lines), strings.Repeat("  ", depth), mark, name, v.summary(n))
+		fmt.Fprintln(v.out, strings.TrimRight(line, " "))
+		v.lines = append(v.lines, n)
+		if n.expanded {
+			for _, c := range n.children {
+				printNode(c, depth+1)
+			}
+		}
+	}
+	printNode(v.root, 0)
+}
+
+func (v *viewer) summary(n *node) string {
+	var s string
+	switch {
+	case n.ignored:
+		s = "(ignored) "
+	case len(n.children) > 0 && !n.equal:
+		if nd := n.numDiffs(); nd == 1 {
+			s = "(1 difference) "
+		} else {
+			s = fmt.Sprintf("(%d differences) ", nd)
+		}
+	}
+	switch {
+	case len(n.children) > 0 || (!n.hasX && !n.hasY):
+		return strings.TrimSpace(s)
+	case n.equal && (!n.ignored || n.x == n.y):
+		return s + format(n.x, n.hasX)
+	default:
+		return s + fmt.Sprintf("-: %s +: %s", format(n.x, n.hasX), format(n.y, n.hasY))
+	}
+}
+
+func format(s string, ok bool) string {
+	if !ok {
+		return "<missing>"
+	}
+	return s
+}
+
+// search prints every node whose path contains text and expands their
+// ancestors so that they are visible when the tree is printed.
+func (v *viewer) search(text string) {
+	var found int
+	v.root.walk(func(n *node) {
+		if n.parent == nil || !strings.Contains(n.path, text) || (n.ignored && !v.showIgnored) {
+			return
+		}
+		found++
+		fmt.Fprintln(v.out, strings.TrimRight(n.path+" "+v.summary(n), " "))
+		for p := n.parent; p != nil; p = p.parent {
+			p.expanded = true
+		}
+	})
+	fmt.Fprintf(v.out, "%d matches\n", found)
+}
+
+// run executes commands read from in until the input is exhausted or
+// the quit command is given.
+func (v *viewer) run(in io.Reader) error {
+	v.print()
+	sc := bufio.NewScanner(in)
+	for fmt.Fprint(v.out, "> "); sc.Scan(); fmt.Fprint(v.out, "> ") {
+		if v.exec(sc.Text()) {
+			return nil
+		}
+	}
+	fmt.Fprintln(v.out)
+	return sc.Err()
+}
@@ -0,0 +1,165 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func mustDecode(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestBuildTree(t *testing.T) {
+	x := mustDecode(t, `{"name":"a","items":[1,2],"meta":{"t":1},"same":[1,2]}`)
+	y := mustDecode(t, `{"name":"b","items":[1,3,4],"meta":{"t":2},"same":[1,2]}`)
+
+	root := buildTree(x, y, nil)
+	if root.equal || root.numDiffs() != 4 {
+		t.Errorf("root = (equal %v, %d differences), want (false, 4 differences)", root.equal, root.numDiffs())
+	}
+	root = buildTree(x, y, regexp.MustCompile(`^\["meta"\]`))
+	if root.equal || root.numDiffs() != 3 {
+		t.Errorf("root = (equal %v, %d differences), want (false, 3 differences)", root.equal, root.numDiffs())
+	}
+	root = buildTree(x, x, nil)
+	if !root.equal || root.numDiffs() != 0 {
+		t.Errorf("root = (equal %v, %d differences), want (true, 0 differences)", root.equal, root.numDiffs())
+	}
+
+	var paths []string
+	buildTree(x, y, nil).walk(func(n *node) { paths = append(paths, n.path) })
+	got := strings.Join(paths, " ")
+	want := ` ["items"] ["items"][0] ["items"][1] ["items"][?->2] ["meta"] ["meta"]["t"] ["name"] ["same"] ["same"][0] ["same"][1]`
+	if got != want {
+		t.Errorf("paths:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestBuildTreeInsertion(t *testing.T) {
+	// The elements of arrays are aligned as by cmp.Diff, such that inserting
+	// an element does not report every subsequent element as a difference.
+	x := mustDecode(t, `{"items":[{"id":1},{"id":2},{"id":3}]}`)
+	y := mustDecode(t, `{"items":[{"id":0},{"id":1},{"id":2},{"id":3}]}`)
+
+	var diffs []string
+	buildTree(x, y, nil).walk(func(n *node) {
+		if !n.equal && len(n.children) == 0 {
+			diffs = append(diffs, n.path)
+		}
+	})
+	got := strings.Join(diffs, " ")
+	want := `["items"][?->0]`
+	if got != want {
+		t.Errorf("differences:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestBuildReportTree(t *testing.T) {
+	x := mustDecode(t, `{"name":"a","items":[1,2],"meta":{"t":1},"same":[1,2]}`)
+	y := mustDecode(t, `{"name":"b","items":[1,3,4],"meta":{"t":2},"same":[1,2]}`)
+	b, err := cmp.DiffJSON(x, y)
+	if err != nil {
+		t.Fatalf("DiffJSON error: %v", err)
+	}
+	var diffs []reportDifference
+	if err := json.Unmarshal(b, &diffs); err != nil {
+		t.Fatal(err)
+	}
+
+	root := buildReportTree(diffs, nil)
+	if root.equal || root.numDiffs() != 4 {
+		t.Errorf("root = (equal %v, %d differences), want (false, 4 differences)", root.equal, root.numDiffs())
+	}
+	root = buildReportTree(diffs, regexp.MustCompile(`^\["meta"\]`))
+	if root.equal || root.numDiffs() != 3 {
+		t.Errorf("root = (equal %v, %d differences), want (false, 3 differences)", root.equal, root.numDiffs())
+	}
+
+	var paths []string
+	root.walk(func(n *node) {
+		paths = append(paths, strings.TrimSpace(n.path+" "+format(n.x, n.hasX)+" "+format(n.y, n.hasY)))
+	})
+	got := strings.Join(paths, "\n")
+	want := strings.Join([]string{
+		`<missing> <missing>`,
+		`["items"] <missing> <missing>`,
+		`["items"][1] 2 3`,
+		`["items"][?->2] <missing> 4`,
+		`["meta"] <missing> <missing>`,
+		`["meta"]["t"] 1 2`,
+		`["name"] "a" "b"`,
+	}, "\n")
+	if got != want {
+		t.Errorf("paths:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"root", nil},
+		{`root["a]"][1][?->2].Field.(main.T)`, []string{`["a]"]`, "[1]", "[?->2]", ".Field", ".(main.T)"}},
+		{`{main.T}.Field["\"]"]`, []string{".Field", `["\"]"]`}},
+		{`root.F(*tag).G`, []string{".F", "(*tag).G"}},
+	}
+	for _, tt := range tests {
+		if got := splitPath(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestViewer(t *testing.T) {
+	x := mustDecode(t, `{"name":"a","items":[{"id":1},{"id":2}],"meta":{"t":1}}`)
+	y := mustDecode(t, `{"name":"b","items":[{"id":1},{"id":3}],"meta":{"t":2}}`)
+
+	var out bytes.Buffer
+	v := newViewer(buildTree(x, y, regexp.MustCompile(`meta`)), &out)
+	if err := v.run(strings.NewReader("d\n/id\ni\nc 0\ne 9\nq\np\n")); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+	want := strings.Join([]string{
+		`   0 - (root) (2 differences)`,
+		`   1   + ["items"] (1 difference)`,
+		`   2     ["name"] -: "a" +: "b"`,
+		`>    0 - (root) (2 differences)`,
+		`   1   - ["items"] (1 difference)`,
+		`   2     + [0]`,
+		`   3     - [1] (1 difference)`,
+		`   4         ["id"] -: 2 +: 3`,
+		`   5     ["name"] -: "a" +: "b"`,
+		`> ["items"][0]["id"] 1`,
+		`["items"][1]["id"] -: 2 +: 3`,
+		`2 matches`,
+		`>    0 - (root) (2 differences)`,
+		`   1   - ["items"] (1 difference)`,
+		`   2     - [0]`,
+		`   3         ["id"] 1`,
+		`   4     - [1] (1 difference)`,
+		`   5         ["id"] -: 2 +: 3`,
+		`   6     ["meta"] (ignored) -: {"t":1} +: {"t":2}`,
+		`   7     ["name"] -: "a" +: "b"`,
+		`>    0 + (root) (2 differences)`,
+		`> invalid node number: 9`,
+		`> `,
+	}, "\n")
+	if got := out.String(); got != want {
+		t.Errorf("output:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+// AuditIgnored returns an Option that calls f for every node in the value tree
+// that is skipped because of an Ignore option, such that test suites can audit
+// exactly which values their comparisons do not check.
+//
+// The opt passed to f is the top-level option that caused the node to be
+// ignored, such as the FilterPath option returned by cmpopts.IgnoreFields,
+// which identifies the reason through its String method. None of the
+// sub-values of an ignored node are visited. See Path regarding retaining p.
+func AuditIgnored(f func(p Path, opt Option)) Option {
+	if f == nil {
+		panic("invalid nil audit function")
+	}
+	return reporter(auditReporter(f))
+}
+
+// ignoreReporter is implemented by reporters that need to know which option
+// ignored a node. ReportIgnored is called immediately after Report for
+// every ignored node.
+type ignoreReporter interface {
+	ReportIgnored(Path, Option)
+}
+
+type auditReporter func(Path, Option)
+
+func (auditReporter) PushStep(PathStep)                  {}
+func (auditReporter) Report(Path, reportFlags)           {}
+func (r auditReporter) ReportIgnored(p Path, opt Option) { r(p, opt) }
+func (auditReporter) PopStep()                           {}
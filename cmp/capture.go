@@ -0,0 +1,72 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "reflect"
+
+// Capture is a recording of the inputs to a failed comparison,
+// as produced by the CaptureFailures option.
+type Capture struct {
+	// X and Y are the encoded forms of the inputs to the comparison.
+	X, Y []byte
+
+	// Diff is the report of the differences, as would be returned by Diff.
+	Diff string
+
+	// Err is the first error that occurred while encoding the inputs.
+	// If non-nil, then X or Y may be incomplete.
+	Err error
+}
+
+// Decode decodes the captured inputs into the values pointed to by x and y
+// using the provided decoder (e.g., json.Unmarshal), such that the comparison
+// can be re-run with different options or inspected offline.
+func (c Capture) Decode(dec func(data []byte, v interface{}) error, x, y interface{}) error {
+	if err := dec(c.X, x); err != nil {
+		return err
+	}
+	return dec(c.Y, y)
+}
+
+// CaptureFailures returns an Option that captures the inputs to Equal and Diff
+// when the compared values are not equal. The inputs are encoded using enc
+// (e.g., json.Marshal or a gob-based encoder) and passed, along with a report
+// of the differences, to save, which may persist the Capture for later analysis.
+// This is intended for investigating failures that are hard to reproduce,
+// such as those of flaky tests.
+//
+// The encoder must be able to encode every input value that is compared.
+// Encoding errors are recorded in the Capture rather than causing a panic.
+func CaptureFailures(enc func(v interface{}) ([]byte, error), save func(Capture)) Option {
+	if enc == nil || save == nil {
+		panic("invalid nil encoder or save function")
+	}
+	return captureOption{enc, save}
+}
+
+type captureOption struct {
+	enc  func(interface{}) ([]byte, error)
+	save func(Capture)
+}
+
+func (captureOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+// capture calls every CaptureFailures option if x and y are not equal.
+func (s *state) capture(x, y interface{}) {
+	if len(s.captures) == 0 || s.result.Equal() {
+		return
+	}
+	for _, opt := range s.captures {
+		c := Capture{Diff: s.captureDiff.String()}
+		c.X, c.Err = opt.enc(x)
+		var err error
+		if c.Y, err = opt.enc(y); c.Err == nil {
+			c.Err = err
+		}
+		opt.save(c)
+	}
+}
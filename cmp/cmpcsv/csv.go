@@ -0,0 +1,175 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmpcsv provides a helper for comparing CSV content,
+// reporting differences by row and column.
+package cmpcsv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Option configures how Diff parses and compares CSV content.
+type Option func(*config)
+
+type config struct {
+	header     bool               // Whether the first record names the columns
+	trimSpace  bool               // Whether to trim whitespace around fields
+	lazyQuotes bool               // Whether to tolerate malformed quotes
+	margins    map[string]float64 // Numeric tolerance keyed by column name
+}
+
+// MatchHeader returns an Option that treats the first record as a header
+// that names the columns. Columns are then matched by name rather than by
+// position, and are identified by name in the report.
+func MatchHeader() Option {
+	return func(c *config) { c.header = true }
+}
+
+// TrimSpace returns an Option that ignores leading and trailing white space
+// in every field.
+func TrimSpace() Option {
+	return func(c *config) { c.trimSpace = true }
+}
+
+// LazyQuotes returns an Option that tolerates quotes appearing in unquoted
+// fields and non-doubled quotes appearing in quoted fields,
+// as with the LazyQuotes field of csv.Reader.
+func LazyQuotes() Option {
+	return func(c *config) { c.lazyQuotes = true }
+}
+
+// NumericTolerance returns an Option that determines two fields in the named
+// column to be equal if both are numbers that are within margin of each other.
+// Fields that are not numbers are compared as strings. The column is named by
+// its header if MatchHeader is used; otherwise, it is named by its zero-based
+// position (e.g., "2").
+//
+// NumericTolerance panics if the margin is negative or NaN.
+func NumericTolerance(column string, margin float64) Option {
+	if margin < 0 || math.IsNaN(margin) {
+		panic("margin must be a non-negative number")
+	}
+	return func(c *config) { c.margins[column] = margin }
+}
+
+// Diff parses x and y as CSV and returns a human-readable report of the fields
+// that differ, or an empty string if the content is equal. Each of x and y may
+// be a string, a []byte, or an io.Reader of CSV content, or it may be
+// an already parsed [][]string. Records may have differing numbers of fields.
+//
+// Differences are reported by the zero-based index of the row (excluding any
+// header) and by the column name, as determined by MatchHeader.
+// An error is returned if either input cannot be parsed, or if a header
+// names more than one column the same.
+func Diff(x, y interface{}, opts ...Option) (string, error) {
+	c := config{margins: make(map[string]float64)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	rx, err := c.parse(x)
+	if err != nil {
+		return "", err
+	}
+	ry, err := c.parse(y)
+	if err != nil {
+		return "", err
+	}
+	var cmpOpts cmp.Options
+	for col, margin := range c.margins {
+		cmpOpts = append(cmpOpts, cmp.FilterPath(isColumn(col), cmp.Comparer(equateNumbers(margin))))
+	}
+	rowsX, err := c.rows(rx)
+	if err != nil {
+		return "", err
+	}
+	rowsY, err := c.rows(ry)
+	if err != nil {
+		return "", err
+	}
+	return cmp.Diff(rowsX, rowsY, cmpOpts), nil
+}
+
+func (c config) parse(v interface{}) ([][]string, error) {
+	var r io.Reader
+	switch v := v.(type) {
+	case string:
+		r = strings.NewReader(v)
+	case []byte:
+		r = bytes.NewReader(v)
+	case io.Reader:
+		r = v
+	case [][]string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cmpcsv: invalid CSV input type: %T", v)
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = c.lazyQuotes
+	return cr.ReadAll()
+}
+
+// rows converts records into rows that map column names to fields.
+func (c config) rows(records [][]string) ([]map[string]string, error) {
+	var names []string
+	if c.header && len(records) > 0 {
+		seen := make(map[string]bool)
+		for _, name := range records[0] {
+			name = c.field(name)
+			if seen[name] {
+				return nil, fmt.Errorf("cmpcsv: duplicate column name in header: %q", name)
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+		records = records[1:]
+	}
+	rows := []map[string]string{}
+	for _, rec := range records {
+		row := make(map[string]string)
+		for i, f := range rec {
+			name := strconv.Itoa(i)
+			if i < len(names) {
+				name = names[i]
+			}
+			row[name] = c.field(f)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (c config) field(s string) string {
+	if c.trimSpace {
+		return strings.TrimSpace(s)
+	}
+	return s
+}
+
+func isColumn(col string) func(cmp.Path) bool {
+	return func(p cmp.Path) bool {
+		mi, ok := p.Last().(cmp.MapIndex)
+		return ok && mi.Key().String() == col
+	}
+}
+
+func equateNumbers(margin float64) func(x, y string) bool {
+	return func(x, y string) bool {
+		fx, errx := strconv.ParseFloat(x, 64)
+		fy, erry := strconv.ParseFloat(y, 64)
+		if x == y || errx != nil || erry != nil {
+			return x == y
+		}
+		return math.Abs(fx-fy) <= margin
+	}
+}
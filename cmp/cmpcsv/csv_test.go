@@ -0,0 +1,121 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpcsv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		label    string
+		x, y     interface{}
+		opts     []Option
+		wantDiff []string // Substrings of the report; empty if equal
+		wantErr  bool
+	}{{
+		label: "Equal",
+		x:     "name,price\napple,1.25\n",
+		y:     []byte("name,price\napple,1.25\n"),
+	}, {
+		label: "Parsed",
+		x:     strings.NewReader("name,price\napple,1.25\n"),
+		y:     [][]string{{"name", "price"}, {"apple", "1.25"}},
+	}, {
+		label:    "Position",
+		x:        "name,price\napple,1.25\n",
+		y:        "name,price\napple,1.50\n",
+		wantDiff: []string{`[1]["1"]`, `"1.25"`, `"1.50"`},
+	}, {
+		label:    "MatchHeader",
+		x:        "name,price\napple,1.25\n",
+		y:        "price,name\n1.50,apple\n",
+		opts:     []Option{MatchHeader()},
+		wantDiff: []string{`[0]["price"]`, `"1.25"`, `"1.50"`},
+	}, {
+		label: "MatchHeader",
+		x:     "name,price\napple,1.25\n",
+		y:     "price,name\n1.25,apple\n",
+		opts:  []Option{MatchHeader()},
+	}, {
+		label:    "Whitespace",
+		x:        "name, price\napple, 1.25\n",
+		y:        "name,price\napple,1.25\n",
+		opts:     []Option{MatchHeader()},
+		wantDiff: []string{`[0][" price"]`},
+	}, {
+		label: "TrimSpace",
+		x:     "name, price\napple, 1.25\n",
+		y:     "name,price\napple,1.25\n",
+		opts:  []Option{MatchHeader(), TrimSpace()},
+	}, {
+		label:   "Quotes",
+		x:       "name\na\"b\n",
+		y:       "name\na\"b\n",
+		wantErr: true,
+	}, {
+		label: "LazyQuotes",
+		x:     "name\na\"b\n",
+		y:     "name\na\"b\n",
+		opts:  []Option{LazyQuotes()},
+	}, {
+		label: "NumericTolerance",
+		x:     "name,price,qty\napple,1.25,3\npear,n/a,4\n",
+		y:     "name,price,qty\napple,1.2500001,3\npear,n/a,4\n",
+		opts:  []Option{MatchHeader(), NumericTolerance("price", 0.001)},
+	}, {
+		label:    "NumericTolerance",
+		x:        "name,price,qty\napple,1.25,3\n",
+		y:        "name,price,qty\napple,1.5,3.0000001\n",
+		opts:     []Option{MatchHeader(), NumericTolerance("price", 0.001), NumericTolerance("qty", 0.001)},
+		wantDiff: []string{`[0]["price"]`},
+	}, {
+		label: "NumericToleranceSpecialValues",
+		x:     "a,b\nNaN,Inf\n",
+		y:     "a,b\nNaN,Inf\n",
+		opts:  []Option{MatchHeader(), NumericTolerance("a", 0.1), NumericTolerance("b", 0.1)},
+	}, {
+		label:   "DuplicateHeader",
+		x:       "x,x\n1,2\n",
+		y:       "x,x\n9,2\n",
+		opts:    []Option{MatchHeader()},
+		wantErr: true,
+	}, {
+		label:   "InvalidType",
+		x:       5,
+		y:       "",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			gotDiff, err := Diff(tt.x, tt.y, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Diff error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(tt.wantDiff) == 0 && gotDiff != "" {
+				t.Errorf("Diff reported differences:\n%s", gotDiff)
+			}
+			for _, want := range tt.wantDiff {
+				if !strings.Contains(gotDiff, want) {
+					t.Errorf("Diff report missing %q:\n%s", want, gotDiff)
+				}
+			}
+			if strings.Contains(gotDiff, `["qty"]`) {
+				t.Errorf("Diff reported field within tolerance:\n%s", gotDiff)
+			}
+		})
+	}
+}
+
+func TestNumericTolerancePanic(t *testing.T) {
+	defer func() {
+		if ex := recover(); ex == nil {
+			t.Errorf("NumericTolerance did not panic")
+		}
+	}()
+	NumericTolerance("price", -1)
+}
@@ -0,0 +1,145 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmpfs provides helpers for comparing files, file trees, and
+// file metadata, for tests that produce on-disk artifacts.
+package cmpfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// DiffFiles compares the contents of the files named x and y and returns a
+// human-readable report of the first difference, or an empty string if the
+// contents are identical. The report includes the byte offset and line of the
+// first difference. The files are streamed rather than read into memory,
+// so arbitrarily large files may be compared.
+//
+// An error is returned if either file cannot be read.
+func DiffFiles(x, y string) (string, error) {
+	fx, err := os.Open(x)
+	if err != nil {
+		return "", err
+	}
+	defer fx.Close()
+	fy, err := os.Open(y)
+	if err != nil {
+		return "", err
+	}
+	defer fy.Close()
+	return diffReaders(fx, fy)
+}
+
+// maxContext is the maximum number of bytes printed for each side
+// at the first difference.
+const maxContext = 32
+
+// diffReaders is the implementation of DiffFiles for arbitrary readers.
+func diffReaders(x, y io.Reader) (string, error) {
+	rx, ry := bufio.NewReader(x), bufio.NewReader(y)
+	var offset, line int64 = 0, 1
+	for {
+		bx, errx := rx.ReadByte()
+		if errx != nil && errx != io.EOF {
+			return "", errx
+		}
+		by, erry := ry.ReadByte()
+		if erry != nil && erry != io.EOF {
+			return "", erry
+		}
+		switch {
+		case errx == io.EOF && erry == io.EOF:
+			return "", nil
+		case errx == io.EOF || erry == io.EOF || bx != by:
+			sx := formatContext(rx, bx, errx)
+			sy := formatContext(ry, by, erry)
+			return fmt.Sprintf("contents differ at byte offset %d (line %d):\n\t-: %s\n\t+: %s\n", offset, line, sx, sy), nil
+		}
+		offset++
+		if bx == '\n' {
+			line++
+		}
+	}
+}
+
+// formatContext formats the bytes starting at the first difference,
+// where b is the byte already read from r.
+func formatContext(r *bufio.Reader, b byte, err error) string {
+	if err == io.EOF {
+		return "EOF"
+	}
+	buf, _ := r.Peek(maxContext - 1)
+	s := fmt.Sprintf("%q", append([]byte{b}, buf...))
+	if len(buf) == maxContext-1 {
+		s += "..."
+	}
+	return s
+}
+
+// FileMeta is the subset of the metadata of an os.FileInfo that
+// is compared by EquateFileInfo.
+type FileMeta struct {
+	Name    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+}
+
+// EquateFileInfo returns a Transformer option that compares os.FileInfo values
+// (and thus, fs.FileInfo values) by only the named fields of FileMeta,
+// which may be any of "Name", "Size", "Mode", or "ModTime".
+// If no fields are specified, then Name, Size, and Mode are compared,
+// and times are ignored. The system-dependent data reported by Sys is
+// never compared. A nil os.FileInfo is transformed into a nil *FileMeta.
+//
+// EquateFileInfo panics if an unknown field is specified.
+func EquateFileInfo(fields ...string) cmp.Option {
+	if len(fields) == 0 {
+		fields = []string{"Name", "Size", "Mode"}
+	}
+	var m fileMask
+	for _, f := range fields {
+		switch f {
+		case "Name":
+			m.name = true
+		case "Size":
+			m.size = true
+		case "Mode":
+			m.mode = true
+		case "ModTime":
+			m.modTime = true
+		default:
+			panic(fmt.Sprintf("invalid FileMeta field: %q", f))
+		}
+	}
+	return cmp.Transformer("cmpfs.EquateFileInfo", m.meta)
+}
+
+type fileMask struct{ name, size, mode, modTime bool }
+
+func (m fileMask) meta(fi os.FileInfo) *FileMeta {
+	if fi == nil {
+		return nil
+	}
+	var fm FileMeta
+	if m.name {
+		fm.Name = fi.Name()
+	}
+	if m.size {
+		fm.Size = fi.Size()
+	}
+	if m.mode {
+		fm.Mode = fi.Mode()
+	}
+	if m.modTime {
+		fm.ModTime = fi.ModTime()
+	}
+	return &fm
+}
@@ -0,0 +1,125 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmpfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0664); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	long := strings.Repeat("0123456789", 10000)
+
+	tests := []struct {
+		label    string
+		x, y     string
+		wantDiff string
+	}{{
+		label: "Equal",
+		x:     "hello\nworld\n",
+		y:     "hello\nworld\n",
+	}, {
+		label: "Empty",
+	}, {
+		label:    "Differ",
+		x:        "hello\nworld\n",
+		y:        "hello\nWorld\n",
+		wantDiff: "contents differ at byte offset 6 (line 2):\n\t-: \"world\\n\"\n\t+: \"World\\n\"\n",
+	}, {
+		label:    "Shorter",
+		x:        "hello",
+		y:        "hello\n",
+		wantDiff: "contents differ at byte offset 5 (line 1):\n\t-: EOF\n\t+: \"\\n\"\n",
+	}, {
+		label:    "Long",
+		x:        long + "a" + long,
+		y:        long + "b" + long,
+		wantDiff: "contents differ at byte offset 100000 (line 1):\n\t-: \"a0123456789012345678901234567890\"...\n\t+: \"b0123456789012345678901234567890\"...\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			gotDiff, err := DiffFiles(write("x", tt.x), write("y", tt.y))
+			if err != nil {
+				t.Fatalf("DiffFiles error: %v", err)
+			}
+			if gotDiff != tt.wantDiff {
+				t.Errorf("DiffFiles:\ngot:\n%s\nwant:\n%s", gotDiff, tt.wantDiff)
+			}
+		})
+	}
+
+	if _, err := DiffFiles(filepath.Join(dir, "missing"), write("y", "")); !os.IsNotExist(err) {
+		t.Errorf("DiffFiles error = %v, want not exist error", err)
+	}
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() interface{}   { return nil }
+
+func TestEquateFileInfo(t *testing.T) {
+	now := time.Now()
+	x := []os.FileInfo{fileInfo{"a.txt", 5, 0644, now}, nil}
+	y := []os.FileInfo{fileInfo{"a.txt", 5, 0644, now.Add(time.Hour)}, nil}
+	z := []os.FileInfo{fileInfo{"a.txt", 6, 0644, now}, nil}
+
+	tests := []struct {
+		label     string
+		x, y      []os.FileInfo
+		fields    []string
+		wantEqual bool
+	}{
+		{label: "IgnoreTimes", x: x, y: y, wantEqual: true},
+		{label: "Size", x: x, y: z, wantEqual: false},
+		{label: "ModTime", x: x, y: y, fields: []string{"ModTime"}, wantEqual: false},
+		{label: "NameOnly", x: x, y: z, fields: []string{"Name"}, wantEqual: true},
+		{label: "Nil", x: x, y: []os.FileInfo{x[0], x[0]}, wantEqual: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := cmp.Equal(tt.x, tt.y, EquateFileInfo(tt.fields...)); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v", got, tt.wantEqual)
+			}
+		})
+	}
+
+	func() {
+		defer func() {
+			if ex := recover(); ex == nil || !strings.Contains(ex.(string), "invalid FileMeta field") {
+				t.Errorf("EquateFileInfo panic = %v, want invalid FileMeta field", ex)
+			}
+		}()
+		EquateFileInfo("Sys")
+	}()
+}
@@ -0,0 +1,110 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// +build go1.16
+
+package cmpfs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TreeOption configures how DiffFS compares two file trees.
+type TreeOption func(*treeConfig)
+
+type treeConfig struct {
+	ignore    []string // Patterns of paths to ignore
+	normalize bool     // Whether to normalize line endings
+}
+
+// IgnorePaths returns a TreeOption that ignores every file or directory whose
+// slash-separated path (relative to the root of the tree) matches any of the
+// patterns, using the syntax of path.Match. Ignoring a directory ignores all of
+// the files within it.
+//
+// IgnorePaths panics if a pattern is malformed.
+func IgnorePaths(patterns ...string) TreeOption {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			panic("invalid path pattern: " + p)
+		}
+	}
+	return func(c *treeConfig) { c.ignore = append(c.ignore, patterns...) }
+}
+
+// NormalizeLineEndings returns a TreeOption that treats "\r\n" line endings
+// as equivalent to "\n" line endings.
+func NormalizeLineEndings() TreeOption {
+	return func(c *treeConfig) { c.normalize = true }
+}
+
+// DiffFS compares the regular files in the trees x and y (e.g., embedded test
+// data against generated output) and returns a human-readable report of the
+// differences, or an empty string if the trees are equal. Files that exist in
+// only one of the trees are reported, as are the lines that differ between
+// files that exist in both. Directories are only compared by the files they
+// contain; empty directories are ignored.
+//
+// An error is returned if either tree cannot be read.
+func DiffFS(x, y fs.FS, opts ...TreeOption) (string, error) {
+	var c treeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	mx, err := c.readTree(x)
+	if err != nil {
+		return "", err
+	}
+	my, err := c.readTree(y)
+	if err != nil {
+		return "", err
+	}
+	return cmp.Diff(mx, my), nil
+}
+
+// readTree returns the lines of every regular file in fsys keyed by path.
+func (c treeConfig) readTree(fsys fs.FS) (map[string][]string, error) {
+	m := make(map[string][]string)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if c.ignored(p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		s := string(b)
+		if c.normalize {
+			s = strings.Replace(s, "\r\n", "\n", -1)
+		}
+		m[p] = strings.SplitAfter(s, "\n")
+		return nil
+	})
+	return m, err
+}
+
+func (c treeConfig) ignored(p string) bool {
+	if p == "." {
+		return false
+	}
+	for _, pattern := range c.ignore {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// +build go1.16
+
+package cmpfs
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDiffFS(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	tree := fstest.MapFS{
+		"a.txt":       file("alpha\nbravo\n"),
+		"dir/b.txt":   file("charlie\n"),
+		"dir/c.txt":   file("delta\n"),
+		"empty/.keep": file(""),
+	}
+
+	tests := []struct {
+		label    string
+		x, y     fstest.MapFS
+		opts     []TreeOption
+		wantDiff []string // Substrings of the report; empty if equal
+	}{{
+		label: "Equal",
+		x:     tree,
+		y:     tree,
+	}, {
+		label: "Content",
+		x:     tree,
+		y: fstest.MapFS{
+			"a.txt":       file("alpha\nBRAVO\n"),
+			"dir/b.txt":   file("charlie\n"),
+			"dir/c.txt":   file("delta\n"),
+			"empty/.keep": file(""),
+		},
+		wantDiff: []string{`["a.txt"][1]`, `"bravo\n"`, `"BRAVO\n"`},
+	}, {
+		label: "MissingAndExtra",
+		x:     tree,
+		y: fstest.MapFS{
+			"a.txt":       file("alpha\nbravo\n"),
+			"dir/b.txt":   file("charlie\n"),
+			"dir/d.txt":   file("delta\n"),
+			"empty/.keep": file(""),
+		},
+		wantDiff: []string{`["dir/c.txt"]`, `["dir/d.txt"]`},
+	}, {
+		label: "IgnorePaths",
+		x:     tree,
+		y: fstest.MapFS{
+			"a.txt":     file("alpha\nbravo\n"),
+			"dir/b.txt": file("CHARLIE\n"),
+		},
+		opts: []TreeOption{IgnorePaths("dir", "empty/*")},
+	}, {
+		label: "LineEndings",
+		x:     tree,
+		y: fstest.MapFS{
+			"a.txt":       file("alpha\r\nbravo\r\n"),
+			"dir/b.txt":   file("charlie\r\n"),
+			"dir/c.txt":   file("delta\n"),
+			"empty/.keep": file(""),
+		},
+		wantDiff: []string{`["a.txt"][0]`, `["dir/b.txt"][0]`},
+	}, {
+		label: "NormalizeLineEndings",
+		x:     tree,
+		y: fstest.MapFS{
+			"a.txt":       file("alpha\r\nbravo\r\n"),
+			"dir/b.txt":   file("charlie\r\n"),
+			"dir/c.txt":   file("delta\n"),
+			"empty/.keep": file(""),
+		},
+		opts: []TreeOption{NormalizeLineEndings()},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			gotDiff, err := DiffFS(tt.x, tt.y, tt.opts...)
+			if err != nil {
+				t.Fatalf("DiffFS error: %v", err)
+			}
+			if len(tt.wantDiff) == 0 && gotDiff != "" {
+				t.Errorf("DiffFS reported differences:\n%s", gotDiff)
+			}
+			for _, want := range tt.wantDiff {
+				if !strings.Contains(gotDiff, want) {
+					t.Errorf("DiffFS report missing %q:\n%s", want, gotDiff)
+				}
+			}
+		})
+	}
+}
+
+func TestIgnorePathsPanic(t *testing.T) {
+	defer func() {
+		if ex := recover(); ex == nil || !strings.Contains(ex.(string), "invalid path pattern") {
+			t.Errorf("IgnorePaths panic = %v, want invalid path pattern", ex)
+		}
+	}()
+	IgnorePaths("[")
+}
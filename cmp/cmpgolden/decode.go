@@ -0,0 +1,329 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpgolden
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// decode parses the rendering s of a value of type t, as produced by Render,
+// and returns the value.
+func decode(s string, t reflect.Type) (reflect.Value, error) {
+	if t == nil {
+		t = reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+	expr, err := parser.ParseExpr(normalize(s))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(t).Elem()
+	if err := decodeExpr(expr, v); err != nil {
+		return reflect.Value{}, err
+	}
+	return v, nil
+}
+
+// normalize rewrites the parts of a rendering that are not valid Go syntax,
+// outside of quoted strings: "<nil>" becomes "nil", and the "&" before
+// a composite literal whose type is elided is removed, since the type of
+// the composite literal already determines that it is a pointer.
+func normalize(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '`' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				j = len(s) - 1
+			}
+			b.WriteString(s[i : j+1])
+			i = j
+		case c == '<' && len(s[i:]) >= 5 && s[i:i+5] == "<nil>":
+			b.WriteString("nil")
+			i += 4
+		case c == '&' && i+1 < len(s) && s[i+1] == '{':
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// decodeExpr decodes the expression e into the settable value v.
+func decodeExpr(e ast.Expr, v reflect.Value) error {
+	e = unwrap(e)
+	t := v.Type()
+	if id, ok := e.(*ast.Ident); ok && id.Name == "nil" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		return fmt.Errorf("%v: cannot decode nil into %v", pos(e), t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		id, ok := e.(*ast.Ident)
+		if !ok || (id.Name != "true" && id.Name != "false") {
+			return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+		}
+		v.SetBool(id.Name == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c, err := constantOf(e)
+		if err != nil {
+			return err
+		}
+		n, ok := constant.Int64Val(constant.ToInt(c))
+		if !ok || v.OverflowInt(n) {
+			return fmt.Errorf("%v: cannot decode %v into %v", pos(e), c, t)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c, err := constantOf(e)
+		if err != nil {
+			return err
+		}
+		n, ok := constant.Uint64Val(constant.ToInt(c))
+		if !ok || v.OverflowUint(n) {
+			return fmt.Errorf("%v: cannot decode %v into %v", pos(e), c, t)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := floatOf(e)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := constantOf(e)
+		if err != nil {
+			return err
+		}
+		c = constant.ToComplex(c)
+		if c.Kind() != constant.Complex {
+			return fmt.Errorf("%v: cannot decode %v into %v", pos(e), c, t)
+		}
+		re, _ := constant.Float64Val(constant.Real(c))
+		im, _ := constant.Float64Val(constant.Imag(c))
+		v.SetComplex(complex(re, im))
+	case reflect.String:
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+		}
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return fmt.Errorf("%v: %v", pos(e), err)
+		}
+		v.SetString(s)
+	case reflect.Ptr:
+		// Pointers that are not followed, such as those that form a cycle,
+		// are printed as addresses, which cannot be decoded.
+		switch x := e.(type) {
+		case *ast.UnaryExpr:
+			if x.Op != token.AND {
+				return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+			}
+			e = x.X
+		case *ast.CompositeLit:
+		default:
+			return fmt.Errorf("%v: cannot decode address of %v", pos(e), t)
+		}
+		p := reflect.New(t.Elem())
+		if err := decodeExpr(e, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Interface:
+		// The dynamic type of an interface value is only known for constants,
+		// which are decoded as values of their default types.
+		var dv reflect.Value
+		switch e := e.(type) {
+		case *ast.Ident:
+			if e.Name == "true" || e.Name == "false" {
+				dv = reflect.ValueOf(e.Name == "true")
+			}
+		case *ast.BasicLit, *ast.UnaryExpr, *ast.BinaryExpr:
+			if c, err := constantOf(e); err == nil {
+				switch c.Kind() {
+				case constant.Int:
+					n, _ := constant.Int64Val(c)
+					dv = reflect.ValueOf(int(n))
+				case constant.Float:
+					f, _ := constant.Float64Val(c)
+					dv = reflect.ValueOf(f)
+				case constant.Complex:
+					re, _ := constant.Float64Val(constant.Real(c))
+					im, _ := constant.Float64Val(constant.Imag(c))
+					dv = reflect.ValueOf(complex(re, im))
+				case constant.String:
+					dv = reflect.ValueOf(constant.StringVal(c))
+				}
+			}
+		}
+		if !dv.IsValid() || !dv.Type().AssignableTo(t) {
+			return fmt.Errorf("%v: cannot decode the dynamic type of %v", pos(e), t)
+		}
+		v.Set(dv)
+	case reflect.Struct:
+		lit, ok := e.(*ast.CompositeLit)
+		if !ok {
+			return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return fmt.Errorf("%v: missing field name in %v", pos(elt), t)
+			}
+			id, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("%v: invalid field name in %v", pos(kv.Key), t)
+			}
+			sf, ok := t.FieldByName(id.Name)
+			if !ok || len(sf.Index) != 1 {
+				return fmt.Errorf("%v: unknown field %v.%s", pos(id), t, id.Name)
+			}
+			if sf.PkgPath != "" {
+				return fmt.Errorf("%v: cannot decode unexported field %v.%s", pos(id), t, id.Name)
+			}
+			if err := decodeExpr(kv.Value, v.Field(sf.Index[0])); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		lit, ok := e.(*ast.CompositeLit)
+		if !ok {
+			return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+		}
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(lit.Elts), len(lit.Elts)))
+		} else if len(lit.Elts) != t.Len() {
+			return fmt.Errorf("%v: got %d elements for %v", pos(e), len(lit.Elts), t)
+		}
+		for i, elt := range lit.Elts {
+			if err := decodeExpr(elt, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		lit, ok := e.(*ast.CompositeLit)
+		if !ok {
+			return fmt.Errorf("%v: cannot decode %v", pos(e), t)
+		}
+		v.Set(reflect.MakeMap(t))
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return fmt.Errorf("%v: missing map key in %v", pos(elt), t)
+			}
+			mk := reflect.New(t.Key()).Elem()
+			if err := decodeExpr(kv.Key, mk); err != nil {
+				return err
+			}
+			mv := reflect.New(t.Elem()).Elem()
+			if err := decodeExpr(kv.Value, mv); err != nil {
+				return err
+			}
+			v.SetMapIndex(mk, mv)
+		}
+	default:
+		// Functions, channels, and unsafe pointers are printed as addresses,
+		// of which only the zero address can be decoded.
+		if c, err := constantOf(e); err == nil && c.Kind() == constant.Int && constant.Sign(c) == 0 {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		return fmt.Errorf("%v: cannot decode non-nil %v", pos(e), t)
+	}
+	return nil
+}
+
+// unwrap removes the parentheses and the conversions around e,
+// since the type of the decoded value is already known.
+func unwrap(e ast.Expr) ast.Expr {
+	for {
+		switch x := e.(type) {
+		case *ast.ParenExpr:
+			e = x.X
+		case *ast.CallExpr:
+			if len(x.Args) != 1 {
+				return e
+			}
+			e = x.Args[0]
+		default:
+			return e
+		}
+	}
+}
+
+// constantOf evaluates e as a constant expression.
+func constantOf(e ast.Expr) (constant.Value, error) {
+	switch x := unwrap(e).(type) {
+	case *ast.BasicLit:
+		if c := constant.MakeFromLiteral(x.Value, x.Kind, 0); c.Kind() != constant.Unknown {
+			return c, nil
+		}
+	case *ast.UnaryExpr:
+		if c, err := constantOf(x.X); err == nil && (x.Op == token.ADD || x.Op == token.SUB) {
+			return constant.UnaryOp(x.Op, c, 0), nil
+		}
+	case *ast.BinaryExpr:
+		cx, errx := constantOf(x.X)
+		cy, erry := constantOf(x.Y)
+		if errx == nil && erry == nil && (x.Op == token.ADD || x.Op == token.SUB) {
+			return constant.BinaryOp(cx, x.Op, cy), nil
+		}
+	}
+	return nil, fmt.Errorf("%v: invalid constant", pos(e))
+}
+
+// floatOf evaluates e as a floating-point value, which may also be
+// NaN or an infinity as printed by Render.
+func floatOf(e ast.Expr) (float64, error) {
+	e = unwrap(e)
+	sign := 1.0
+	if u, ok := e.(*ast.UnaryExpr); ok && (u.Op == token.ADD || u.Op == token.SUB) {
+		if id, ok := unwrap(u.X).(*ast.Ident); ok && id.Name == "Inf" {
+			if u.Op == token.SUB {
+				sign = -1
+			}
+			e = id
+		}
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		switch id.Name {
+		case "NaN":
+			return math.NaN(), nil
+		case "Inf":
+			return math.Inf(int(sign)), nil
+		}
+	}
+	c, err := constantOf(e)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := constant.Float64Val(constant.ToFloat(c))
+	return f, nil
+}
+
+// pos returns the position of e within the rendering, for error messages.
+func pos(e ast.Node) string {
+	return fmt.Sprintf("offset %d", e.Pos()-1)
+}
@@ -0,0 +1,172 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmpgolden provides a helper for comparing values against golden
+// files, which record the expected values of a test and are rewritten when
+// the test is asked to update them.
+//
+// This package does not register a flag itself, so that it does not conflict
+// with flags of the test. A test typically registers its own:
+//
+//	var update = flag.Bool("update", false, "update golden files")
+//
+//	func TestServer(t *testing.T) {
+//		got := ...
+//		d, err := cmpgolden.Diff("testdata/server.golden", got, *update)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		if d != "" {
+//			t.Errorf("mismatch (-want +got):\n%s", d)
+//		}
+//	}
+package cmpgolden
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// Diff compares the value got against the value recorded in the golden file
+// at path, and returns a human-readable report of the differences, or an empty
+// string if they are equal. The golden file holds the rendering of a value as
+// a Go literal, as produced by Render, which Diff decodes into a value of
+// the same type as got. The values are then compared by cmp.Diff with
+// the given options, such that options on the values (e.g., ignoring fields)
+// apply as they would for comparing the values directly.
+//
+// If update is true, the golden file (and any missing parent directories) is
+// instead replaced by the rendering of got, and Diff returns an empty string.
+//
+// An error is returned if the golden file cannot be read, written, or decoded.
+// Only values of the kinds that Render prints as literals can be decoded,
+// which excludes unexported fields, non-nil functions and channels,
+// and pointers that form a cycle. The dynamic values of interfaces can only
+// be constants, which are decoded as the default types of Go constants
+// (e.g., int or float64).
+func Diff(path string, got interface{}, update bool, opts ...cmp.Option) (string, error) {
+	t := reflect.TypeOf(got)
+	if update {
+		s := Render(got)
+		if _, err := decode(s, t); err != nil {
+			return "", fmt.Errorf("cannot decode the rendering of %v: %v", t, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
+			return "", err
+		}
+		return "", ioutil.WriteFile(path, []byte(s), 0664)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%v (update the golden file to create it)", err)
+		}
+		return "", err
+	}
+	want, err := decode(string(b), t)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode golden file %s: %v", path, err)
+	}
+	return cmp.Diff(want.Interface(), got, opts...), nil
+}
+
+// Render returns the rendering of v that Diff writes to golden files.
+func Render(v interface{}) string {
+	if v == nil {
+		return "nil\n"
+	}
+	return indent(value.Format(reflect.ValueOf(v), value.FormatConfig{})) + "\n"
+}
+
+// indent formats the single-line output of value.Format across multiple lines,
+// such that every element of a composite literal is printed on its own line.
+// Braces within quoted strings and type literals (e.g., "struct { A int }"),
+// and commas within parentheses and brackets, are left as is.
+func indent(s string) string {
+	var b bytes.Buffer
+	var depth, nest int // Depth of braces, and of parentheses and brackets
+	newline := func() {
+		b.WriteByte('\n')
+		for i := 0; i < depth; i++ {
+			b.WriteByte('\t')
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '`', '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				j = len(s) - 1
+			}
+			b.WriteString(s[i : j+1])
+			i = j
+		case '(', '[':
+			nest++
+			b.WriteByte(c)
+		case ')', ']':
+			nest--
+			b.WriteByte(c)
+		case '{':
+			if strings.HasSuffix(s[:i], "struct ") || strings.HasSuffix(s[:i], "interface ") {
+				j, n := i, 0
+				for ; j < len(s)-1; j++ {
+					if s[j] == '{' {
+						n++
+					} else if s[j] == '}' {
+						if n--; n == 0 {
+							break
+						}
+					}
+				}
+				b.WriteString(s[i : j+1])
+				i = j
+				continue
+			}
+			b.WriteByte(c)
+			if i+1 < len(s) && s[i+1] == '}' {
+				b.WriteByte('}')
+				i++
+				continue
+			}
+			depth++
+			newline()
+		case '}':
+			b.WriteByte(',')
+			depth--
+			newline()
+			b.WriteByte(c)
+		case ',':
+			b.WriteByte(c)
+			if nest > 0 || depth == 0 {
+				continue
+			}
+			newline()
+			if i+1 < len(s) && s[i+1] == ' ' {
+				i++
+			}
+		case ':':
+			b.WriteByte(c)
+			if depth > 0 && nest == 0 && i+1 < len(s) && s[i+1] != ' ' {
+				b.WriteByte(' ')
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,199 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpgolden
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type Server struct {
+	Name  string
+	Ports []int
+	Tags  map[string]string
+	Next  *Server
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		label string
+		in    interface{}
+		want  string
+	}{{
+		label: "Nil",
+		in:    nil,
+		want:  "nil\n",
+	}, {
+		label: "Int",
+		in:    5,
+		want:  "5\n",
+	}, {
+		label: "EmptySlice",
+		in:    []int{},
+		want:  "[]int{}\n",
+	}, {
+		label: "Struct",
+		in:    Server{Name: "a{b}, c", Ports: []int{80, 443}, Tags: map[string]string{"env": "prod"}, Next: &Server{Name: "b"}},
+		want: `cmpgolden.Server{
+	Name: "a{b}, c",
+	Ports: []int{
+		80,
+		443,
+	},
+	Tags: map[string]string{
+		"env": "prod",
+	},
+	Next: &cmpgolden.Server{
+		Name: "b",
+	},
+}
+`,
+	}, {
+		label: "TypeLiteral",
+		in:    []struct{ A, B int }{{1, 2}},
+		want: `[]struct { A int; B int }{
+	{
+		A: 1,
+		B: 2,
+	},
+}
+`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := Render(tt.in); got != tt.want {
+				t.Errorf("Render mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type Named uint16
+	type Wrapper struct {
+		Ptr   *int
+		Str   *string
+		Iface []interface{}
+		Named Named
+		Float []float64
+		Cplx  complex64
+		Bytes []byte
+		Nil   []int
+		Func  func()
+		Elems []*Server
+		Array [2]bool
+	}
+	five, hello := 5, "hello"
+
+	tests := []struct {
+		label   string
+		in      interface{}
+		wantErr string
+	}{{
+		label: "Nil",
+		in:    nil,
+	}, {
+		label: "Server",
+		in:    Server{Name: "a{b}, c", Ports: []int{80, 443}, Tags: map[string]string{"env": "prod"}, Next: &Server{Name: "b"}},
+	}, {
+		label: "Kinds",
+		in: &Wrapper{
+			Ptr:   &five,
+			Str:   &hello,
+			Iface: []interface{}{1, -2.5, "s", true, nil},
+			Named: 7,
+			Float: []float64{math.Inf(-1), math.Inf(+1), -0.5, 1e100},
+			Cplx:  complex(1, -2),
+			Bytes: []byte("\x00\xff"),
+			Elems: []*Server{{Name: "x"}, nil},
+			Array: [2]bool{false, true},
+		},
+	}, {
+		label:   "Cycle",
+		in:      func() *Server { s := &Server{}; s.Next = s; return s }(),
+		wantErr: "cannot decode address",
+	}, {
+		label:   "Unexported",
+		in:      struct{ a int }{1},
+		wantErr: "cannot decode unexported field",
+	}, {
+		label:   "InterfaceStruct",
+		in:      []interface{}{Server{}},
+		wantErr: "cannot decode the dynamic type",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got, err := decode(Render(tt.in), reflect.TypeOf(tt.in))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decode error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if d := cmp.Diff(tt.in, got.Interface()); d != "" {
+				t.Errorf("decode mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmpgolden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "server.golden")
+
+	// The golden file does not exist until it is updated.
+	want := Server{Name: "a", Ports: []int{80}}
+	if _, err := Diff(path, want, false); err == nil || !strings.Contains(err.Error(), "update") {
+		t.Errorf("Diff error = %v, want an error suggesting to update", err)
+	}
+	if got, err := Diff(path, want, true); got != "" || err != nil {
+		t.Fatalf("Diff with update = (%q, %v), want (\"\", nil)", got, err)
+	}
+	if b, err := ioutil.ReadFile(path); err != nil || string(b) != Render(want) {
+		t.Errorf("golden file = (%q, %v), want (%q, nil)", b, err, Render(want))
+	}
+
+	if got, err := Diff(path, want, false); got != "" || err != nil {
+		t.Errorf("Diff of equal value = (%q, %v), want (\"\", nil)", got, err)
+	}
+	got, err := Diff(path, Server{Name: "a", Ports: []int{8080}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantDiff := cmp.Diff(want, Server{Name: "a", Ports: []int{8080}}); got != wantDiff {
+		t.Errorf("Diff of unequal value:\ngot:\n%s\nwant:\n%s", got, wantDiff)
+	}
+
+	// Options apply to the values decoded from the golden file.
+	ignorePorts := cmpopts.IgnoreFields(Server{}, "Ports")
+	if got, err := Diff(path, Server{Name: "a", Ports: []int{8080}}, false, ignorePorts); got != "" || err != nil {
+		t.Errorf("Diff with IgnoreFields = (%q, %v), want (\"\", nil)", got, err)
+	}
+
+	// Values that cannot be decoded are not written.
+	if _, err := Diff(path, struct{ a int }{1}, true); err == nil {
+		t.Errorf("Diff with update of undecodable value succeeded, want error")
+	}
+	if b, err := ioutil.ReadFile(path); err != nil || string(b) != Render(want) {
+		t.Errorf("golden file = (%q, %v), want (%q, nil)", b, err, Render(want))
+	}
+}
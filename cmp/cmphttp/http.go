@@ -0,0 +1,224 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// Package cmphttp provides options for comparing HTTP requests, responses,
+// and headers, as commonly needed by tests of HTTP handlers and clients.
+package cmphttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// VolatileHeaders is a list of headers whose values typically differ between
+// otherwise identical responses, for use with IgnoreHeaders.
+var VolatileHeaders = []string{"Date", "Expires", "Last-Modified", "Age", "Etag", "X-Request-Id"}
+
+// Option configures how the option returned by Equate compares values.
+type Option func(*config)
+
+type config struct {
+	ignore     map[string]bool // Set of canonical header names to ignore
+	decodeJSON bool            // Whether to decode bodies as JSON
+}
+
+// IgnoreHeaders returns an Option that ignores the named headers,
+// where the names are case-insensitive.
+func IgnoreHeaders(names ...string) Option {
+	return func(c *config) {
+		for _, name := range names {
+			c.ignore[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// DecodeJSONBody returns an Option that decodes the body of requests and
+// responses as JSON, such that bodies are equal if they encode the same
+// JSON value regardless of formatting or the order of object members.
+// Numbers are decoded as json.Number, preserving their exact text,
+// as done by cmpopts.TransformJSON.
+// Bodies that are not valid JSON are compared as strings.
+func DecodeJSONBody() Option {
+	return func(c *config) { c.decodeJSON = true }
+}
+
+// BufferBody reads the entire body and replaces it with an equivalent
+// in-memory reader, which the option returned by Equate can read without
+// consuming it. It must be called before comparing the body of a response,
+// or of a request whose GetBody field is nil.
+// Calling BufferBody on a buffered or nil body has no effect.
+func BufferBody(body *io.ReadCloser) error {
+	if *body == nil || *body == http.NoBody {
+		return nil
+	}
+	if _, ok := (*body).(*bufferedBody); ok {
+		return nil
+	}
+	b, err := ioutil.ReadAll(*body)
+	if cerr := (*body).Close(); err == nil {
+		err = cerr
+	}
+	*body = &bufferedBody{bytes.NewReader(b), b}
+	return err
+}
+
+// bufferedBody is a body that has been read in full by BufferBody.
+type bufferedBody struct {
+	*bytes.Reader
+	b []byte
+}
+
+func (*bufferedBody) Close() error { return nil }
+
+// BodyError is the body of a Request or Response whose body could not be read.
+// A BodyError is never equal to another, so that bodies that cannot be read
+// are always reported as differences.
+type BodyError struct {
+	Err string
+}
+
+// Equal reports false, which cmp.Equal uses to compare BodyError values.
+func (BodyError) Equal(BodyError) bool { return false }
+
+// Request is the form that a *http.Request is compared in.
+type Request struct {
+	Method  string
+	URL     string
+	Host    string
+	Header  http.Header
+	Trailer http.Header
+	Body    interface{} // The body as a string, or the decoded JSON value
+}
+
+// Response is the form that a *http.Response is compared in.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Trailer    http.Header
+	Body       interface{} // The body as a string, or the decoded JSON value
+}
+
+// Equate returns a cmp.Option that compares *http.Request, *http.Response,
+// and http.Header values semantically:
+//	• Header names are compared case-insensitively and the multiple values of a
+//	single header are compared irrespective of order
+//	• Requests are compared as a Request and responses as a Response,
+//	which omit fields that are only meaningful to a live connection
+//	• Bodies are compared by content, which is read from bodies buffered by
+//	BufferBody or from the GetBody function of a request
+//
+// Bodies are never consumed, so that they can still be read after
+// the comparison. A body that cannot be read without consuming it,
+// or whose read fails, is compared as a BodyError describing the problem.
+// A nil request or response is transformed into a nil pointer.
+func Equate(opts ...Option) cmp.Option {
+	c := config{ignore: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return cmp.Options{
+		cmp.Transformer("cmphttp.Header", c.header),
+		cmp.Transformer("cmphttp.Request", c.request),
+		cmp.Transformer("cmphttp.Response", c.response),
+	}
+}
+
+// header returns a copy of h with canonical names and sorted values.
+// The result is not a http.Header to avoid transforming it again.
+func (c config) header(h http.Header) map[string][]string {
+	m := make(map[string][]string)
+	for k, vs := range h {
+		k = http.CanonicalHeaderKey(k)
+		if c.ignore[k] {
+			continue
+		}
+		m[k] = append(m[k], vs...)
+	}
+	for _, vs := range m {
+		sort.Strings(vs)
+	}
+	return m
+}
+
+func (c config) request(r *http.Request) *Request {
+	if r == nil {
+		return nil
+	}
+	var url string
+	if r.URL != nil {
+		url = r.URL.String()
+	}
+	return &Request{
+		Method:  r.Method,
+		URL:     url,
+		Host:    r.Host,
+		Header:  r.Header,
+		Trailer: r.Trailer,
+		Body:    c.requestBody(r),
+	}
+}
+
+func (c config) response(r *http.Response) *Response {
+	if r == nil {
+		return nil
+	}
+	return &Response{
+		StatusCode: r.StatusCode,
+		Header:     r.Header,
+		Trailer:    r.Trailer,
+		Body:       c.body(r.Body),
+	}
+}
+
+func (c config) requestBody(r *http.Request) interface{} {
+	if _, ok := r.Body.(*bufferedBody); ok || r.GetBody == nil {
+		return c.body(r.Body)
+	}
+	rc, err := r.GetBody()
+	if err != nil {
+		return BodyError{fmt.Sprintf("cannot get body: %v", err)}
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return BodyError{fmt.Sprintf("cannot read body: %v", err)}
+	}
+	return c.content(b)
+}
+
+// body returns the content of a body without consuming it.
+func (c config) body(rc io.ReadCloser) interface{} {
+	switch rc := rc.(type) {
+	case nil:
+		return ""
+	case *bufferedBody:
+		return c.content(rc.b)
+	}
+	if rc == http.NoBody {
+		return ""
+	}
+	return BodyError{"body is not buffered; call cmphttp.BufferBody before comparing it"}
+}
+
+// content returns the body content b as a string, or the decoded JSON value.
+func (c config) content(b []byte) interface{} {
+	if c.decodeJSON {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var v interface{}
+		if dec.Decode(&v) == nil {
+			if _, err := dec.Token(); err == io.EOF {
+				return v
+			}
+		}
+	}
+	return string(b)
+}
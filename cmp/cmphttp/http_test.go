@@ -0,0 +1,174 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmphttp
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEquate(t *testing.T) {
+	response := func(code int, body string, header ...string) *http.Response {
+		r := &http.Response{
+			StatusCode: code,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+		if err := BufferBody(&r.Body); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(header); i += 2 {
+			r.Header[header[i]] = append(r.Header[header[i]], header[i+1])
+		}
+		return r
+	}
+	request := func(method, url, body string) *http.Request {
+		r, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+	unbuffered := func(body string) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+	}
+	failing := func() *http.Request {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.GetBody = func() (io.ReadCloser, error) { return nil, errors.New("failure") }
+		return r
+	}
+
+	tests := []struct {
+		label     string
+		x, y      interface{}
+		opts      []Option
+		wantEqual bool
+	}{{
+		label:     "HeaderCase",
+		x:         http.Header{"Content-Type": {"text/plain"}},
+		y:         http.Header{"content-type": {"text/plain"}},
+		wantEqual: true,
+	}, {
+		label:     "HeaderOrder",
+		x:         http.Header{"Accept": {"a", "b"}},
+		y:         http.Header{"Accept": {"b", "a"}},
+		wantEqual: true,
+	}, {
+		label:     "HeaderValues",
+		x:         http.Header{"Accept": {"a", "b"}},
+		y:         http.Header{"Accept": {"a", "c"}},
+		wantEqual: false,
+	}, {
+		label:     "Response",
+		x:         response(200, "hello", "Date", "Mon, 01 Jan 2018 00:00:00 GMT"),
+		y:         response(200, "hello", "Date", "Tue, 02 Jan 2018 00:00:00 GMT"),
+		wantEqual: false,
+	}, {
+		label:     "IgnoreHeaders",
+		x:         response(200, "hello", "Date", "Mon, 01 Jan 2018 00:00:00 GMT"),
+		y:         response(200, "hello", "date", "Tue, 02 Jan 2018 00:00:00 GMT"),
+		opts:      []Option{IgnoreHeaders(VolatileHeaders...)},
+		wantEqual: true,
+	}, {
+		label:     "StatusCode",
+		x:         response(200, "hello"),
+		y:         response(404, "hello"),
+		wantEqual: false,
+	}, {
+		label:     "Body",
+		x:         response(200, `{"a": 1, "b": [2, 3]}`),
+		y:         response(200, `{"b":[2,3],"a":1}`),
+		wantEqual: false,
+	}, {
+		label:     "DecodeJSONBody",
+		x:         response(200, `{"a": 1, "b": [2, 3]}`),
+		y:         response(200, `{"b":[2,3],"a":1}`),
+		opts:      []Option{DecodeJSONBody()},
+		wantEqual: true,
+	}, {
+		label:     "DecodeJSONBody",
+		x:         response(200, `{"a": 1}`),
+		y:         response(200, `{"a": 2}`),
+		opts:      []Option{DecodeJSONBody()},
+		wantEqual: false,
+	}, {
+		label:     "DecodeJSONBodyLargeNumbers",
+		x:         response(200, `{"a": 9007199254740993}`),
+		y:         response(200, `{"a": 9007199254740992}`),
+		opts:      []Option{DecodeJSONBody()},
+		wantEqual: false,
+	}, {
+		label:     "UnbufferedBody",
+		x:         unbuffered("hello"),
+		y:         unbuffered("hello"),
+		wantEqual: false,
+	}, {
+		label:     "GetBodyError",
+		x:         failing(),
+		y:         failing(),
+		wantEqual: false,
+	}, {
+		label:     "Request",
+		x:         request("POST", "http://example.com/foo", `{"a": 1}`),
+		y:         request("POST", "http://example.com/foo", `{ "a" : 1 }`),
+		opts:      []Option{DecodeJSONBody()},
+		wantEqual: true,
+	}, {
+		label:     "RequestURL",
+		x:         request("GET", "http://example.com/foo", ""),
+		y:         request("GET", "http://example.com/bar", ""),
+		wantEqual: false,
+	}, {
+		label:     "Nil",
+		x:         []*http.Request{nil},
+		y:         []*http.Request{request("GET", "/", "")},
+		wantEqual: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := cmp.Equal(tt.x, tt.y, Equate(tt.opts...)); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v\n%s", got, tt.wantEqual, cmp.Diff(tt.x, tt.y, Equate(tt.opts...)))
+			}
+		})
+	}
+}
+
+func TestEquateBodyRestored(t *testing.T) {
+	x := &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("hello"))}
+	y := &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("hello"))}
+	if err := BufferBody(&x.Body); err != nil {
+		t.Fatal(err)
+	}
+	if err := BufferBody(&y.Body); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(x, y, Equate()) {
+		t.Fatalf("Equal = false, want true")
+	}
+	b, err := ioutil.ReadAll(x.Body)
+	if err != nil || string(b) != "hello" {
+		t.Errorf("ReadAll = (%q, %v), want (%q, nil)", b, err, "hello")
+	}
+
+	// Requests are read through GetBody, leaving their body unconsumed.
+	rx, _ := http.NewRequest("POST", "/", strings.NewReader("hello"))
+	ry, _ := http.NewRequest("POST", "/", strings.NewReader("hello"))
+	if !cmp.Equal(rx, ry, Equate()) {
+		t.Fatalf("Equal = false, want true")
+	}
+	b, err = ioutil.ReadAll(rx.Body)
+	if err != nil || string(b) != "hello" {
+		t.Errorf("ReadAll = (%q, %v), want (%q, nil)", b, err, "hello")
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpopts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// CrossTypeOption configures how EquateAcrossTypes matches struct fields.
+type CrossTypeOption func(*crossTypeConfig)
+
+type crossTypeConfig struct {
+	tag  string // Key of the struct tag to name fields by; empty for Go names
+	fold bool   // Whether to match field names case-insensitively
+	skip bool   // Whether to ignore fields present in only one struct
+}
+
+// MatchFieldTag returns a CrossTypeOption that matches struct fields by their
+// name in the struct tag with the given key (e.g., "json" or "yaml"),
+// similar to how encoding/json names fields. Fields without a name in the tag
+// are matched by their Go name, while fields tagged with "-" are not compared.
+func MatchFieldTag(key string) CrossTypeOption {
+	if key == "" {
+		panic("invalid empty struct tag key")
+	}
+	return func(c *crossTypeConfig) { c.tag = key }
+}
+
+// MatchFieldsFold returns a CrossTypeOption that matches struct field names
+// case-insensitively (e.g., "ID", "Id", and "id" all match each other),
+// similar to how encoding/json matches object keys to field names.
+// Since field names are converted to lower case for matching,
+// reported paths name the fields in lower case.
+func MatchFieldsFold() CrossTypeOption {
+	return func(c *crossTypeConfig) { c.fold = true }
+}
+
+// IgnoreMissingFields returns a CrossTypeOption that ignores fields present
+// in only one of the structs, which EquateAcrossTypes otherwise reports as
+// added or removed. It is a separate opt-in for tests where only the fields
+// common to both structs are expected to match, such that values are equal if
+// those fields are. The ignored fields remain visible to programmatic reports:
+// cmp.DiffResult lists them as ignored differences whose Edit is cmp.Inserted
+// or cmp.Removed, and the cmp.AuditIgnored option is called for each of them.
+func IgnoreMissingFields() CrossTypeOption {
+	return func(c *crossTypeConfig) { c.skip = true }
+}
+
+// EquateAcrossTypes returns a Transformer option that compares two values of
+// different struct types by matching up their fields. Each struct is
+// transformed into a map[string]interface{} from field names to field values,
+// such that two structs are equal if they have the same set of field names
+// and all of their matched field values are equal. By default, fields are
+// matched by their Go name; use MatchFieldTag to match them by a struct tag,
+// and MatchFieldsFold to match them case-insensitively.
+//
+// This is useful for comparing types that correspond to each other,
+// such as a data transfer object and the domain type it is converted from,
+// or the values of an old and a new generation of an evolving type in
+// a schema-migration test. Fields present in only one of the structs do not
+// cause a type mismatch, but are reported as added or removed:
+// Diff reports them as entries added to or removed from the transformed map,
+// and DiffResult reports them with an Edit of cmp.Inserted or cmp.Removed.
+// The option applies to values of different struct types (or non-nil pointers
+// to structs) held in interface values, such as the inputs to Equal.
+// It applies recursively to fields whose values are of different struct types.
+//
+// Only exported fields are compared. The fields of embedded structs
+// are promoted to the parent struct, unless named by a struct tag,
+// following the rules of Go as encoding/json does: a shallower field shadows
+// deeper fields of the same name, a tagged field shadows untagged fields at
+// the same depth, and otherwise fields of the same name at the same depth are
+// ambiguous and not compared. EquateAcrossTypes panics if two fields at the
+// same depth are tagged with the same name.
+func EquateAcrossTypes(opts ...CrossTypeOption) cmp.Option {
+	var c crossTypeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	opt := cmp.FilterValues(areDifferentStructs, cmp.Transformer(crossTypeName, c.fields))
+	if c.skip {
+		return cmp.Options{opt, cmp.FilterPath(isMissingField, cmp.Ignore())}
+	}
+	return opt
+}
+
+const crossTypeName = "cmpopts.EquateAcrossTypes"
+
+// isMissingField reports whether the last step of p is a field that
+// EquateAcrossTypes found in only one of the structs.
+func isMissingField(p cmp.Path) bool {
+	mi, ok := p.Last().(cmp.MapIndex)
+	if !ok || len(p) < 2 {
+		return false
+	}
+	if tf, ok := p.Index(-2).(cmp.Transform); !ok || tf.Name() != crossTypeName {
+		return false
+	}
+	vx, vy := mi.Values()
+	return !vx.IsValid() || !vy.IsValid()
+}
+
+func areDifferentStructs(x, y interface{}) bool {
+	tx, ty := structType(x), structType(y)
+	return tx != nil && ty != nil && tx != ty
+}
+
+// structType reports the struct type of v if v is a struct or
+// a non-nil pointer to a struct. Otherwise, it returns nil.
+func structType(v interface{}) reflect.Type {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return rv.Type()
+}
+
+func (c crossTypeConfig) fields(v interface{}) map[string]interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	var fs []crossField
+	c.addFields(&fs, rv.Type(), rv, 0, map[reflect.Type]bool{rv.Type(): true})
+
+	// Select the dominant field for each name according to the rules of Go
+	// for promoted fields, as encoding/json does: the shallowest fields win,
+	// where a tagged field wins over untagged fields at the same depth,
+	// and untagged fields at the same depth are ambiguous and dropped.
+	byName := make(map[string][]crossField)
+	var names []string
+	for _, f := range fs {
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+	m := make(map[string]interface{})
+	for _, name := range names {
+		var dominant []crossField
+		var numTagged int
+		for _, f := range byName[name] {
+			switch {
+			case len(dominant) > 0 && f.depth > dominant[0].depth:
+				continue
+			case len(dominant) > 0 && f.depth < dominant[0].depth:
+				dominant, numTagged = nil, 0
+			}
+			dominant = append(dominant, f)
+			if f.tagged {
+				numTagged++
+			}
+		}
+		switch {
+		case numTagged > 1:
+			panic(fmt.Sprintf("duplicate field name %q in %v", name, rv.Type()))
+		case numTagged == 1:
+			for _, f := range dominant {
+				if f.tagged {
+					dominant = []crossField{f}
+				}
+			}
+		}
+		if len(dominant) == 1 && dominant[0].v.IsValid() {
+			m[name] = dominant[0].v.Interface()
+		}
+	}
+	return m
+}
+
+// crossField is a candidate field for EquateAcrossTypes.
+type crossField struct {
+	name   string
+	depth  int           // Depth of embedding; zero for fields of the struct itself
+	tagged bool          // Whether the field is named by a struct tag
+	v      reflect.Value // Invalid if the field is within a nil embedded pointer
+}
+
+// addFields appends the fields of struct type t to fs, along with those of
+// its embedded structs, where v is the value of the struct or invalid.
+// The visited types are those of the embedding structs, which breaks cycles.
+func (c crossTypeConfig) addFields(fs *[]crossField, t reflect.Type, v reflect.Value, depth int, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // Unexported fields cannot be compared
+		}
+		name, tagged := f.Name, false
+		if c.tag != "" {
+			if f.Tag.Get(c.tag) == "-" {
+				continue
+			}
+			if tn := value.FieldTagName(f, c.tag); tn != "" {
+				name, tagged = tn, true
+			}
+		}
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		if f.Anonymous && !tagged {
+			ft, ev := f.Type, fv
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if ev.IsValid() {
+					if ev.IsNil() {
+						ev = reflect.Value{} // The fields of a nil embedded struct do not exist
+					} else {
+						ev = ev.Elem()
+					}
+				}
+			}
+			if ft.Kind() == reflect.Struct {
+				if !visited[ft] {
+					visited[ft] = true
+					c.addFields(fs, ft, ev, depth+1, visited)
+					delete(visited, ft)
+				}
+				continue
+			}
+		}
+		if c.fold {
+			name = strings.ToLower(name)
+		}
+		*fs = append(*fs, crossField{name, depth, tagged, fv})
+	}
+}
@@ -6,10 +6,18 @@
 package cmpopts
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"fmt"
 	"math"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/internal/function"
 )
 
 func equateAlways(_, _ interface{}) bool { return true }
@@ -70,14 +78,49 @@ func (a approximator) compareF32(x, y float32) bool {
 	return a.compareF64(float64(x), float64(y))
 }
 
+// EquateApproxTime returns a Comparer option that determines two non-zero
+// time.Time values to be equal if they are within some margin of one another.
+// If both times have a monotonic clock reading, then the monotonic time
+// difference is used. Times in different locations are compared by the
+// instants they represent. The margin must be non-negative.
+func EquateApproxTime(margin time.Duration) cmp.Option {
+	if margin < 0 {
+		panic("margin must be a non-negative number")
+	}
+	a := timeApproximator{margin}
+	return cmp.FilterValues(areNonZeroTimes, cmp.Comparer(a.compare))
+}
+
+func areNonZeroTimes(x, y time.Time) bool {
+	return !x.IsZero() && !y.IsZero()
+}
+
+type timeApproximator struct {
+	margin time.Duration
+}
+
+func (a timeApproximator) compare(x, y time.Time) bool {
+	// Avoid subtracting times, which overflows when the difference is
+	// larger than the largest representable duration.
+	if x.After(y) {
+		x, y = y, x // Ensure that x is before y
+	}
+	// The times are within the margin if x+margin is not before y.
+	return !x.Add(a.margin).Before(y)
+}
+
 // EquateNaNs returns a Comparer option that determines float32 and float64
-// NaN values to be equal.
+// NaN values to be equal. It also determines complex64 and complex128 values
+// to be equal if their real parts and their imaginary parts are each
+// either equal or both NaN.
 //
 // EquateNaNs can be used in conjunction with EquateApprox.
 func EquateNaNs() cmp.Option {
 	return cmp.Options{
 		cmp.FilterValues(areNaNsF64s, cmp.Comparer(equateAlways)),
 		cmp.FilterValues(areNaNsF32s, cmp.Comparer(equateAlways)),
+		cmp.FilterValues(hasNaNsC128s, cmp.Comparer(equateNaNsC128)),
+		cmp.FilterValues(hasNaNsC64s, cmp.Comparer(equateNaNsC64)),
 	}
 }
 
@@ -87,3 +130,231 @@ func areNaNsF64s(x, y float64) bool {
 func areNaNsF32s(x, y float32) bool {
 	return areNaNsF64s(float64(x), float64(y))
 }
+func hasNaNsC128s(x, y complex128) bool {
+	hasNaN := func(z complex128) bool { return math.IsNaN(real(z)) || math.IsNaN(imag(z)) }
+	return hasNaN(x) && hasNaN(y)
+}
+func hasNaNsC64s(x, y complex64) bool {
+	return hasNaNsC128s(complex128(x), complex128(y))
+}
+func equateNaNsC128(x, y complex128) bool {
+	eq := func(x, y float64) bool { return x == y || areNaNsF64s(x, y) }
+	return eq(real(x), real(y)) && eq(imag(x), imag(y))
+}
+func equateNaNsC64(x, y complex64) bool {
+	return equateNaNsC128(complex128(x), complex128(y))
+}
+
+// ComparerFromCompare returns a Comparer option that determines two values to
+// be equal if the three-way compare function reports that they are equal.
+// The compare function must be of the form "func(T, T) int", where the result
+// is zero if and only if the two inputs are equal. This allows functions such
+// as bytes.Compare, strings.Compare, or (*big.Int).Cmp to be used directly.
+// The option applies to all values of a type that is assignable to T.
+//
+// If T is a pointer, then the compare function is only used when
+// both pointers are non-nil; otherwise, the pointers are only equal if
+// they are both nil.
+//
+// The compare function must be:
+//	• Antisymmetric: compare(x, y) == 0 if and only if compare(y, x) == 0
+//	• Deterministic: compare(x, y) == compare(x, y)
+//	• Pure: compare(x, y) does not modify x or y
+func ComparerFromCompare(compare interface{}) cmp.Option {
+	vf := reflect.ValueOf(compare)
+	if !function.IsType(vf.Type(), function.Compare) || vf.IsNil() {
+		panic(fmt.Sprintf("invalid compare function: %T", compare))
+	}
+	cc := compareComparer{vf.Type().In(0), vf}
+	return cmp.FilterValues(cc.filter, cmp.Comparer(cc.equal))
+}
+
+type compareComparer struct {
+	in  reflect.Type  // T
+	fnc reflect.Value // func(T, T) int
+}
+
+func (cc compareComparer) filter(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	return (x != nil && y != nil) &&
+		(vx.Type().AssignableTo(cc.in) && vy.Type().AssignableTo(cc.in)) &&
+		!(vx.Kind() == reflect.Ptr && vx.IsNil()) &&
+		!(vy.Kind() == reflect.Ptr && vy.IsNil())
+}
+func (cc compareComparer) equal(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	return cc.fnc.Call([]reflect.Value{vx, vy})[0].Int() == 0
+}
+
+// EquateUUIDs returns a Comparer option that determines two UUIDs to be equal
+// if they represent the same 128-bit value, regardless of representation.
+// A UUID may be represented as any of the following:
+//	• a string in the canonical 8-4-4-4-12 hexadecimal form, in either case,
+//	optionally with a "urn:uuid:" prefix or surrounded by braces
+//	• an array of 16 bytes, which includes the UUID types of popular packages
+//	such as github.com/google/uuid and github.com/gofrs/uuid
+//	• a non-nil pointer to either of the above
+//
+// This option only applies when both values are UUIDs; thus, strings that
+// are not formatted as a UUID are compared as usual.
+func EquateUUIDs() cmp.Option {
+	return cmp.FilterValues(areUUIDs, cmp.Comparer(equateUUIDs))
+}
+
+func areUUIDs(x, y interface{}) bool {
+	_, okx := parseUUID(x)
+	_, oky := parseUUID(y)
+	return okx && oky
+}
+func equateUUIDs(x, y interface{}) bool {
+	ux, _ := parseUUID(x)
+	uy, _ := parseUUID(y)
+	return ux == uy
+}
+
+// parseUUID reports the 16 bytes of v if v is a representation of a UUID.
+func parseUUID(v interface{}) (u [16]byte, ok bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Array && rv.Len() == 16 && rv.Type().Elem().Kind() == reflect.Uint8:
+		for i := range u {
+			u[i] = byte(rv.Index(i).Uint())
+		}
+		return u, true
+	case rv.Kind() == reflect.String:
+		s := rv.String()
+		if len(s) == 36+len("urn:uuid:") && strings.EqualFold(s[:len("urn:uuid:")], "urn:uuid:") {
+			s = s[len("urn:uuid:"):]
+		} else if len(s) == 36+2 && s[0] == '{' && s[len(s)-1] == '}' {
+			s = s[1 : len(s)-1]
+		}
+		if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, false
+		}
+		b, err := hex.DecodeString(s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+		if err != nil {
+			return u, false
+		}
+		copy(u[:], b)
+		return u, true
+	default:
+		return u, false
+	}
+}
+
+// EquateRegexps returns a Comparer option that determines two *regexp.Regexp
+// values to be equal if they were compiled from the same source pattern,
+// as reported by the String method. Inline flags (e.g., "(?i)") are part of
+// the pattern and are thus compared as well. Whether leftmost-longest matching
+// was selected by Longest or CompilePOSIX is not observable and is ignored.
+// A nil regular expression is only equal to another nil regular expression.
+//
+// Comparing the compiled program structurally is meaningless and
+// would otherwise panic because of unexported fields.
+func EquateRegexps() cmp.Option {
+	return cmp.Comparer(equateRegexps)
+}
+
+func equateRegexps(x, y *regexp.Regexp) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return x.String() == y.String()
+}
+
+// EquateReflectTypes returns a Comparer option that determines two
+// reflect.Type values to be equal if they represent the same type.
+// Since every Go type has exactly one reflect.Type, this is an identity check.
+func EquateReflectTypes() cmp.Option {
+	return cmp.Comparer(func(x, y reflect.Type) bool { return x == y })
+}
+
+// EquateReflectValues returns a Transformer option that compares
+// reflect.Value values according to the values they hold, such that all other
+// options continue to apply to the held values. The zero reflect.Value is
+// transformed into a nil interface{}.
+//
+// This option panics if the held value cannot be obtained because
+// it was derived from an unexported struct field.
+func EquateReflectValues() cmp.Option {
+	return cmp.Transformer("cmpopts.EquateReflectValues", func(v reflect.Value) interface{} {
+		if !v.IsValid() {
+			return nil
+		}
+		if !v.CanInterface() {
+			panic(fmt.Sprintf("cannot obtain value of %v derived from an unexported field", v.Type()))
+		}
+		return v.Interface()
+	})
+}
+
+// EquateChannels returns a Comparer option that determines two channels to be
+// equal if they are the same channel or are both nil. Channels of the same type
+// are already compared this way by default; this option additionally equates
+// channels of different directions (e.g., a chan T and a <-chan T held in
+// interface{} values) that refer to the same underlying channel.
+// The channels must have identical element types.
+//
+// This is useful for structs wiring up pipelines, where the property under
+// test is that the same channel is shared between stages.
+func EquateChannels() cmp.Option {
+	return cmp.FilterValues(areChannels, cmp.Comparer(equateChannels))
+}
+
+func areChannels(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	return (x != nil && y != nil) &&
+		(vx.Kind() == reflect.Chan && vy.Kind() == reflect.Chan) &&
+		(vx.Type().Elem() == vy.Type().Elem())
+}
+func equateChannels(x, y interface{}) bool {
+	return reflect.ValueOf(x).Pointer() == reflect.ValueOf(y).Pointer()
+}
+
+// EquateBinaryMarshaled returns a Comparer option that determines two values
+// of a type implementing encoding.BinaryMarshaler to be equal if they marshal
+// to the same bytes. This is useful for types with a canonical binary form,
+// but with an in-memory representation that differs for equal values.
+// The option panics if MarshalBinary reports an error.
+//
+// This option is only used when both values are non-nil. Since many types in
+// the standard library (e.g., time.Time and url.URL) implement
+// encoding.BinaryMarshaler, consider combining this option with a filter
+// that restricts it to the intended types.
+//
+// To see the marshaled bytes in reports, use TransformBinaryMarshaled instead.
+func EquateBinaryMarshaled() cmp.Option {
+	return cmp.FilterValues(areBinaryMarshalers, cmp.Comparer(equateBinaryMarshaled))
+}
+
+// TransformBinaryMarshaled returns a Transformer option that transforms
+// values of a type implementing encoding.BinaryMarshaler into their marshaled
+// bytes, formatted as a hexadecimal string. It is identical to
+// EquateBinaryMarshaled, except that reports show the marshaled form.
+func TransformBinaryMarshaled() cmp.Option {
+	return cmp.FilterValues(areBinaryMarshalers, cmp.Transformer("cmpopts.TransformBinaryMarshaled", func(m encoding.BinaryMarshaler) string {
+		return hex.EncodeToString(marshalBinary(m))
+	}))
+}
+
+func areBinaryMarshalers(x, y encoding.BinaryMarshaler) bool {
+	isNil := func(m encoding.BinaryMarshaler) bool {
+		v := reflect.ValueOf(m)
+		return m == nil || (v.Kind() == reflect.Ptr && v.IsNil())
+	}
+	return !isNil(x) && !isNil(y)
+}
+func equateBinaryMarshaled(x, y encoding.BinaryMarshaler) bool {
+	return bytes.Equal(marshalBinary(x), marshalBinary(y))
+}
+
+func marshalBinary(m encoding.BinaryMarshaler) []byte {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		panic(fmt.Sprintf("cannot marshal %T: %v", m, err))
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// +build go1.13
+
+package cmpopts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEquateErrors(t *testing.T) {
+	type result struct {
+		N   int
+		Err error
+	}
+	wrapped := fmt.Errorf("read failed: %w", io.EOF)
+
+	tests := []struct {
+		label     string
+		x, y      interface{}
+		wantEqual bool
+		reason    string
+	}{{
+		label:     "Is",
+		x:         result{1, io.EOF},
+		y:         result{1, wrapped},
+		wantEqual: true,
+		reason:    "equal because the wrapped error matches the sentinel",
+	}, {
+		label:     "IsReversed",
+		x:         result{1, wrapped},
+		y:         result{1, io.EOF},
+		wantEqual: true,
+		reason:    "equal because errors.Is is checked in both directions",
+	}, {
+		label:     "NotIs",
+		x:         result{1, io.EOF},
+		y:         result{1, io.ErrUnexpectedEOF},
+		wantEqual: false,
+		reason:    "not equal because the errors do not match",
+	}, {
+		label:     "DifferentMessages",
+		x:         result{1, errors.New("a")},
+		y:         result{1, errors.New("a")},
+		wantEqual: false,
+		reason:    "not equal because distinct errors do not match, even with the same message",
+	}, {
+		label:     "AnyError",
+		x:         result{1, AnyError},
+		y:         result{1, wrapped},
+		wantEqual: true,
+		reason:    "equal because AnyError matches any non-nil error",
+	}, {
+		label:     "AnyErrorNil",
+		x:         result{1, AnyError},
+		y:         result{1, nil},
+		wantEqual: false,
+		reason:    "not equal because AnyError does not match a nil error",
+	}, {
+		label:     "Interfaces",
+		x:         []interface{}{io.EOF, "x"},
+		y:         []interface{}{wrapped, "x"},
+		wantEqual: true,
+		reason:    "equal because errors held in empty interfaces are also matched",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got := cmp.Equal(tt.x, tt.y, EquateErrors())
+			if got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v\nreason: %v", got, tt.wantEqual, tt.reason)
+			}
+		})
+	}
+}
@@ -7,10 +7,12 @@ package cmpopts
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/internal/function"
 )
 
 // IgnoreFields returns an Option that ignores exported fields of the
@@ -33,6 +35,21 @@ func IgnoreTypes(typs ...interface{}) cmp.Option {
 	return cmp.FilterPath(tf.filter, cmp.Ignore())
 }
 
+// IgnoreSyncPrimitives returns an Option that ignores all values of the
+// sync.Mutex, sync.RWMutex, sync.Once, and sync.WaitGroup types.
+// These carry no comparable semantic state, yet make any struct that contains
+// them uncomparable without an option since their fields are unexported.
+func IgnoreSyncPrimitives() cmp.Option {
+	return cmp.FilterPath(syncFilter.filter, cmp.Ignore())
+}
+
+var syncFilter = typeFilter{
+	reflect.TypeOf((*sync.Mutex)(nil)).Elem(),
+	reflect.TypeOf((*sync.RWMutex)(nil)).Elem(),
+	reflect.TypeOf((*sync.Once)(nil)).Elem(),
+	reflect.TypeOf((*sync.WaitGroup)(nil)).Elem(),
+}
+
 type typeFilter []reflect.Type
 
 func newTypeFilter(typs ...interface{}) (tf typeFilter) {
@@ -107,6 +124,118 @@ func (tf ifaceFilter) filter(p cmp.Path) bool {
 	return false
 }
 
+// IgnoreSliceElements returns a Transformer option that removes the elements
+// of all []V for which the discard function reports true from both slices
+// prior to comparing them. The discard function must be of the form
+// "func(T) bool" which is used on any slice with element type V that is
+// assignable to T. The indexes in the reported paths are those of the slices
+// after the elements are removed.
+//
+// Removing every element of a non-nil slice leaves an empty slice, which
+// is unequal to a nil slice unless IgnoreSliceElements is used in conjunction
+// with EquateEmpty.
+func IgnoreSliceElements(discard interface{}) cmp.Option {
+	vf := reflect.ValueOf(discard)
+	if !function.IsType(vf.Type(), function.ValuePredicate) || vf.IsNil() {
+		panic(fmt.Sprintf("invalid discard function: %T", discard))
+	}
+	sd := sliceDiscarder{vf.Type().In(0), vf}
+	return cmp.FilterValues(sd.filter, cmp.Transformer("cmpopts.IgnoreSliceElements", sd.discard))
+}
+
+type sliceDiscarder struct {
+	in  reflect.Type  // T
+	fnc reflect.Value // func(T) bool
+}
+
+func (sd sliceDiscarder) filter(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if !(x != nil && y != nil && vx.Type() == vy.Type()) ||
+		!(vx.Kind() == reflect.Slice && vx.Type().Elem().AssignableTo(sd.in)) {
+		return false
+	}
+	// Only apply the transform if there is something to discard to avoid
+	// an infinite recursion cycle applying the same transform to itself.
+	return sd.hasDiscarded(vx) || sd.hasDiscarded(vy)
+}
+func (sd sliceDiscarder) hasDiscarded(v reflect.Value) bool {
+	for i := 0; i < v.Len(); i++ {
+		if sd.fnc.Call([]reflect.Value{v.Index(i)})[0].Bool() {
+			return true
+		}
+	}
+	return false
+}
+func (sd sliceDiscarder) discard(x interface{}) interface{} {
+	src := reflect.ValueOf(x)
+	if src.IsNil() {
+		return x
+	}
+	dst := reflect.MakeSlice(src.Type(), 0, src.Len())
+	for i := 0; i < src.Len(); i++ {
+		if !sd.fnc.Call([]reflect.Value{src.Index(i)})[0].Bool() {
+			dst = reflect.Append(dst, src.Index(i))
+		}
+	}
+	return dst.Interface()
+}
+
+// IgnoreMapEntries returns a Transformer option that removes the entries
+// of all map[K]V for which the discard function reports true from both maps
+// prior to comparing them. The discard function must be of the form
+// "func(T, R) bool" which is used on any map with key K that is assignable
+// to T and value V that is assignable to R.
+//
+// Removing every entry of a non-nil map leaves an empty map, which
+// is unequal to a nil map unless IgnoreMapEntries is used in conjunction
+// with EquateEmpty.
+func IgnoreMapEntries(discard interface{}) cmp.Option {
+	vf := reflect.ValueOf(discard)
+	if !function.IsType(vf.Type(), function.KeyValuePredicate) || vf.IsNil() {
+		panic(fmt.Sprintf("invalid discard function: %T", discard))
+	}
+	md := mapDiscarder{vf.Type().In(0), vf.Type().In(1), vf}
+	return cmp.FilterValues(md.filter, cmp.Transformer("cmpopts.IgnoreMapEntries", md.discard))
+}
+
+type mapDiscarder struct {
+	key reflect.Type  // T
+	val reflect.Type  // R
+	fnc reflect.Value // func(T, R) bool
+}
+
+func (md mapDiscarder) filter(x, y interface{}) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if !(x != nil && y != nil && vx.Type() == vy.Type()) ||
+		!(vx.Kind() == reflect.Map && vx.Type().Key().AssignableTo(md.key) && vx.Type().Elem().AssignableTo(md.val)) {
+		return false
+	}
+	// Only apply the transform if there is something to discard to avoid
+	// an infinite recursion cycle applying the same transform to itself.
+	return md.hasDiscarded(vx) || md.hasDiscarded(vy)
+}
+func (md mapDiscarder) hasDiscarded(v reflect.Value) bool {
+	for _, k := range v.MapKeys() {
+		if md.fnc.Call([]reflect.Value{k, v.MapIndex(k)})[0].Bool() {
+			return true
+		}
+	}
+	return false
+}
+func (md mapDiscarder) discard(x interface{}) interface{} {
+	src := reflect.ValueOf(x)
+	if src.IsNil() {
+		return x
+	}
+	dst := reflect.MakeMap(src.Type())
+	for _, k := range src.MapKeys() {
+		if v := src.MapIndex(k); !md.fnc.Call([]reflect.Value{k, v})[0].Bool() {
+			dst.SetMapIndex(k, v)
+		}
+	}
+	return dst.Interface()
+}
+
 // IgnoreUnexported returns an Option that only ignores the immediate unexported
 // fields of a struct, including anonymous fields of unexported types.
 // In particular, unexported fields within the struct's exported fields
@@ -142,6 +271,70 @@ func (xf unexportedFilter) filter(p cmp.Path) bool {
 	return xf.m[p.Index(-2).Type()] && !isExported(sf.Name())
 }
 
+// Subset returns an Option that only compares the portions of the values that
+// are set in x, which is the expected value (e.g., as in cmp.Diff(want, got)),
+// such that a test may assert only the fields it cares about.
+// Every node where the value in x is the zero value of its type is ignored,
+// as are map entries that only exist in y. Thus, the entries of a map in x
+// must be a subset of the entries in y. Slices are still compared element by
+// element, such that a slice in x must have the same length as in y,
+// although the zero values in the elements are ignored.
+// Since zero values are never compared, a field cannot be asserted to be zero,
+// nor can a slice or map be asserted to be nil.
+//
+// The nodes skipped by Subset are reported as ignored by this option,
+// such that cmp.AuditIgnored and cmp.RecordCoverage identify exactly
+// which paths were checked.
+func Subset() cmp.Option {
+	return cmp.FilterPath(subsetFilter, cmp.Ignore())
+}
+
+func subsetFilter(p cmp.Path) bool {
+	vx, vy := p.Last().Values()
+	if _, ok := p.Last().(cmp.SliceIndex); ok {
+		// Elements that only exist in either slice always differ.
+		return vx.IsValid() && vy.IsValid() && isZero(vx)
+	}
+	return !vx.IsValid() || isZero(vx)
+}
+
+// isZero reports whether v is the zero value of its type.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	case reflect.UnsafePointer:
+		return v.Pointer() == 0
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 // isExported reports whether the identifier is exported.
 func isExported(id string) bool {
 	r, _ := utf8.DecodeRuneInString(id)
@@ -0,0 +1,64 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpopts
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TransformJSON returns a Transformer option that decodes []byte and string
+// values (including json.RawMessage) into interface{} values before comparing
+// them, such that two JSON documents are equal regardless of the order of
+// object members and of whitespace. Reports of differences name the decoded
+// object members and array elements that differ.
+//
+// The option only applies if both values are valid JSON documents. Numbers are
+// decoded as json.Number, preserving their exact text, so that large integers
+// are never rounded and numbers such as 1 and 1.0 remain unequal.
+// Since any string holding a valid JSON document is decoded (e.g., "true"),
+// consider combining this option with a filter that restricts it to the
+// intended values. Strings within the decoded documents that are themselves
+// JSON documents are also decoded.
+func TransformJSON() cmp.Option {
+	return cmp.Options{
+		cmp.FilterValues(func(x, y []byte) bool {
+			return isJSON(bytes.NewReader(x)) && isJSON(bytes.NewReader(y))
+		}, cmp.Transformer("cmpopts.TransformJSON", func(b []byte) interface{} {
+			return decodeJSON(bytes.NewReader(b))
+		})),
+		cmp.FilterValues(func(x, y string) bool {
+			return isJSON(strings.NewReader(x)) && isJSON(strings.NewReader(y))
+		}, cmp.Transformer("cmpopts.TransformJSON", func(s string) interface{} {
+			return decodeJSON(strings.NewReader(s))
+		})),
+	}
+}
+
+// isJSON reports whether r holds exactly one valid JSON document.
+func isJSON(r io.Reader) bool {
+	dec := json.NewDecoder(r)
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		return false
+	}
+	_, err := dec.Token()
+	return err == io.EOF
+}
+
+// decodeJSON decodes the JSON document in r, which must be valid.
+func decodeJSON(r io.Reader) interface{} {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		panic(err) // Unreachable, since the document was already validated
+	}
+	return v
+}
@@ -0,0 +1,32 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// +build go1.18
+
+package cmpopts
+
+import (
+	"net/netip"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// EquateNetIP returns a Comparer option that determines netip.Addr,
+// netip.AddrPort, and netip.Prefix values to be equal according to
+// their Compare methods. This is needed since these types contain
+// unexported fields and thus cannot be compared structurally.
+//
+// In particular, an IPv4 address and its IPv4-mapped IPv6 form are not equal,
+// and addresses with different IPv6 zones are not equal.
+// Prefixes are equal if both their addresses and their lengths are equal;
+// they are not masked before comparison.
+func EquateNetIP() cmp.Option {
+	return cmp.Options{
+		cmp.Comparer(func(x, y netip.Addr) bool { return x.Compare(y) == 0 }),
+		cmp.Comparer(func(x, y netip.AddrPort) bool { return x.Compare(y) == 0 }),
+		cmp.Comparer(func(x, y netip.Prefix) bool {
+			return x.Addr().Compare(y.Addr()) == 0 && x.Bits() == y.Bits()
+		}),
+	}
+}
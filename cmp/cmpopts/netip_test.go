@@ -0,0 +1,95 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+// +build go1.18
+
+package cmpopts
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEquateNetIP(t *testing.T) {
+	type endpoint struct {
+		Addr    netip.Addr
+		Port    netip.AddrPort
+		Network netip.Prefix
+	}
+	mustEndpoint := func(addr, port, network string) endpoint {
+		return endpoint{
+			netip.MustParseAddr(addr),
+			netip.MustParseAddrPort(port),
+			netip.MustParsePrefix(network),
+		}
+	}
+
+	tests := []struct {
+		label     string
+		x, y      interface{}
+		wantEqual bool
+		reason    string
+	}{{
+		label:     "Equal",
+		x:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/8"),
+		y:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/8"),
+		wantEqual: true,
+		reason:    "equal because all fields are identical",
+	}, {
+		label:     "IPv6",
+		x:         []netip.Addr{netip.MustParseAddr("2001:db8::1")},
+		y:         []netip.Addr{netip.MustParseAddr("2001:0db8:0:0::1")},
+		wantEqual: true,
+		reason:    "equal because both strings parse to the same address",
+	}, {
+		label:     "Addr",
+		x:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/8"),
+		y:         mustEndpoint("10.0.0.2", "10.0.0.1:80", "10.0.0.0/8"),
+		wantEqual: false,
+		reason:    "not equal because the addresses differ",
+	}, {
+		label:     "IPv4Mapped",
+		x:         netip.MustParseAddr("10.0.0.1"),
+		y:         netip.MustParseAddr("::ffff:10.0.0.1"),
+		wantEqual: false,
+		reason:    "not equal because an IPv4-mapped IPv6 address is distinct",
+	}, {
+		label:     "Zone",
+		x:         netip.MustParseAddr("fe80::1%eth0"),
+		y:         netip.MustParseAddr("fe80::1%eth1"),
+		wantEqual: false,
+		reason:    "not equal because the zones differ",
+	}, {
+		label:     "AddrPort",
+		x:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/8"),
+		y:         mustEndpoint("10.0.0.1", "10.0.0.1:443", "10.0.0.0/8"),
+		wantEqual: false,
+		reason:    "not equal because the ports differ",
+	}, {
+		label:     "Prefix",
+		x:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/8"),
+		y:         mustEndpoint("10.0.0.1", "10.0.0.1:80", "10.0.0.0/16"),
+		wantEqual: false,
+		reason:    "not equal because the prefix lengths differ",
+	}, {
+		label:     "Prefix",
+		x:         netip.MustParsePrefix("10.1.0.0/8"),
+		y:         netip.MustParsePrefix("10.0.0.0/8"),
+		wantEqual: false,
+		reason:    "not equal because prefixes are not masked",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if tt.reason == "" {
+				t.Errorf("reason must be provided")
+			}
+			if got := cmp.Equal(tt.x, tt.y, EquateNetIP()); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v\nreason: %v", got, tt.wantEqual, tt.reason)
+			}
+		})
+	}
+}
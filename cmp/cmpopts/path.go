@@ -0,0 +1,218 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpopts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// FilterPathPattern returns a new Option where opt is only evaluated if
+// the path to the current node matches the pattern, which is written in the
+// syntax of the paths that Diff prints (e.g., "Foo.Bar[*].Baz").
+// This allows expressing options for specific nodes declaratively,
+// such as FilterPathPattern("Items[*].ID", cmp.Ignore()).
+//
+// A pattern is a sequence of the following steps, which are matched against
+// the steps of the path from the root:
+//	• ".Name" matches the struct field called Name, where the leading period
+//	may be omitted for the first step.
+//	• "[N]" matches the slice or array element at index N in either value,
+//	or the map entry whose key is printed as N.
+//	• "[\"key\"]" matches the map entry with the string key "key",
+//	where the key is written as a quoted Go string literal.
+//	• ".*" matches any struct field, and "[*]" matches any slice or array
+//	element or map entry.
+//
+// The pattern may start with the type of the root values in braces
+// (e.g., "{mypkg.Config}.Servers[*].Addr"), or with "root", as printed by Diff.
+// The type, if specified, must match the string of the type of the root values.
+// Pointer indirections, type assertions, and transformations (e.g., those of
+// SortSlices) are skipped when matching, and are not written in the pattern.
+//
+// FilterPathPattern panics if the pattern is invalid.
+func FilterPathPattern(pattern string, opt cmp.Option) cmp.Option {
+	pp, err := parsePathPattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("invalid path pattern %q: %v", pattern, err))
+	}
+	return cmp.FilterPath(pp.match, opt)
+}
+
+// pathPattern is a parsed path pattern.
+type pathPattern struct {
+	root  string // Type of the root values; empty if any
+	steps []patternStep
+}
+
+// patternStep is a single step of a path pattern.
+type patternStep struct {
+	field bool   // Whether the step matches a struct field, rather than an index
+	any   bool   // Whether the step matches any field or index
+	name  string // Name of the field, or the printed index or map key
+	key   string // Value of a string map key, if isKey is set
+	index int    // Value of the index, if isInt is set
+	isInt bool   // Whether name is an integer, such that it may be an index
+	isKey bool   // Whether name is a quoted string, such that key is set
+}
+
+// parsePathPattern parses a pattern as documented by FilterPathPattern.
+func parsePathPattern(s string) (pp pathPattern, err error) {
+	switch {
+	case strings.HasPrefix(s, "{"):
+		i := matchBrace(s)
+		if i < 0 {
+			return pp, errors.New("unterminated root type")
+		}
+		pp.root, s = s[1:i], s[i+1:]
+	case s == "root" || strings.HasPrefix(s, "root.") || strings.HasPrefix(s, "root["):
+		s = strings.TrimPrefix(s, "root")
+	case s != "" && s[0] != '.' && s[0] != '[':
+		s = "." + s // Leading period of the first field is optional
+	}
+
+	for s != "" {
+		var ps patternStep
+		switch s[0] {
+		case '.':
+			i := strings.IndexAny(s[1:], ".[") + 1
+			if i == 0 {
+				i = len(s)
+			}
+			ps.field, ps.name, s = true, s[1:i], s[i:]
+			switch {
+			case ps.name == "*":
+				ps.any = true
+			case !isIdentifier(ps.name):
+				return pp, fmt.Errorf("invalid field name %q", ps.name)
+			}
+		case '[':
+			i := matchBracket(s)
+			if i < 0 {
+				return pp, errors.New("unterminated index")
+			}
+			ps.name, s = s[1:i], s[i+1:]
+			if ps.name == "" {
+				return pp, errors.New("empty index")
+			}
+			if ps.name == "*" {
+				ps.any = true
+			}
+			if n, err := strconv.Atoi(ps.name); err == nil {
+				ps.index, ps.isInt = n, true
+			}
+			if k, err := strconv.Unquote(ps.name); err == nil && ps.name[0] != '\'' {
+				ps.key, ps.isKey = k, true
+			}
+		default:
+			return pp, fmt.Errorf("unexpected %q", s[0])
+		}
+		pp.steps = append(pp.steps, ps)
+	}
+	return pp, nil
+}
+
+// matchBrace returns the index of the brace that closes the brace at s[0],
+// or -1 if there is none.
+func matchBrace(s string) int {
+	var depth int
+	for i, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// matchBracket returns the index of the bracket that closes the bracket at
+// s[0], ignoring any brackets within quoted strings, or -1 if there is none.
+func matchBracket(s string) int {
+	var depth int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '`':
+			q := s[i]
+			for i++; i < len(s) && s[i] != q; i++ {
+				if s[i] == '\\' && q == '"' {
+					i++
+				}
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isIdentifier reports whether s is a valid Go identifier.
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// match reports whether the path p matches the pattern.
+func (pp pathPattern) match(p cmp.Path) bool {
+	if len(p) == 0 {
+		return false
+	}
+	if pp.root != "" && (p[0].Type() == nil || p[0].Type().String() != pp.root) {
+		return false
+	}
+	steps := pp.steps
+	for _, ps := range p[1:] {
+		switch ps.(type) {
+		case cmp.Indirect, cmp.TypeAssertion, cmp.Transform:
+			continue
+		}
+		if len(steps) == 0 || !steps[0].match(ps) {
+			return false
+		}
+		steps = steps[1:]
+	}
+	return len(steps) == 0
+}
+
+// match reports whether the path step matches ps.
+func (ps patternStep) match(step cmp.PathStep) bool {
+	switch step := step.(type) {
+	case cmp.StructField:
+		return ps.field && (ps.any || ps.name == step.Name())
+	case cmp.SliceIndex:
+		if ps.field {
+			return false
+		}
+		ix, iy := step.SplitKeys()
+		return ps.any || (ps.isInt && (ps.index == ix || ps.index == iy))
+	case cmp.MapIndex:
+		if ps.field {
+			return false
+		}
+		k := step.Key()
+		if ps.isKey && k.Kind() == reflect.String {
+			return ps.key == k.String()
+		}
+		return ps.any || ps.name == fmt.Sprintf("%#v", k)
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmpopts
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// EquateProtoWellKnownTypes returns a Transformer option that converts the
+// well-known protocol buffer types into their native Go equivalents, such that
+// a message compares equal to the Go value that it represents.
+// This is useful for tests that compare domain objects against the messages
+// they are converted to (e.g., an interface{} holding a *timestamppb.Timestamp
+// against one holding a time.Time).
+//
+// The following conversions are performed:
+//	• Timestamp to time.Time in UTC
+//	• Duration to time.Duration
+//	• DoubleValue, FloatValue, Int64Value, UInt64Value, Int32Value,
+//	UInt32Value, BoolValue, StringValue, and BytesValue to the scalar they wrap
+//
+// To avoid a dependency on any protobuf implementation, the types are
+// identified by their name and the fields they have in generated Go code.
+// A nil pointer to a well-known type is converted to a nil interface{}.
+// The option applies whenever either value being compared is of a
+// well-known type (or a pointer to one).
+func EquateProtoWellKnownTypes() cmp.Option {
+	return cmp.FilterValues(func(x, y interface{}) bool {
+		return wellKnownKind(reflect.TypeOf(x)) != notWellKnown ||
+			wellKnownKind(reflect.TypeOf(y)) != notWellKnown
+	}, cmp.Transformer("cmpopts.EquateProtoWellKnownTypes", fromWellKnown))
+}
+
+type wellKnown int
+
+const (
+	notWellKnown wellKnown = iota
+	wellKnownTimestamp
+	wellKnownDuration
+	wellKnownWrapper
+)
+
+// wrapperKinds maps the name of each wrapper type to the kind of its value.
+var wrapperKinds = map[string]reflect.Kind{
+	"DoubleValue": reflect.Float64,
+	"FloatValue":  reflect.Float32,
+	"Int64Value":  reflect.Int64,
+	"UInt64Value": reflect.Uint64,
+	"Int32Value":  reflect.Int32,
+	"UInt32Value": reflect.Uint32,
+	"BoolValue":   reflect.Bool,
+	"StringValue": reflect.String,
+	"BytesValue":  reflect.Slice,
+}
+
+func wellKnownKind(t reflect.Type) wellKnown {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return notWellKnown
+	}
+	hasField := func(name string, k reflect.Kind) bool {
+		sf, ok := t.FieldByName(name)
+		if !ok || sf.Type.Kind() != k {
+			return false
+		}
+		return k != reflect.Slice || sf.Type.Elem().Kind() == reflect.Uint8
+	}
+	switch name := t.Name(); name {
+	case "Timestamp", "Duration":
+		if hasField("Seconds", reflect.Int64) && hasField("Nanos", reflect.Int32) {
+			if name == "Timestamp" {
+				return wellKnownTimestamp
+			}
+			return wellKnownDuration
+		}
+	default:
+		if k, ok := wrapperKinds[name]; ok && hasField("Value", k) {
+			return wellKnownWrapper
+		}
+	}
+	return notWellKnown
+}
+
+func fromWellKnown(x interface{}) interface{} {
+	k := wellKnownKind(reflect.TypeOf(x))
+	if k == notWellKnown {
+		return x
+	}
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch k {
+	case wellKnownTimestamp:
+		secs, nanos := v.FieldByName("Seconds").Int(), v.FieldByName("Nanos").Int()
+		return time.Unix(secs, nanos).UTC()
+	case wellKnownDuration:
+		secs, nanos := v.FieldByName("Seconds").Int(), v.FieldByName("Nanos").Int()
+		return time.Duration(secs)*time.Second + time.Duration(nanos)
+	default:
+		return v.FieldByName("Value").Interface()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/internal/function"
@@ -145,3 +146,78 @@ func (ms mapSorter) less(v reflect.Value, i, j int) bool {
 	vx, vy := v.Index(i).Field(0), v.Index(j).Field(0)
 	return ms.fnc.Call([]reflect.Value{vx, vy})[0].Bool()
 }
+
+// LessFromSortInterface adapts an existing sort.Interface implementation into
+// a less function of the form "func(T, T) bool", which is suitable for use with
+// SortSlices or SortMaps. The type of s must be a slice type (or a pointer to a
+// slice type) that implements sort.Interface, and T is its element type.
+// Only the type of s is used; its contents are ignored.
+//
+// The Less method of s must satisfy the same requirements as the less function
+// passed to SortSlices or SortMaps.
+func LessFromSortInterface(s sort.Interface) interface{} {
+	t := reflect.TypeOf(s)
+	st := t
+	if st != nil && st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st == nil || st.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("invalid sort.Interface type: %T", s))
+	}
+	return makeLess(st.Elem(), func(vx, vy reflect.Value) bool {
+		v := reflect.MakeSlice(st, 2, 2)
+		v.Index(0).Set(vx)
+		v.Index(1).Set(vy)
+		if t.Kind() == reflect.Ptr {
+			p := reflect.New(st)
+			p.Elem().Set(v)
+			v = p
+		}
+		return v.Interface().(sort.Interface).Less(0, 1)
+	})
+}
+
+// LessFromIndexFunc adapts a less function of the form "func(i, j int) bool",
+// as used by sort.Slice, into a less function of the form "func(T, T) bool",
+// which is suitable for use with SortSlices or SortMaps.
+// The less function must index into the slice pointed to by ptr,
+// which must be of type *[]T. For example:
+//
+//	var ps []Person
+//	less := LessFromIndexFunc(&ps, func(i, j int) bool { return ps[i].Name < ps[j].Name })
+//	opt := SortSlices(less)
+//
+// Each call of the returned function temporarily stores the two elements being
+// compared in the slice pointed to by ptr, and restores the original slice
+// before returning. Calls are serialized with respect to each other, but ptr
+// must not otherwise be accessed while the returned function is in use.
+func LessFromIndexFunc(ptr interface{}, less func(i, j int) bool) interface{} {
+	vp := reflect.ValueOf(ptr)
+	if vp.Kind() != reflect.Ptr || vp.IsNil() || vp.Elem().Kind() != reflect.Slice {
+		panic(fmt.Sprintf("invalid slice pointer: %T", ptr))
+	}
+	if less == nil {
+		panic("invalid nil less function")
+	}
+	var mu sync.Mutex
+	st := vp.Elem().Type()
+	return makeLess(st.Elem(), func(vx, vy reflect.Value) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		old := reflect.ValueOf(vp.Elem().Interface())
+		defer vp.Elem().Set(old)
+		v := reflect.MakeSlice(st, 2, 2)
+		v.Index(0).Set(vx)
+		v.Index(1).Set(vy)
+		vp.Elem().Set(v)
+		return less(0, 1)
+	})
+}
+
+// makeLess returns a function of the form "func(T, T) bool" that calls f.
+func makeLess(t reflect.Type, f func(vx, vy reflect.Value) bool) interface{} {
+	ft := reflect.FuncOf([]reflect.Type{t, t}, []reflect.Type{reflect.TypeOf(true)}, false)
+	return reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf(f(in[0], in[1]))}
+	}).Interface()
+}
@@ -6,16 +6,22 @@ package cmpopts
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	pb "github.com/google/go-cmp/cmp/internal/testprotos"
 )
 
 type (
@@ -63,9 +69,88 @@ type (
 	}
 
 	EmptyInterface interface{}
+
+	byLength []string
+	myUUID   [16]byte
+
+	UserDTO struct {
+		ID      string      `json:"id"`
+		Name    string      `json:"display_name"`
+		Address *AddressDTO `json:"address,omitempty"`
+		Secret  string      `json:"-"`
+	}
+	AddressDTO struct {
+		City string `json:"city"`
+	}
+	User struct {
+		ID          string  `json:"id"`
+		DisplayName string  `json:"display_name"`
+		Address     Address `json:"address"`
+	}
+	Address struct {
+		City string `json:"city"`
+	}
+	AuditedUser struct {
+		User
+		Revision int
+	}
+	Base struct {
+		ID   int
+		Name string
+	}
+	Shadowing struct {
+		Base
+		ID int
+	}
+	Labeled struct {
+		Name string
+	}
+	Ambiguous struct {
+		Base
+		Labeled
+	}
+
+	fraction     struct{ Num, Den int }
+	badMarshaler struct{}
+
+	syncCounter struct {
+		mu   sync.Mutex
+		RW   sync.RWMutex
+		Once *sync.Once
+		WG   sync.WaitGroup
+		N    int
+	}
 )
 
+// newSyncCounter returns a syncCounter whose sync primitives are all in use.
+func newSyncCounter(n int) *syncCounter {
+	c := &syncCounter{Once: new(sync.Once), N: n}
+	c.mu.Lock()
+	c.RW.RLock()
+	c.Once.Do(func() {})
+	c.WG.Add(1)
+	return c
+}
+
+// MarshalBinary encodes the fraction in lowest terms.
+func (f fraction) MarshalBinary() ([]byte, error) {
+	a, b := f.Num, f.Den
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return []byte{byte(f.Num / a), byte(f.Den / a)}, nil
+}
+
+func (*badMarshaler) MarshalBinary() ([]byte, error) { return nil, errors.New("bad") }
+
+var sharedChan = make(chan int)
+
+func (s *byLength) Len() int           { return len(*s) }
+func (s *byLength) Less(i, j int) bool { return len((*s)[i]) < len((*s)[j]) }
+func (s *byLength) Swap(i, j int)      { (*s)[i], (*s)[j] = (*s)[j], (*s)[i] }
+
 func TestOptions(t *testing.T) {
+	now := time.Now()
 	createBar3X := func() *Bar3 {
 		return &Bar3{
 			Bar1: Bar1{Foo3{&Foo2{&Foo1{Bravo: 2}}}},
@@ -397,6 +482,41 @@ func TestOptions(t *testing.T) {
 		opts:      []cmp.Option{EquateApprox(0, 0)},
 		wantEqual: false,
 		reason:    "not equal because EquateApprox(0, 0) is equivalent to ==",
+	}, {
+		label:     "EquateApproxTime",
+		x:         []time.Time{now, now.Add(-time.Second)},
+		y:         []time.Time{now.Add(time.Second), now},
+		opts:      []cmp.Option{EquateApproxTime(time.Second)},
+		wantEqual: true,
+		reason:    "equal because the times are within the margin in either order",
+	}, {
+		label:     "EquateApproxTime",
+		x:         []time.Time{now},
+		y:         []time.Time{now.Add(time.Second + 1)},
+		opts:      []cmp.Option{EquateApproxTime(time.Second)},
+		wantEqual: false,
+		reason:    "not equal because the times are beyond the margin",
+	}, {
+		label:     "EquateApproxTime",
+		x:         []time.Time{now.Round(0)},
+		y:         []time.Time{now.Round(0).In(time.FixedZone("UTC+5", 5*60*60)).Add(time.Millisecond)},
+		opts:      []cmp.Option{EquateApproxTime(time.Second)},
+		wantEqual: true,
+		reason:    "equal because the times are within the margin, regardless of location",
+	}, {
+		label:     "EquateApproxTime",
+		x:         []time.Time{{}},
+		y:         []time.Time{time.Unix(0, 0)},
+		opts:      []cmp.Option{EquateApproxTime(1 << 62)},
+		wantEqual: false,
+		reason:    "not equal because the zero time is only equal to itself",
+	}, {
+		label:     "EquateApproxTime",
+		x:         []time.Time{time.Unix(-1<<62, 0)},
+		y:         []time.Time{time.Unix(1<<62, 0)},
+		opts:      []cmp.Option{EquateApproxTime(time.Hour)},
+		wantEqual: false,
+		reason:    "not equal even though the difference overflows a duration",
 	}, {
 		label:     "EquateNaNs",
 		x:         []float64{1.0, math.NaN(), math.E, -0.0, +0.0, math.Inf(+1), math.Inf(-1)},
@@ -417,6 +537,33 @@ func TestOptions(t *testing.T) {
 		opts:      []cmp.Option{EquateNaNs()},
 		wantEqual: true,
 		reason:    "equal because EquateNaNs operates on float32",
+	}, {
+		label:     "EquateNaNs",
+		x:         []complex128{complex(math.NaN(), 1), complex(2, math.NaN()), 3},
+		y:         []complex128{complex(math.NaN(), 1), complex(2, math.NaN()), 3},
+		wantEqual: false,
+		reason:    "not equal because complex numbers with NaN parts are never equal",
+	}, {
+		label:     "EquateNaNs",
+		x:         []complex128{complex(math.NaN(), 1), complex(2, math.NaN()), 3},
+		y:         []complex128{complex(math.NaN(), 1), complex(2, math.NaN()), 3},
+		opts:      []cmp.Option{EquateNaNs()},
+		wantEqual: true,
+		reason:    "equal because EquateNaNs operates on the parts of complex128",
+	}, {
+		label:     "EquateNaNs",
+		x:         []complex64{complex(float32(math.NaN()), 1)},
+		y:         []complex64{complex(float32(math.NaN()), 2)},
+		opts:      []cmp.Option{EquateNaNs()},
+		wantEqual: false,
+		reason:    "not equal because the imaginary parts differ",
+	}, {
+		label:     "EquateNaNs",
+		x:         []complex64{complex(float32(math.NaN()), 1)},
+		y:         []complex64{complex(1, float32(math.NaN()))},
+		opts:      []cmp.Option{EquateNaNs()},
+		wantEqual: false,
+		reason:    "not equal because the NaNs are in different parts",
 	}, {
 		label: "EquateApprox+EquateNaNs",
 		x:     []float64{1.0, math.NaN(), math.E, -0.0, +0.0, math.Inf(+1), math.Inf(-1), 1.01, 5001},
@@ -586,6 +733,127 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: true,
 		reason:    "equal because bytes.Buffer is ignored by match on multiple interface types",
+	}, {
+		label:     "IgnoreSyncPrimitives",
+		x:         &syncCounter{N: 1},
+		y:         &syncCounter{N: 1},
+		wantPanic: true,
+		reason:    "panics because sync.Mutex has unexported fields",
+	}, {
+		label:     "IgnoreSyncPrimitives",
+		x:         newSyncCounter(1),
+		y:         &syncCounter{Once: new(sync.Once), N: 1},
+		opts:      []cmp.Option{IgnoreSyncPrimitives()},
+		wantEqual: true,
+		reason:    "equal because the sync primitives are ignored, even when in use",
+	}, {
+		label:     "IgnoreSyncPrimitives",
+		x:         newSyncCounter(1),
+		y:         &syncCounter{Once: new(sync.Once), N: 2},
+		opts:      []cmp.Option{IgnoreSyncPrimitives()},
+		wantEqual: false,
+		reason:    "not equal because the other fields differ",
+	}, {
+		label:     "IgnoreSliceElements",
+		x:         []int{1, 0, 2, 0},
+		y:         []int{0, 1, 2},
+		opts:      []cmp.Option{IgnoreSliceElements(func(v int) bool { return v == 0 })},
+		wantEqual: true,
+		reason:    "equal because zero elements are removed from both slices",
+	}, {
+		label:     "IgnoreSliceElements",
+		x:         []int{0},
+		y:         []int{5},
+		opts:      []cmp.Option{IgnoreSliceElements(func(v int) bool { return v == 0 })},
+		wantEqual: false,
+		reason:    "not equal because only the zero element is removed",
+	}, {
+		label:     "IgnoreSliceElements",
+		x:         []MyInt{1, 0},
+		y:         []MyInt{1},
+		opts:      []cmp.Option{IgnoreSliceElements(func(v interface{}) bool { return v == MyInt(0) })},
+		wantEqual: true,
+		reason:    "equal because MyInt is assignable to interface{}",
+	}, {
+		label:     "IgnoreSliceElements",
+		x:         []int{0},
+		y:         []int(nil),
+		opts:      []cmp.Option{IgnoreSliceElements(func(v int) bool { return v == 0 })},
+		wantEqual: false,
+		reason:    "not equal because an empty slice is not a nil slice",
+	}, {
+		label: "IgnoreSliceElements+EquateEmpty",
+		x:     []int{0},
+		y:     []int(nil),
+		opts: []cmp.Option{
+			IgnoreSliceElements(func(v int) bool { return v == 0 }),
+			EquateEmpty(),
+		},
+		wantEqual: true,
+		reason:    "equal because EquateEmpty equates empty and nil slices",
+	}, {
+		label:     "IgnoreMapEntries",
+		x:         map[string]int{"a": 1, "_b": 2},
+		y:         map[string]int{"a": 1, "_c": 3},
+		opts:      []cmp.Option{IgnoreMapEntries(func(k string, _ int) bool { return strings.HasPrefix(k, "_") })},
+		wantEqual: true,
+		reason:    "equal because entries with keys starting with an underscore are removed",
+	}, {
+		label:     "IgnoreMapEntries",
+		x:         map[string]int{"a": 1, "b": 0},
+		y:         map[string]int{"a": 2},
+		opts:      []cmp.Option{IgnoreMapEntries(func(_ string, v int) bool { return v == 0 })},
+		wantEqual: false,
+		reason:    "not equal because the remaining entries differ",
+	}, {
+		label:     "IgnoreMapEntries",
+		x:         map[string]int{"a": 1, "b": 0},
+		y:         map[string]int{"a": 1},
+		opts:      []cmp.Option{IgnoreMapEntries(func(_ string, v MyInt) bool { return v == 0 })},
+		wantEqual: false,
+		reason:    "not equal because int is not assignable to MyInt",
+	}, {
+		label:     "FilterPathPattern",
+		x:         struct{ Items []Foo1 }{[]Foo1{{Alpha: 1, Bravo: 2}, {Alpha: 3, Bravo: 4}}},
+		y:         struct{ Items []Foo1 }{[]Foo1{{Alpha: 9, Bravo: 2}, {Alpha: 8, Bravo: 4}}},
+		opts:      []cmp.Option{FilterPathPattern("Items[*].Alpha", cmp.Ignore())},
+		wantEqual: true,
+		reason:    "equal because the Alpha field of every element is ignored",
+	}, {
+		label:     "FilterPathPattern",
+		x:         struct{ Items []Foo1 }{[]Foo1{{Alpha: 1, Bravo: 2}, {Alpha: 3, Bravo: 4}}},
+		y:         struct{ Items []Foo1 }{[]Foo1{{Alpha: 9, Bravo: 2}, {Alpha: 8, Bravo: 4}}},
+		opts:      []cmp.Option{FilterPathPattern(".Items[0].Alpha", cmp.Ignore())},
+		wantEqual: false,
+		reason:    "not equal because only the Alpha field of the first element is ignored",
+	}, {
+		label:     "FilterPathPattern",
+		x:         map[string]*Foo1{"a": {Alpha: 1}, "b[]": {Alpha: 2}},
+		y:         map[string]*Foo1{"a": {Alpha: 1}, "b[]": {Alpha: 3}},
+		opts:      []cmp.Option{FilterPathPattern(`{map[string]*cmpopts.Foo1}["b[]"].Alpha`, cmp.Ignore())},
+		wantEqual: true,
+		reason:    "equal because the Alpha field of the entry is ignored through the pointer",
+	}, {
+		label:     "FilterPathPattern",
+		x:         map[string]*Foo1{"a": {Alpha: 1}, "b[]": {Alpha: 2}},
+		y:         map[string]*Foo1{"a": {Alpha: 1}, "b[]": {Alpha: 3}},
+		opts:      []cmp.Option{FilterPathPattern(`{map[string]cmpopts.Foo1}["b[]"].Alpha`, cmp.Ignore())},
+		wantEqual: false,
+		reason:    "not equal because the root type does not match",
+	}, {
+		label:     "FilterPathPattern",
+		x:         map[int][]int{1: {1, 2, 3}, 2: {4}},
+		y:         map[int][]int{1: {3, 2, 1}, 2: {5}},
+		opts:      []cmp.Option{SortSlices(func(x, y int) bool { return x < y }), FilterPathPattern("root[2][*]", cmp.Ignore())},
+		wantEqual: true,
+		reason:    "equal because the elements of the sorted slice for key 2 are ignored",
+	}, {
+		label:     "FilterPathPattern",
+		x:         struct{ A, B int }{1, 2},
+		y:         struct{ A, B int }{3, 4},
+		opts:      []cmp.Option{FilterPathPattern(".*", cmp.Comparer(func(x, y int) bool { return true }))},
+		wantEqual: true,
+		reason:    "equal because every field is compared by the Comparer",
 	}, {
 		label:     "IgnoreInterfaces",
 		x:         struct{ mu sync.Mutex }{},
@@ -716,6 +984,54 @@ func TestOptions(t *testing.T) {
 		},
 		wantEqual: true,
 		reason:    "equal because all Ignore options can be composed together",
+	}, {
+		label:     "Subset",
+		x:         User{ID: "1"},
+		y:         User{ID: "1", DisplayName: "Alice", Address: Address{City: "Zurich"}},
+		opts:      []cmp.Option{Subset()},
+		wantEqual: true,
+		reason:    "equal because only the ID is set in the expected value",
+	}, {
+		label:     "Subset",
+		x:         User{ID: "1", Address: Address{City: "Paris"}},
+		y:         User{ID: "1", DisplayName: "Alice", Address: Address{City: "Zurich"}},
+		opts:      []cmp.Option{Subset()},
+		wantEqual: false,
+		reason:    "not equal because Address.City is set in the expected value and differs",
+	}, {
+		label:     "Subset",
+		x:         User{ID: "1"},
+		y:         User{DisplayName: "Alice"},
+		opts:      []cmp.Option{Subset()},
+		wantEqual: false,
+		reason:    "not equal because the ID is missing from the actual value",
+	}, {
+		label:     "Subset",
+		x:         map[string][]User{"a": {{ID: "1"}, {}}},
+		y:         map[string][]User{"a": {{ID: "1", DisplayName: "Alice"}, {ID: "2"}}, "b": nil},
+		opts:      []cmp.Option{Subset()},
+		wantEqual: true,
+		reason:    "equal because map entries only in the actual value and unset fields of slice elements are ignored",
+	}, {
+		label:     "Subset",
+		x:         []int{1, 2},
+		y:         []int{1, 2, 3},
+		opts:      []cmp.Option{Subset()},
+		wantEqual: false,
+		reason:    "not equal because slices are still compared element by element",
+	}, {
+		label:     "Subset",
+		x:         []int{},
+		y:         []int(nil),
+		opts:      []cmp.Option{Subset()},
+		wantEqual: false,
+		reason:    "not equal because an empty non-nil slice is compared",
+	}, {
+		label:     "Subset",
+		x:         User{DisplayName: "Alice"},
+		y:         User{ID: "1", DisplayName: "Alice"},
+		wantEqual: false,
+		reason:    "not equal because the ID differs without Subset",
 	}, {
 		label: "AcyclicTransformer",
 		x:     "a\nb\nc\nd",
@@ -737,12 +1053,556 @@ func TestOptions(t *testing.T) {
 	}, {
 		label: "AcyclicTransformer",
 		x:     "this is a sentence",
-		y: "this   			is a 			sentence",
+		y:     "this   			is a 			sentence",
 		opts: []cmp.Option{
 			AcyclicTransformer("", func(s string) []string { return strings.Fields(s) }),
 		},
 		wantEqual: true,
 		reason:    "equal because acyclic transformer splits on any contiguous whitespace",
+	}, {
+		label: "CachedTransformer",
+		x:     []string{"foo", "Bar", "BAZ", "foo"},
+		y:     []string{"Foo", "BAR", "baz", "FOO"},
+		opts: []cmp.Option{
+			CachedTransformer("", strings.ToUpper),
+		},
+		wantEqual: true,
+		reason:    "equal because of strings.ToUpper",
+	}, {
+		label: "CachedTransformer",
+		x:     [][]int{{1, 2}, {3}},
+		y:     [][]int{{2, 1}, {3}},
+		opts: []cmp.Option{
+			CachedTransformer("Sum", func(s []int) (n int) {
+				for _, v := range s {
+					n += v
+				}
+				return n
+			}),
+		},
+		wantEqual: true,
+		reason:    "equal because sums match; slices cannot be cached, but still are transformed",
+	}, {
+		label: "CachedTransformer",
+		x:     []float64{0, math.Copysign(0, -1)},
+		y:     []float64{0, 0},
+		opts: []cmp.Option{
+			CachedTransformer("Signbit", math.Signbit),
+		},
+		wantEqual: false,
+		reason:    "not equal because the sign of zero differs; floats are not cached since -0 and +0 are equal keys",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         []*big.Int{big.NewInt(1), big.NewInt(2)},
+		y:         []*big.Int{new(big.Int).SetInt64(1), new(big.Int).Add(big.NewInt(1), big.NewInt(1))},
+		opts:      []cmp.Option{ComparerFromCompare((*big.Int).Cmp)},
+		wantEqual: true,
+		reason:    "equal because (*big.Int).Cmp reports that the values are equal",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         []*big.Int{big.NewInt(1), big.NewInt(2)},
+		y:         []*big.Int{big.NewInt(1), big.NewInt(3)},
+		opts:      []cmp.Option{ComparerFromCompare((*big.Int).Cmp)},
+		wantEqual: false,
+		reason:    "not equal because 2 and 3 differ",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         []*big.Int{nil, big.NewInt(2)},
+		y:         []*big.Int{nil, big.NewInt(2)},
+		opts:      []cmp.Option{ComparerFromCompare((*big.Int).Cmp)},
+		wantEqual: true,
+		reason:    "equal because nil pointers are not passed to the compare function",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         []*big.Int{nil},
+		y:         []*big.Int{big.NewInt(0)},
+		opts:      []cmp.Option{ComparerFromCompare((*big.Int).Cmp)},
+		wantEqual: false,
+		reason:    "not equal because only one pointer is nil",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         map[string][]byte{"a": nil},
+		y:         map[string][]byte{"a": {}},
+		opts:      []cmp.Option{ComparerFromCompare(bytes.Compare)},
+		wantEqual: true,
+		reason:    "equal because bytes.Compare treats nil and empty slices as equal",
+	}, {
+		label:     "ComparerFromCompare",
+		x:         struct{ A, B string }{"a", "b"},
+		y:         struct{ A, B string }{"a", "B"},
+		opts:      []cmp.Option{ComparerFromCompare(func(x, y string) int { return strings.Compare(strings.ToLower(x), strings.ToLower(y)) })},
+		wantEqual: true,
+		reason:    "equal because the compare function is case-insensitive",
+	}, {
+		label:     "LessFromSortInterface",
+		x:         []string{"c", "a", "b"},
+		y:         []string{"a", "b", "c"},
+		opts:      []cmp.Option{SortSlices(LessFromSortInterface(sort.StringSlice(nil)))},
+		wantEqual: true,
+		reason:    "equal because sort.StringSlice orders the strings",
+	}, {
+		label:     "LessFromSortInterface",
+		x:         map[int][]string{0: {"ccc", "a", "bb"}},
+		y:         map[int][]string{0: {"a", "bb", "ccc"}},
+		opts:      []cmp.Option{SortSlices(LessFromSortInterface(new(byLength)))},
+		wantEqual: true,
+		reason:    "equal because byLength orders the strings through pointer receivers",
+	}, {
+		label:     "LessFromSortInterface",
+		x:         map[float64]bool{2: true, 1: false},
+		y:         map[float64]bool{1: false, 2: true},
+		opts:      []cmp.Option{SortMaps(LessFromSortInterface(sort.Float64Slice(nil)))},
+		wantEqual: true,
+		reason:    "equal because sort.Float64Slice orders the map keys",
+	}, {
+		label: "LessFromIndexFunc",
+		x:     []Foo1{{Alpha: 3}, {Alpha: 1}, {Alpha: 2}},
+		y:     []Foo1{{Alpha: 1}, {Alpha: 2}, {Alpha: 3}},
+		opts: []cmp.Option{SortSlices(func() interface{} {
+			var s []Foo1
+			return LessFromIndexFunc(&s, func(i, j int) bool { return s[i].Alpha < s[j].Alpha })
+		}())},
+		wantEqual: true,
+		reason:    "equal because the index function orders the structs",
+	}, {
+		label: "LessFromIndexFunc",
+		x:     []Foo1{{Alpha: 3}, {Alpha: 1}, {Alpha: 2}},
+		y:     []Foo1{{Alpha: 1}, {Alpha: 2}, {Alpha: 4}},
+		opts: []cmp.Option{SortSlices(func() interface{} {
+			var s []Foo1
+			return LessFromIndexFunc(&s, func(i, j int) bool { return s[i].Alpha < s[j].Alpha })
+		}())},
+		wantEqual: false,
+		reason:    "not equal because 3 and 4 differ",
+	}, {
+		label:     "TransformJSON",
+		x:         `{"a": 1, "b": [true, null]}`,
+		y:         `{"b":[true,null],"a":1}`,
+		opts:      []cmp.Option{TransformJSON()},
+		wantEqual: true,
+		reason:    "equal because member order and whitespace do not matter",
+	}, {
+		label:     "TransformJSON",
+		x:         `{"a": 1, "b": [true, null]}`,
+		y:         `{"b":[true,null],"a":1}`,
+		wantEqual: false,
+		reason:    "not equal because the strings differ",
+	}, {
+		label:     "TransformJSON",
+		x:         []byte(`{"a": 1}`),
+		y:         []byte(`{"a": 1.0}`),
+		opts:      []cmp.Option{TransformJSON()},
+		wantEqual: false,
+		reason:    "not equal because numbers are compared by their text",
+	}, {
+		label:     "TransformJSON",
+		x:         struct{ M json.RawMessage }{json.RawMessage(`[1, 2]`)},
+		y:         struct{ M json.RawMessage }{json.RawMessage(` [1,2] `)},
+		opts:      []cmp.Option{TransformJSON()},
+		wantEqual: true,
+		reason:    "equal because json.RawMessage is decoded",
+	}, {
+		label:     "TransformJSON",
+		x:         `{"a": 1}`,
+		y:         `{"a": 1`,
+		opts:      []cmp.Option{TransformJSON()},
+		wantEqual: false,
+		reason:    "not equal because invalid JSON is compared as a string",
+	}, {
+		label:     "TransformJSON",
+		x:         `{"a": 1} {}`,
+		y:         `{"a":1} {}`,
+		opts:      []cmp.Option{TransformJSON()},
+		wantEqual: false,
+		reason:    "not equal because multiple documents are compared as a string",
+	}, {
+		label:     "EquateProtoWellKnownTypes",
+		x:         &pb.Timestamp{Seconds: 1500000000, Nanos: 5},
+		y:         time.Unix(1500000000, 5).In(time.FixedZone("UTC+1", 3600)),
+		opts:      []cmp.Option{EquateProtoWellKnownTypes()},
+		wantEqual: true,
+		reason:    "equal because the Timestamp represents the same instant",
+	}, {
+		label:     "EquateProtoWellKnownTypes",
+		x:         &pb.Timestamp{Seconds: 1500000000, Nanos: 5},
+		y:         time.Unix(1500000000, 6),
+		opts:      []cmp.Option{EquateProtoWellKnownTypes()},
+		wantEqual: false,
+		reason:    "not equal because the instants differ by a nanosecond",
+	}, {
+		label:     "EquateProtoWellKnownTypes",
+		x:         &pb.Timestamp{Seconds: 1500000000},
+		y:         time.Unix(1500000000, 0),
+		wantEqual: false,
+		reason:    "not equal because the types differ without the option",
+	}, {
+		label: "EquateProtoWellKnownTypes",
+		x: map[string]interface{}{
+			"duration": &pb.Duration{Seconds: 90, Nanos: 1e8},
+			"name":     &pb.StringValue{Value: "gopher"},
+			"count":    pb.Int64Value{Value: 5},
+			"enabled":  (*pb.BoolValue)(nil),
+			"data":     &pb.BytesValue{Value: []byte("abc")},
+		},
+		y: map[string]interface{}{
+			"duration": 90*time.Second + 100*time.Millisecond,
+			"name":     "gopher",
+			"count":    int64(5),
+			"enabled":  nil,
+			"data":     []byte("abc"),
+		},
+		opts:      []cmp.Option{EquateProtoWellKnownTypes()},
+		wantEqual: true,
+		reason:    "equal because every well-known type is converted to its native equivalent",
+	}, {
+		label: "EquateProtoWellKnownTypes",
+		x: []*pb.Timestamp{
+			{Seconds: 1}, {Seconds: 2},
+		},
+		y: []*pb.Timestamp{
+			{Seconds: 1}, {Seconds: 3},
+		},
+		opts:      []cmp.Option{EquateProtoWellKnownTypes()},
+		wantEqual: false,
+		reason:    "not equal because the second timestamps differ",
+	}, {
+		label:     "EquateProtoWellKnownTypes",
+		x:         &pb.Int64Value{Value: 5},
+		y:         int32(5),
+		opts:      []cmp.Option{EquateProtoWellKnownTypes()},
+		wantEqual: false,
+		reason:    "not equal because Int64Value only equals an int64",
+	}, {
+		label:     "EquateUUIDs",
+		x:         []string{"f47ac10b-58cc-4372-a567-0e02b2c3d479", "hello"},
+		y:         []string{"F47AC10B-58CC-4372-A567-0E02B2C3D479", "hello"},
+		opts:      []cmp.Option{EquateUUIDs()},
+		wantEqual: true,
+		reason:    "equal because UUID strings are compared case-insensitively",
+	}, {
+		label:     "EquateUUIDs",
+		x:         []string{"hello"},
+		y:         []string{"HELLO"},
+		opts:      []cmp.Option{EquateUUIDs()},
+		wantEqual: false,
+		reason:    "not equal because strings that are not UUIDs are compared as usual",
+	}, {
+		label: "EquateUUIDs",
+		x: map[string]interface{}{
+			"array": [16]byte{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79},
+			"named": myUUID{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79},
+			"urn":   "urn:uuid:f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		},
+		y: map[string]interface{}{
+			"array": "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+			"named": "{F47AC10B-58CC-4372-A567-0E02B2C3D479}",
+			"urn":   &myUUID{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79},
+		},
+		opts:      []cmp.Option{EquateUUIDs()},
+		wantEqual: true,
+		reason:    "equal because all representations hold the same UUID",
+	}, {
+		label:     "EquateUUIDs",
+		x:         interface{}(myUUID{0xf4}),
+		y:         interface{}("f47ac10b-58cc-4372-a567-0e02b2c3d478"),
+		opts:      []cmp.Option{EquateUUIDs()},
+		wantEqual: false,
+		reason:    "not equal because the UUIDs differ",
+	}, {
+		label:     "EquateRegexps",
+		x:         struct{ R *regexp.Regexp }{regexp.MustCompile("a+b")},
+		y:         struct{ R *regexp.Regexp }{regexp.MustCompile("a+b")},
+		wantPanic: true,
+		reason:    "panics because *regexp.Regexp has unexported fields",
+	}, {
+		label:     "EquateRegexps",
+		x:         struct{ R *regexp.Regexp }{regexp.MustCompile("a+b")},
+		y:         struct{ R *regexp.Regexp }{regexp.MustCompile("a+b")},
+		opts:      []cmp.Option{EquateRegexps()},
+		wantEqual: true,
+		reason:    "equal because the patterns are identical",
+	}, {
+		label:     "EquateRegexps",
+		x:         []*regexp.Regexp{regexp.MustCompile("a+b"), nil},
+		y:         []*regexp.Regexp{regexp.MustCompilePOSIX("a+b"), nil},
+		opts:      []cmp.Option{EquateRegexps()},
+		wantEqual: true,
+		reason:    "equal because the patterns are identical and leftmost-longest matching is ignored",
+	}, {
+		label:     "EquateRegexps",
+		x:         []*regexp.Regexp{regexp.MustCompile("a+b")},
+		y:         []*regexp.Regexp{regexp.MustCompile("(?i)a+b")},
+		opts:      []cmp.Option{EquateRegexps()},
+		wantEqual: false,
+		reason:    "not equal because the inline flags differ",
+	}, {
+		label:     "EquateRegexps",
+		x:         []*regexp.Regexp{regexp.MustCompile("a+b")},
+		y:         []*regexp.Regexp{nil},
+		opts:      []cmp.Option{EquateRegexps()},
+		wantEqual: false,
+		reason:    "not equal because only one regular expression is nil",
+	}, {
+		label:     "EquateReflectTypes",
+		x:         struct{ T reflect.Type }{reflect.TypeOf(0)},
+		y:         struct{ T reflect.Type }{reflect.TypeOf(0)},
+		wantPanic: true,
+		reason:    "panics because the underlying type descriptor has unexported fields",
+	}, {
+		label:     "EquateReflectTypes",
+		x:         []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(""), nil},
+		y:         []reflect.Type{reflect.TypeOf(1), reflect.TypeOf("x"), nil},
+		opts:      []cmp.Option{EquateReflectTypes()},
+		wantEqual: true,
+		reason:    "equal because the types are identical",
+	}, {
+		label:     "EquateReflectTypes",
+		x:         []reflect.Type{reflect.TypeOf(0)},
+		y:         []reflect.Type{reflect.TypeOf(MyInt(0))},
+		opts:      []cmp.Option{EquateReflectTypes()},
+		wantEqual: false,
+		reason:    "not equal because int and MyInt are distinct types",
+	}, {
+		label:     "EquateReflectValues",
+		x:         []reflect.Value{reflect.ValueOf(5), reflect.ValueOf([]int{1, 2}), {}},
+		y:         []reflect.Value{reflect.ValueOf(5), reflect.ValueOf([]int{1, 2}), {}},
+		opts:      []cmp.Option{EquateReflectValues()},
+		wantEqual: true,
+		reason:    "equal because the held values are equal",
+	}, {
+		label:     "EquateReflectValues",
+		x:         []reflect.Value{reflect.ValueOf(5)},
+		y:         []reflect.Value{reflect.ValueOf(int64(5))},
+		opts:      []cmp.Option{EquateReflectValues()},
+		wantEqual: false,
+		reason:    "not equal because the held values have different types",
+	}, {
+		label:     "EquateReflectValues",
+		x:         []reflect.Value{reflect.ValueOf([]string{"a", "b"})},
+		y:         []reflect.Value{reflect.ValueOf([]string{"b", "a"})},
+		opts:      []cmp.Option{EquateReflectValues(), SortSlices(func(x, y string) bool { return x < y })},
+		wantEqual: true,
+		reason:    "equal because other options still apply to the held values",
+	}, {
+		label:     "EquateReflectValues",
+		x:         []reflect.Value{reflect.ValueOf(privateStruct{}).Field(1)},
+		y:         []reflect.Value{reflect.ValueOf(privateStruct{}).Field(1)},
+		opts:      []cmp.Option{EquateReflectValues()},
+		wantPanic: true,
+		reason:    "panics because the value was derived from an unexported field",
+	}, {
+		label:     "EquateChannels",
+		x:         []interface{}{(<-chan int)(sharedChan), (chan int)(nil)},
+		y:         []interface{}{(chan<- int)(sharedChan), (<-chan int)(nil)},
+		wantEqual: false,
+		reason:    "not equal because channels of different directions have different types",
+	}, {
+		label:     "EquateChannels",
+		x:         []interface{}{(<-chan int)(sharedChan)},
+		y:         []interface{}{(<-chan int)(make(chan int))},
+		opts:      []cmp.Option{EquateChannels()},
+		wantEqual: false,
+		reason:    "not equal because the channels are different channels",
+	}, {
+		label:     "EquateChannels",
+		x:         []interface{}{(<-chan int)(sharedChan), (chan int)(nil)},
+		y:         []interface{}{(chan<- int)(sharedChan), (<-chan int)(nil)},
+		opts:      []cmp.Option{EquateChannels()},
+		wantEqual: true,
+		reason:    "equal because the channels are the same channel or both nil",
+	}, {
+		label:     "EquateChannels",
+		x:         []interface{}{(<-chan int)(sharedChan)},
+		y:         []interface{}{(<-chan int64)(nil)},
+		opts:      []cmp.Option{EquateChannels()},
+		wantEqual: false,
+		reason:    "not equal because the element types differ",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann"},
+		y:         User{ID: "1", DisplayName: "Ann"},
+		wantEqual: false,
+		reason:    "not equal because the types differ",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}},
+		y:         User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: false,
+		reason:    "not equal because the Go field names differ",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}, Secret: "x"},
+		y:         &User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"))},
+		wantEqual: true,
+		reason:    "equal because the fields match by their json names, recursively",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}},
+		y:         User{ID: "1", DisplayName: "Ann", Address: Address{"Rome"}},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"))},
+		wantEqual: false,
+		reason:    "not equal because the address fields differ",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         map[string]interface{}{"id": "1", "display_name": "Ann", "Address": Address{"Oslo"}, "Revision": 2},
+		y:         AuditedUser{User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}}, 2},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"))},
+		wantEqual: false,
+		reason:    "not equal because only structs are transformed",
+	}, {
+		label: "EquateAcrossTypes",
+		x: struct {
+			ID, DisplayName string
+			Address         Address
+			Revision        int
+		}{"1", "Ann", Address{"Oslo"}, 2},
+		y:         AuditedUser{User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}}, 2},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: true,
+		reason:    "equal because the fields of embedded structs are promoted",
+	}, {
+		label: "EquateAcrossTypes",
+		x: struct {
+			City string
+			Address
+		}{"Oslo", Address{"Oslo"}},
+		y:         AddressDTO{"Oslo"},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: true,
+		reason:    "equal because the field City shadows the promoted field, as in Go",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         Shadowing{Base{1, "Ann"}, 2},
+		y:         Base{2, "Ann"},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: true,
+		reason:    "equal because Shadowing.ID shadows the promoted Base.ID",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         Ambiguous{Base{1, "Ann"}, Labeled{"Bob"}},
+		y:         struct{ ID int }{1},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: true,
+		reason:    "equal because the promoted Name fields are ambiguous at the same depth and are dropped, as in Go",
+	}, {
+		label: "EquateAcrossTypes",
+		x: struct {
+			Base
+			Label string `json:"Name"`
+		}{Base{1, "Ann"}, "Bob"},
+		y:         Base{1, "Bob"},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"))},
+		wantEqual: true,
+		reason:    "equal because the tagged field shadows the promoted untagged field",
+	}, {
+		label: "EquateAcrossTypes",
+		x: struct {
+			A string `db:"name"`
+			B string `db:"name"`
+		}{"Ann", "Ann"},
+		y:         struct{ Name string }{"Ann"},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("db"))},
+		wantPanic: true,
+		reason:    "panics because two fields at the same depth are tagged with the same name",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         struct{ Id, UserName string }{"1", "Ann"},
+		y:         struct{ ID, Username string }{"1", "Ann"},
+		opts:      []cmp.Option{EquateAcrossTypes()},
+		wantEqual: false,
+		reason:    "not equal because the field names differ in case",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         struct{ Id, UserName string }{"1", "Ann"},
+		y:         struct{ ID, Username string }{"1", "Ann"},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldsFold())},
+		wantEqual: true,
+		reason:    "equal because the field names match case-insensitively",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}},
+		y:         struct{ Id, Display_Name, ADDRESS interface{} }{"1", "Ann", struct{ CITY string }{"Oslo"}},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"), MatchFieldsFold())},
+		wantEqual: true,
+		reason:    "equal because the field names match by json name case-insensitively",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         struct{ ID, Id string }{"1", "1"},
+		y:         struct{ ID string }{"1"},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldsFold())},
+		wantEqual: false,
+		reason:    "not equal because untagged fields whose names conflict case-insensitively are ambiguous and dropped",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}, Secret: "x"},
+		y:         AuditedUser{User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}}, 2},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"))},
+		wantEqual: false,
+		reason:    "not equal because the Revision field is only present in one struct",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Oslo"}, Secret: "x"},
+		y:         AuditedUser{User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}}, 2},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"), IgnoreMissingFields())},
+		wantEqual: true,
+		reason:    "equal because fields present in only one struct are ignored",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         UserDTO{ID: "1", Name: "Ann", Address: &AddressDTO{"Rome"}},
+		y:         AuditedUser{User{ID: "1", DisplayName: "Ann", Address: Address{"Oslo"}}, 2},
+		opts:      []cmp.Option{EquateAcrossTypes(MatchFieldTag("json"), IgnoreMissingFields())},
+		wantEqual: false,
+		reason:    "not equal because the common address fields differ",
+	}, {
+		label:     "EquateAcrossTypes",
+		x:         map[string]interface{}{"Revision": 1},
+		y:         map[string]interface{}{},
+		opts:      []cmp.Option{EquateAcrossTypes(IgnoreMissingFields())},
+		wantEqual: false,
+		reason:    "not equal because only the entries of transformed structs are ignored",
+	}, {
+		label:     "EquateBinaryMarshaled",
+		x:         []fraction{{1, 2}, {3, 9}},
+		y:         []fraction{{2, 4}, {1, 3}},
+		wantEqual: false,
+		reason:    "not equal because the fields differ",
+	}, {
+		label:     "EquateBinaryMarshaled",
+		x:         []fraction{{1, 2}, {3, 9}},
+		y:         []fraction{{2, 4}, {1, 3}},
+		opts:      []cmp.Option{EquateBinaryMarshaled()},
+		wantEqual: true,
+		reason:    "equal because the fractions marshal to the same lowest terms",
+	}, {
+		label:     "EquateBinaryMarshaled",
+		x:         []fraction{{1, 2}},
+		y:         []fraction{{1, 3}},
+		opts:      []cmp.Option{EquateBinaryMarshaled()},
+		wantEqual: false,
+		reason:    "not equal because the fractions differ in lowest terms",
+	}, {
+		label:     "EquateBinaryMarshaled",
+		x:         []*badMarshaler{nil},
+		y:         []*badMarshaler{nil},
+		opts:      []cmp.Option{EquateBinaryMarshaled()},
+		wantEqual: true,
+		reason:    "equal because nil pointers are not marshaled",
+	}, {
+		label:     "EquateBinaryMarshaled",
+		x:         &badMarshaler{},
+		y:         &badMarshaler{},
+		opts:      []cmp.Option{EquateBinaryMarshaled()},
+		wantPanic: true,
+		reason:    "panics because MarshalBinary reports an error",
+	}, {
+		label:     "TransformBinaryMarshaled",
+		x:         map[string]fraction{"a": {1, 2}},
+		y:         map[string]fraction{"a": {50, 100}},
+		opts:      []cmp.Option{TransformBinaryMarshaled()},
+		wantEqual: true,
+		reason:    "equal because the fractions marshal to the same lowest terms",
 	}}
 
 	for _, tt := range tests {
@@ -784,6 +1644,12 @@ func TestPanic(t *testing.T) {
 		fnc:    EquateApprox,
 		args:   args(0.0, 0.0),
 		reason: "zero margin and fraction is equivalent to exact equality",
+	}, {
+		label:     "EquateApproxTime",
+		fnc:       EquateApproxTime,
+		args:      args(time.Duration(-1)),
+		wantPanic: "margin must be a non-negative number",
+		reason:    "negative duration margin is invalid",
 	}, {
 		label:     "EquateApprox",
 		fnc:       EquateApprox,
@@ -824,6 +1690,53 @@ func TestPanic(t *testing.T) {
 		args:      args((func(_, _ int) bool)(nil)),
 		wantPanic: "invalid less function",
 		reason:    "nil value is not valid",
+	}, {
+		label:     "IgnoreSliceElements",
+		fnc:       IgnoreSliceElements,
+		args:      args(func(_, _ int) bool { return true }),
+		wantPanic: "invalid discard function",
+		reason:    "func(x, y int) bool is wrong signature for discard",
+	}, {
+		label:     "IgnoreMapEntries",
+		fnc:       IgnoreMapEntries,
+		args:      args(func(int) bool { return true }),
+		wantPanic: "invalid discard function",
+		reason:    "func(int) bool is wrong signature for discard",
+	}, {
+		label:     "IgnoreMapEntries",
+		fnc:       IgnoreMapEntries,
+		args:      args((func(_ string, _ int) bool)(nil)),
+		wantPanic: "invalid discard function",
+		reason:    "nil value is not valid",
+	}, {
+		label:  "FilterPathPattern",
+		fnc:    FilterPathPattern,
+		args:   args(`{[]string}["a"][0].B.*[*]`, cmp.Ignore()),
+		reason: "valid pattern",
+	}, {
+		label:     "FilterPathPattern",
+		fnc:       FilterPathPattern,
+		args:      args("A..B", cmp.Ignore()),
+		wantPanic: `invalid path pattern "A..B": invalid field name ""`,
+		reason:    "field names must not be empty",
+	}, {
+		label:     "FilterPathPattern",
+		fnc:       FilterPathPattern,
+		args:      args("A[0", cmp.Ignore()),
+		wantPanic: "unterminated index",
+		reason:    "indexes must be closed",
+	}, {
+		label:     "FilterPathPattern",
+		fnc:       FilterPathPattern,
+		args:      args("{int", cmp.Ignore()),
+		wantPanic: "unterminated root type",
+		reason:    "the root type must be closed",
+	}, {
+		label:     "FilterPathPattern",
+		fnc:       FilterPathPattern,
+		args:      args("A[]", cmp.Ignore()),
+		wantPanic: "empty index",
+		reason:    "indexes must not be empty",
 	}, {
 		label:     "SortMaps",
 		fnc:       SortMaps,
@@ -971,6 +1884,81 @@ func TestPanic(t *testing.T) {
 		args:      args("", "not a func"),
 		wantPanic: "invalid transformer function",
 		reason:    "AcyclicTransformer has same input requirements as Transformer",
+	}, {
+		label:     "CachedTransformer",
+		fnc:       CachedTransformer,
+		args:      args("", "not a func"),
+		wantPanic: "invalid transformer function",
+		reason:    "CachedTransformer has same input requirements as Transformer",
+	}, {
+		label:     "CachedTransformer",
+		fnc:       CachedTransformer,
+		args:      args("invalid name", strings.ToUpper),
+		wantPanic: "invalid name",
+		reason:    "CachedTransformer has same name requirements as Transformer",
+	}, {
+		label:  "ComparerFromCompare",
+		fnc:    ComparerFromCompare,
+		args:   args(strings.Compare),
+		reason: "strings.Compare is a valid compare function",
+	}, {
+		label:     "ComparerFromCompare",
+		fnc:       ComparerFromCompare,
+		args:      args(func(x, y int) bool { return x < y }),
+		wantPanic: "invalid compare function",
+		reason:    "compare function must return an int",
+	}, {
+		label:     "ComparerFromCompare",
+		fnc:       ComparerFromCompare,
+		args:      args(func(x string, y []byte) int { return 0 }),
+		wantPanic: "invalid compare function",
+		reason:    "compare function must have inputs of the same type",
+	}, {
+		label:     "ComparerFromCompare",
+		fnc:       ComparerFromCompare,
+		args:      args((func(x, y int) int)(nil)),
+		wantPanic: "invalid compare function",
+		reason:    "compare function must not be nil",
+	}, {
+		label:  "LessFromSortInterface",
+		fnc:    LessFromSortInterface,
+		args:   args(sort.IntSlice(nil)),
+		reason: "sort.IntSlice is a slice type",
+	}, {
+		label:     "LessFromSortInterface",
+		fnc:       LessFromSortInterface,
+		args:      args(nil),
+		wantPanic: "invalid sort.Interface type",
+		reason:    "sort.Interface must not be nil",
+	}, {
+		label:     "LessFromSortInterface",
+		fnc:       LessFromSortInterface,
+		args:      args(sort.Reverse(sort.IntSlice(nil))),
+		wantPanic: "invalid sort.Interface type",
+		reason:    "sort.Interface must be a slice type to construct values from",
+	}, {
+		label:  "LessFromIndexFunc",
+		fnc:    LessFromIndexFunc,
+		args:   args(new([]int), func(i, j int) bool { return i < j }),
+		reason: "pointer to slice is valid",
+	}, {
+		label:     "LessFromIndexFunc",
+		fnc:       LessFromIndexFunc,
+		args:      args([]int(nil), func(i, j int) bool { return i < j }),
+		wantPanic: "invalid slice pointer",
+		reason:    "slice must be passed by pointer",
+	}, {
+		label:     "LessFromIndexFunc",
+		fnc:       LessFromIndexFunc,
+		args:      args(new([]int), nil),
+		wantPanic: "invalid nil less function",
+		reason:    "less function must not be nil",
+	}, {
+		label:     "MatchFieldTag",
+		fnc:       MatchFieldTag,
+		args:      args(""),
+		wantPanic: "invalid empty struct tag key",
+		reason:    "struct tag key must not be empty",
 	}}
 
 	for _, tt := range tests {
@@ -1017,3 +2005,101 @@ func TestPanic(t *testing.T) {
 		})
 	}
 }
+
+func TestEquateAcrossTypesMissingFields(t *testing.T) {
+	type UserV1 struct {
+		ID   string
+		Name string
+	}
+	type UserV2 struct {
+		ID       string
+		Revision int
+	}
+	x, y := UserV1{"1", "Ann"}, UserV2{"1", 2}
+
+	// Fields present in only one struct are reported as added or removed.
+	got := cmp.Diff(x, y, EquateAcrossTypes())
+	want := `cmpopts.EquateAcrossTypes(root)["Name"] (entry removed):
+	-: "Ann"
+cmpopts.EquateAcrossTypes(root)["Revision"] (entry added):
+	+: 2
+`
+	if got != want {
+		t.Errorf("Diff:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	edits := func(opts ...CrossTypeOption) (ss []string) {
+		for _, d := range cmp.DiffResult(x, y, EquateAcrossTypes(opts...)) {
+			key := d.Path.Last().(cmp.MapIndex).Key()
+			ss = append(ss, fmt.Sprintf("%v %v ignored=%v", key, d.Edit(), d.Ignored))
+		}
+		return ss
+	}
+	gotEdits, wantEdits := edits(), []string{"Name removed ignored=false", "Revision inserted ignored=false"}
+	if !reflect.DeepEqual(gotEdits, wantEdits) {
+		t.Errorf("DiffResult = %q, want %q", gotEdits, wantEdits)
+	}
+
+	// With IgnoreMissingFields, the values are equal, while the added and
+	// removed fields are still listed as ignored differences.
+	if !cmp.Equal(x, y, EquateAcrossTypes(IgnoreMissingFields())) {
+		t.Errorf("Equal with IgnoreMissingFields = false, want true")
+	}
+	gotEdits, wantEdits = edits(IgnoreMissingFields()), []string{"Name removed ignored=true", "Revision inserted ignored=true"}
+	if !reflect.DeepEqual(gotEdits, wantEdits) {
+		t.Errorf("DiffResult with IgnoreMissingFields = %q, want %q", gotEdits, wantEdits)
+	}
+}
+
+func TestCachedTransformer(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	type upperString string // Distinct output type, so outputs are not transformed again
+	opt := CachedTransformer("Upper", func(s string) upperString {
+		mu.Lock()
+		calls[s]++
+		mu.Unlock()
+		return upperString(strings.ToUpper(s))
+	})
+
+	x := strings.Split(strings.Repeat("a", 100), "")
+	y := strings.Split(strings.Repeat("A", 100), "")
+	for i := 1; i <= 3; i++ {
+		if !cmp.Equal(x, y, opt) {
+			t.Fatalf("Equal = false, want true")
+		}
+		// Results are only memoized within each comparison, where f may also
+		// be called a second time to check that it is deterministic.
+		for _, s := range []string{"a", "A"} {
+			if n := calls[s]; n < i || n > 2*i {
+				t.Errorf("after %d comparisons, transformer calls for %q = %d, want within [%d, %d]", i, s, n, i, 2*i)
+			}
+		}
+	}
+
+	// Mutating the value that a pointer input points to between comparisons
+	// does not reuse a stale result.
+	type T struct{ S string }
+	upper := CachedTransformer("Upper", func(p *T) string { return strings.ToUpper(p.S) })
+	px, py := &T{"a"}, &T{"A"}
+	if !cmp.Equal(px, py, upper) {
+		t.Errorf("Equal = false, want true")
+	}
+	py.S = "b"
+	if cmp.Equal(px, py, upper) {
+		t.Errorf("Equal after mutation = true, want false")
+	}
+}
+
+func TestSubsetAudit(t *testing.T) {
+	var ignored []string
+	x := User{ID: "1", Address: Address{City: "Zurich"}}
+	y := User{ID: "1", DisplayName: "Alice", Address: Address{City: "Zurich"}}
+	if !cmp.Equal(x, y, Subset(), cmp.AuditIgnored(func(p cmp.Path, _ cmp.Option) {
+		ignored = append(ignored, p.String())
+	})) {
+		t.Fatalf("Equal = false, want true")
+	}
+	if want := []string{"DisplayName"}; !reflect.DeepEqual(ignored, want) {
+		t.Errorf("ignored paths = %v, want %v", ignored, want)
+	}
+}
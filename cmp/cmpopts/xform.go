@@ -33,3 +33,20 @@ func AcyclicTransformer(name string, f interface{}) cmp.Option {
 	xf := xformFilter{cmp.Transformer(name, f)}
 	return cmp.FilterPath(xf.filter, xf.xform)
 }
+
+// CachedTransformer returns a Transformer that memoizes the results of f.
+// It is intended for transformers that are expensive to compute, where the
+// same sub-values are transformed repeatedly (e.g., a canonicalization
+// function applied to the nodes of a large graph).
+//
+// The transformer f must be a function "func(T) R" as required by
+// cmp.Transformer. Results are memoized for the duration of a single
+// comparison by the cache that Equal keeps for the outputs of every
+// Transformer (see cmp.NoTransformerCache), such that pointer inputs are
+// identified by their address and values reachable from them may be mutated
+// between comparisons. Input values that do not identify themselves as
+// map keys (e.g., floating-point numbers) are never cached.
+// The cmp.NoTransformerCache option disables the memoization.
+func CachedTransformer(name string, f interface{}) cmp.Option {
+	return cmp.Transformer(name, f)
+}
@@ -27,9 +27,12 @@
 package cmp
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp/internal/diff"
 	"github.com/google/go-cmp/cmp/internal/function"
@@ -47,13 +50,22 @@ import (
 // If S contains a single Transformer, then use that to transform the current
 // values and recursively call Equal on the output values.
 // If S contains a single Comparer, then use that to compare the current values.
-// Otherwise, evaluation proceeds to the next rule.
+// If S is empty, then S is instead derived from the options registered
+// using RegisterOptions and SetDefaultOptions. Otherwise, evaluation proceeds to the next rule.
 //
 // • If the values have an Equal method of the form "(T) Equal(T) bool" or
 // "(T) Equal(I) bool" where T is assignable to I, then use the result of
 // x.Equal(y) even if x or y is nil. Otherwise, no such method exists and
 // evaluation proceeds to the next rule.
 //
+// • If a surrogate has been registered for the type of the values using
+// RegisterSurrogate, then transform the values and recursively call Equal
+// on the output values. Otherwise, evaluation proceeds to the next rule.
+//
+// • If the MaxDepth option limits the depth of the comparison and the values
+// are below that depth, then compare them without descending into them
+// as documented by MaxDepth. Otherwise, evaluation proceeds to the next rule.
+//
 // • Lastly, try to compare x and y based on their basic kinds.
 // Simple kinds like booleans, integers, floats, complex numbers, strings, and
 // channels are compared using the equivalent of the == operator in Go.
@@ -79,7 +91,75 @@ import (
 // Pointers and interfaces are equal if they are both nil or both non-nil,
 // where they have the same underlying concrete type and recursively
 // calling Equal on the underlying values reports equal.
+//
+// Before recursing into a pointer, slice element, or map, the current path
+// is checked to detect whether the address has already been visited.
+// If there is a cycle, then the pointed at values are considered equal
+// only if both addresses were previously visited in the same path step.
+//
+// Values of different types, including x and y themselves, are never equal.
+// This results in them being reported as unequal rather than a panic,
+// such that Diff notes the mismatched types.
+//
+// Equal stops the comparison at the first difference found, since the rest
+// of the values cannot change the result, unless an option that observes the
+// entire comparison is used (e.g., Reporter, Strict, or RecordStats).
 func Equal(x, y interface{}, opts ...Option) bool {
+	s := newState(opts)
+	s.stopAtFirstDiff()
+	s.compareRoot(x, y)
+	return s.result.Equal()
+}
+
+// Diff returns a human-readable report of the differences between two values.
+// It returns an empty string if and only if Equal returns true for the same
+// input values and options. The output string will use the "-" symbol to
+// indicate elements removed from x, and the "+" symbol to indicate elements
+// added to y.
+//
+// Do not depend on this output being stable.
+func Diff(x, y interface{}, opts ...Option) string {
+	// The equality result and the report are produced by the same traversal,
+	// so there is no need for a separate call to Equal.
+	s := newState(opts)
+	r := s.newReporter()
+	s.stopAfterReport(r)
+	s.reporters = append(s.reporters, reporterOption{r})
+	s.compareRoot(x, y)
+	r.stopped = s.stopped
+	d := r.String()
+	if (d == "") != s.result.Equal() {
+		panic("inconsistent difference and equality results")
+	}
+	return d
+}
+
+// compareRoot compares x and y as the root of the value tree, and performs
+// all of the checks and captures that follow a complete comparison.
+// It returns the path step for the root values.
+func (s *state) compareRoot(x, y interface{}) PathStep {
+	var start time.Time
+	if len(s.stats) > 0 {
+		start = time.Now()
+	}
+	step := rootStep(x, y)
+	if !s.compareContext(step) {
+		return step // The comparison is incomplete
+	}
+	s.checkUnused()
+	s.capture(x, y)
+	if len(s.stats) > 0 {
+		s.work.Calls = 1
+		s.work.Duration = time.Since(start)
+		for _, st := range s.stats {
+			st.add(s.work)
+		}
+	}
+	return step
+}
+
+// rootStep constructs the first path step for comparing x and y.
+func rootStep(x, y interface{}) PathStep {
 	vx := reflect.ValueOf(x)
 	vy := reflect.ValueOf(y)
 
@@ -101,28 +181,7 @@ func Equal(x, y interface{}, opts ...Option) bool {
 	} else {
 		t = vx.Type()
 	}
-
-	s := newState(opts)
-	s.compareAny(&pathStep{t, vx, vy})
-	return s.result.Equal()
-}
-
-// Diff returns a human-readable report of the differences between two values.
-// It returns an empty string if and only if Equal returns true for the same
-// input values and options. The output string will use the "-" symbol to
-// indicate elements removed from x, and the "+" symbol to indicate elements
-// added to y.
-//
-// Do not depend on this output being stable.
-func Diff(x, y interface{}, opts ...Option) string {
-	r := new(defaultReporter)
-	opts = Options{Options(opts), reporter(r)}
-	eq := Equal(x, y, opts...)
-	d := r.String()
-	if (d == "") != eq {
-		panic("inconsistent difference and equality results")
-	}
-	return d
+	return &pathStep{t, vx, vy}
 }
 
 type state struct {
@@ -132,6 +191,9 @@ type state struct {
 	curPath   Path             // The current path in the value tree
 	reporters []reporterOption // Optional reporters
 
+	// curPtrs tracks the pointers on the current path for cycle detection.
+	curPtrs pointerPath
+
 	// recChecker checks for infinite cycles applying the same set of
 	// transformers upon the output of itself.
 	recChecker recChecker
@@ -140,20 +202,113 @@ type state struct {
 	// It is safe for statelessCompare to mutate this value.
 	dynChecker dynChecker
 
+	// used records whether each option in opts was applied.
+	// It is nil unless the Strict option is in use.
+	used []bool
+
+	// work counts the work performed for the Stats option.
+	// It is safe for statelessCompare to mutate this value.
+	work Stats
+
+	// ctxErr records why the comparison was stopped early.
+	// It is nil unless the context passed to EqualContext or DiffContext
+	// is done before the comparison completes.
+	ctxErr error
+
+	// stopAtDiff reports whether the traversal is unwound by checkStop
+	// once a difference is found. It is only set if no option observes the
+	// entire comparison.
+	stopAtDiff bool
+
+	// maxDiffs is the number of differences after which the traversal
+	// is unwound by checkStop, but only outside of recordCompare given that
+	// the edit-script for slices relies on complete results. It is only set
+	// if the report by Diff is limited by MaxDiffs and no other option
+	// observes the entire comparison; otherwise it is zero.
+	maxDiffs int
+
+	// stopped reports whether the traversal was unwound by checkStop.
+	stopped bool
+
+	// transformed caches the outputs of transformers by their inputs.
+	// It is nil unless a transformer was applied.
+	transformed map[transformKey]reflect.Value
+
+	// ignoredBy is the option that ignored the current node.
+	// It is only valid while reporting an ignored node.
+	ignoredBy Option
+
+	// captureDiff records the report for captures.
+	// It is nil unless a CaptureFailures option is in use.
+	captureDiff *defaultReporter
+
 	// These fields, once set by processOption, will not change.
-	exporters map[reflect.Type]bool // Set of structs with unexported field visibility
-	opts      Options               // List of all fundamental and filter options
+	exporters  []exporter      // List of exporters of structs with unexported field visibility
+	opts       Options         // List of all fundamental and filter options
+	strict     bool            // Whether to panic on unused options
+	captures   []captureOption // List of options to capture failures with
+	writers    []io.Writer     // List of writers to stream reports to
+	stats      []*Stats        // List of statistics to record into
+	ctx        context.Context // Optional context to stop the comparison
+	reportOpts []reportOption  // List of options to configure reports with
+	tracing    bool            // Whether any reporter traces applied options
+	parallel   int             // Number of goroutines to compare collections on; zero if sequential
+	noTrCache  bool            // Whether the outputs of transformers are not cached
+	maxDepth   int             // Depth below which nodes are not descended into; zero if unlimited
+
+	// These fields are only used for options registered with RegisterOptions,
+	// SetDefaultOptions, and RegisterSurrogate.
+	noRegistered bool                          // Whether registered options are disabled
+	registered   Options                       // List of registered options, which opts take precedence over
+	scoped       []*registeredOption           // Options in registered that are scoped to a type
+	scopes       []int                         // Number of steps in curPath of the type of each scoped option
+	surrogates   map[reflect.Type]*transformer // Registered surrogates keyed by type
 }
 
 func newState(opts []Option) *state {
 	// Always ensure a validator option exists to validate the inputs.
 	s := &state{opts: Options{validator{}}}
+	s.curPtrs.Init()
 	for _, opt := range opts {
 		s.processOption(opt)
 	}
+	if !s.noRegistered {
+		s.registered = registeredOptions()
+		for _, opt := range s.registered {
+			if opt, ok := opt.(*registeredOption); ok {
+				s.scoped = append(s.scoped, opt)
+			}
+		}
+		s.scopes = make([]int, len(s.scoped))
+		s.surrogates = registeredSurrogates()
+	}
+	if s.strict {
+		s.used = make([]bool, len(s.opts))
+	}
+	if len(s.captures) > 0 {
+		s.captureDiff = s.newReporter()
+		s.reporters = append(s.reporters, reporterOption{s.captureDiff})
+	}
+	for _, w := range s.writers {
+		r := s.newReporter()
+		r.w = w
+		s.reporters = append(s.reporters, reporterOption{r})
+	}
 	return s
 }
 
+// newReporter returns a defaultReporter configured by all report options.
+func (s *state) newReporter() *defaultReporter {
+	r := new(defaultReporter)
+	if !s.noRegistered {
+		r.format.Formatters = registeredFormatters()
+	}
+	for _, opt := range s.reportOpts {
+		opt(r)
+	}
+	return r
+}
+
 func (s *state) processOption(opt Option) {
 	switch opt := opt.(type) {
 	case nil:
@@ -169,15 +324,35 @@ func (s *state) processOption(opt Option) {
 			panic(fmt.Sprintf("cannot use an unfiltered option: %v", opt))
 		}
 		s.opts = append(s.opts, opt)
+	case exporter:
+		s.exporters = append(s.exporters, opt)
 	case visibleStructs:
-		if s.exporters == nil {
-			s.exporters = make(map[reflect.Type]bool)
-		}
-		for t := range opt {
-			s.exporters[t] = true
-		}
+		s.exporters = append(s.exporters, func(t reflect.Type) bool { return opt[t] })
 	case reporterOption:
+		if _, ok := opt.reporterIface.(optionReporter); ok {
+			s.tracing = true
+		}
 		s.reporters = append(s.reporters, opt)
+	case strictOption:
+		s.strict = true
+	case captureOption:
+		s.captures = append(s.captures, opt)
+	case writeDiffsOption:
+		s.writers = append(s.writers, opt.w)
+	case statsOption:
+		s.stats = append(s.stats, opt.st)
+	case reportOption:
+		s.reportOpts = append(s.reportOpts, opt)
+	case noRegisteredOptions:
+		s.noRegistered = true
+	case parallelOption:
+		s.parallel = int(opt)
+	case noTransformerCache:
+		s.noTrCache = true
+	case maxDepthOption:
+		s.maxDepth = int(opt)
+	case Config:
+		s.processOption(opt.options())
 	default:
 		panic(fmt.Sprintf("unknown option %T", opt))
 	}
@@ -192,21 +367,147 @@ func (s *state) statelessCompare(step PathStep) diff.Result {
 	// It is an implementation bug if the contents of curPath differs from
 	// when calling this function to when returning from it.
 
+	return s.recordCompare(step, nil)
+}
+
+// recordCompare is identical to statelessCompare, except that reporter
+// events are captured in rec (if non-nil) so that they may be replayed later
+// without needing to traverse the values again.
+func (s *state) recordCompare(step PathStep, rec *recorder) (res diff.Result) {
 	oldResult, oldReporters := s.result, s.reporters
 	s.result = diff.Result{} // Reset result
 	s.reporters = nil        // Remove reporters to avoid spurious printouts
+	if rec != nil {
+		s.reporters = []reporterOption{{rec}}
+	}
+	// The state is restored in a defer, so that it remains consistent even if
+	// the traversal is unwound because the context is done.
+	oldMaxDiffs := s.maxDiffs
+	s.maxDiffs = 0
+	defer func() { s.result, s.reporters, s.maxDiffs = oldResult, oldReporters, oldMaxDiffs }()
+	if s.stopAtDiff {
+		// The result of a stopped comparison is incomplete, yet still
+		// reports whether the values are equal.
+		defer func() {
+			if ex := recover(); ex != nil {
+				if _, ok := ex.(traversalStopped); !ok {
+					panic(ex)
+				}
+				res = s.result
+			}
+		}()
+	}
 	s.compareAny(step)
-	res := s.result
-	s.result, s.reporters = oldResult, oldReporters
-	return res
+	return s.result
 }
 
-func (s *state) compareAny(step PathStep) {
-	// TODO: Support cyclic data structures.
+// replay merges a result previously obtained from recordCompare into the
+// current result and forwards any recorded events to the registered reporters.
+func (s *state) replay(res diff.Result, rec *recorder) {
+	s.result.NumSame += res.NumSame
+	s.result.NumDiff += res.NumDiff
+	s.checkStop()
+	if rec == nil {
+		return
+	}
+	for _, e := range rec.events {
+		if e.step != nil {
+			s.curPath.push(e.step)
+		}
+		for _, r := range s.reporters {
+			switch {
+			case e.step != nil:
+				r.PushStep(e.step)
+			case e.pop:
+				r.PopStep()
+			case e.traced:
+				if or, ok := r.reporterIface.(optionReporter); ok {
+					or.ReportOption(s.curPath, e.option)
+				}
+			default:
+				r.Report(s.curPath, e.flags)
+				if ir, ok := r.reporterIface.(ignoreReporter); ok && e.flags&reportIgnored > 0 {
+					ir.ReportIgnored(s.curPath, e.ignoredBy)
+				}
+			}
+		}
+		if e.pop {
+			s.curPath.pop()
+		}
+	}
+}
+
+// recorder is a reporter that records all events so that they can be
+// replayed at a later point in time.
+type recorder struct {
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	step      PathStep // Non-nil for PushStep
+	pop       bool     // True for PopStep
+	flags     reportFlags
+	ignoredBy Option // The option that ignored the node, if known
+	traced    bool   // True for ReportOption
+	option    Option // The option passed to ReportOption
+}
+
+func (r *recorder) PushStep(ps PathStep) {
+	r.events = append(r.events, recordedEvent{step: copyStep(ps)})
+}
+func (r *recorder) Report(_ Path, f reportFlags) {
+	r.events = append(r.events, recordedEvent{flags: f})
+}
+func (r *recorder) PopStep() {
+	r.events = append(r.events, recordedEvent{pop: true})
+}
+func (r *recorder) ReportIgnored(_ Path, opt Option) {
+	r.events[len(r.events)-1].ignoredBy = opt
+}
+func (r *recorder) ReportOption(_ Path, opt Option) {
+	r.events = append(r.events, recordedEvent{traced: true, option: opt})
+}
+
+// copyStep returns a shallow copy of the PathStep.
+// The compareX methods reuse PathSteps between siblings, so any step that is
+// retained beyond the time it is popped must be copied.
+func copyStep(ps PathStep) PathStep {
+	switch ps := ps.(type) {
+	case *pathStep:
+		c := *ps
+		return &c
+	case *structField:
+		c := *ps
+		return &c
+	case *sliceIndex:
+		c := *ps
+		return &c
+	case *mapIndex:
+		c := *ps
+		return &c
+	case *indirect:
+		c := *ps
+		return &c
+	case *typeAssertion:
+		c := *ps
+		return &c
+	case *transform:
+		c := *ps
+		return &c
+	default:
+		return ps
+	}
+}
 
+func (s *state) compareAny(step PathStep) {
 	// Update the path stack.
 	s.curPath.push(step)
 	defer s.curPath.pop()
+	if len(s.scoped) > 0 {
+		defer s.enterScopes(step)()
+	}
+	s.work.Nodes++
+	s.checkContext()
 	for _, r := range s.reporters {
 		r.PushStep(step)
 		defer r.PopStep()
@@ -217,17 +518,38 @@ func (s *state) compareAny(step PathStep) {
 	t := step.Type()
 	vx, vy := step.Values()
 
+	// Cycle-detection for slice elements (see NOTE in compareSlice).
+	if si, ok := step.(*sliceIndex); ok && si.isSlice && vx.IsValid() && vy.IsValid() {
+		px, py := vx.Addr(), vy.Addr()
+		if eq, visited := s.curPtrs.Push(px, py); visited {
+			s.report(eq, reportByCycle)
+			return
+		}
+		defer s.curPtrs.Pop(px, py)
+	}
+
 	// Rule 1: Check whether an option applies on this node in the value tree.
 	if s.tryOptions(t, vx, vy) {
 		return
 	}
+	s.reportOption(nil)
 
 	// Rule 2: Check whether the type has a valid Equal method.
 	if s.tryMethod(t, vx, vy) {
 		return
 	}
 
-	// Rule 3: Recursively descend into each value's underlying kind.
+	// Rule 3: Check whether a surrogate is registered for the type.
+	if s.trySurrogate(t, vx, vy) {
+		return
+	}
+
+	// Rule 4: Check whether the node is too deep to descend into.
+	if s.tryMaxDepth(t, vx, vy) {
+		return
+	}
+
+	// Rule 5: Recursively descend into each value's underlying kind.
 	switch t.Kind() {
 	case reflect.Bool:
 		s.report(vx.Bool() == vy.Bool(), 0)
@@ -273,6 +595,14 @@ func (s *state) compareAny(step PathStep) {
 			s.report(vx.IsNil() && vy.IsNil(), 0)
 			return
 		}
+
+		// Cycle-detection for pointers.
+		if eq, visited := s.curPtrs.Push(vx, vy); visited {
+			s.report(eq, reportByCycle)
+			return
+		}
+		defer s.curPtrs.Pop(vx, vy)
+
 		vx, vy = vx.Elem(), vy.Elem()
 		s.compareAny(&indirect{pathStep{t.Elem(), vx, vy}})
 		return
@@ -295,13 +625,48 @@ func (s *state) compareAny(step PathStep) {
 
 func (s *state) tryOptions(t reflect.Type, vx, vy reflect.Value) bool {
 	// Evaluate all filters and apply the remaining options.
-	if opt := s.opts.filter(s, t, vx, vy); opt != nil {
+	opt, idx := s.opts.filterIndex(s, t, vx, vy)
+	var src Option // The option passed to Equal that opt originates from
+	if idx >= 0 {
+		src = s.opts[idx]
+	}
+	if ropt, rsrc, ok := s.filterRegistered(opt, t, vx, vy); ok {
+		opt, idx, src = ropt, -1, rsrc
+	}
+	if opt != nil {
+		if s.used != nil && idx >= 0 {
+			s.used[idx] = true
+		}
+		if _, ok := opt.(ignore); ok {
+			s.ignoredBy = src
+		}
+		s.reportOption(src)
 		opt.apply(s, vx, vy)
 		return true
 	}
 	return false
 }
 
+// checkUnused panics if the Strict option is in use and
+// any option was never applied.
+func (s *state) checkUnused() {
+	if s.used == nil {
+		return
+	}
+	var ss []string
+	for i, opt := range s.opts {
+		if _, ok := opt.(validator); !ok && !s.used[i] {
+			ss = append(ss, fmt.Sprint(opt))
+		}
+	}
+	if len(ss) > 0 {
+		const warning = "options never applied in strict mode"
+		const help = "consider removing options that no longer match any values"
+		set := strings.Join(ss, "\n\t")
+		panic(fmt.Sprintf("%s:\n\t%s\n%s", warning, set, help))
+	}
+}
+
 func (s *state) tryMethod(t reflect.Type, vx, vy reflect.Value) bool {
 	// Check if this type even has an Equal method.
 	m, ok := t.MethodByName("Equal")
@@ -309,6 +674,7 @@ func (s *state) tryMethod(t reflect.Type, vx, vy reflect.Value) bool {
 		return false
 	}
 
+	s.work.EqualMethods++
 	eq := s.callTTBFunc(m.Func, vx, vy)
 	s.report(eq, reportByMethod)
 	return true
@@ -317,7 +683,7 @@ func (s *state) tryMethod(t reflect.Type, vx, vy reflect.Value) bool {
 func (s *state) callTRFunc(f, v reflect.Value, step *transform) reflect.Value {
 	v = sanitizeValue(v, f.Type().In(0))
 	if !s.dynChecker.Next() {
-		return f.Call([]reflect.Value{v})[0]
+		return s.callFunc(f, v)
 	}
 
 	// Run the function twice and ensure that we get the same results back.
@@ -326,7 +692,7 @@ func (s *state) callTRFunc(f, v reflect.Value, step *transform) reflect.Value {
 	c := make(chan reflect.Value)
 	go detectRaces(c, f, v)
 	got := <-c
-	want := f.Call([]reflect.Value{v})[0]
+	want := s.callFunc(f, v)
 	if step.vx, step.vy = got, want; !s.statelessCompare(step).Equal() {
 		// To avoid false-positives with non-reflexive equality operations,
 		// we sanity check whether a value is equal to itself.
@@ -342,7 +708,7 @@ func (s *state) callTTBFunc(f, x, y reflect.Value) bool {
 	x = sanitizeValue(x, f.Type().In(0))
 	y = sanitizeValue(y, f.Type().In(1))
 	if !s.dynChecker.Next() {
-		return f.Call([]reflect.Value{x, y})[0].Bool()
+		return s.callFunc(f, x, y).Bool()
 	}
 
 	// Swapping the input arguments is sufficient to check that
@@ -352,13 +718,73 @@ func (s *state) callTTBFunc(f, x, y reflect.Value) bool {
 	c := make(chan reflect.Value)
 	go detectRaces(c, f, y, x)
 	got := <-c
-	want := f.Call([]reflect.Value{x, y})[0].Bool()
+	want := s.callFunc(f, x, y).Bool()
 	if !got.IsValid() || got.Bool() != want {
 		panic(fmt.Sprintf("non-deterministic or non-symmetric function detected: %s", function.NameOf(f)))
 	}
 	return want
 }
 
+// callXYBFunc is identical to callTTBFunc, except that it does not require
+// that f be symmetric with respect to its inputs.
+func (s *state) callXYBFunc(f, x, y reflect.Value) bool {
+	x = sanitizeValue(x, f.Type().In(0))
+	y = sanitizeValue(y, f.Type().In(1))
+	if !s.dynChecker.Next() {
+		return s.callFunc(f, x, y).Bool()
+	}
+
+	// Calling the function twice with the same arguments is sufficient to
+	// check that f is deterministic.
+	// We run in goroutines so that the race detector (if enabled) can detect
+	// unsafe mutations to the input.
+	c := make(chan reflect.Value)
+	go detectRaces(c, f, x, y)
+	got := <-c
+	want := s.callFunc(f, x, y).Bool()
+	if !got.IsValid() || got.Bool() != want {
+		panic(fmt.Sprintf("non-deterministic function detected: %s", function.NameOf(f)))
+	}
+	return want
+}
+
+// callFunc calls f with the given arguments and returns the first result.
+// Any panic that occurs is re-raised as a *PanicError with the name of f and
+// the current path, since a bare panic from deep within a user-provided
+// function is otherwise difficult to localize.
+func (s *state) callFunc(f reflect.Value, args ...reflect.Value) reflect.Value {
+	defer func() {
+		if ex := recover(); ex != nil {
+			p := make(Path, len(s.curPath))
+			for i, ps := range s.curPath {
+				p[i] = copyStep(ps)
+			}
+			panic(&PanicError{Func: function.NameOf(f), Path: p, Value: ex})
+		}
+	}()
+	return f.Call(args)[0]
+}
+
+// PanicError is the value that Equal and Diff panic with when a function
+// provided by an option (e.g., a Comparer or Transformer) panics.
+// It retains the original value passed to panic, such that a caller that
+// recovers from the panic can still inspect it.
+type PanicError struct {
+	Func  string      // Name of the function that panicked
+	Path  Path        // Path to the values that the function was called with
+	Value interface{} // Value that the function panicked with
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s at %#v: %v", e.Func, e.Path, e.Value)
+}
+
+// Unwrap returns the original panic value if it is an error, or nil otherwise.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
 func detectRaces(c chan<- reflect.Value, f reflect.Value, vs ...reflect.Value) {
 	var ret reflect.Value
 	defer func() {
@@ -384,6 +810,8 @@ func sanitizeValue(v reflect.Value, t reflect.Type) reflect.Value {
 func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 	var vax, vay reflect.Value // Addressable versions of vx and vy
 
+	var mayForce, mayForceInit bool
+
 	step := &structField{}
 	for i := 0; i < t.NumField(); i++ {
 		step.typ = t.Field(i).Type
@@ -405,7 +833,13 @@ func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 				vax = makeAddressable(vx)
 				vay = makeAddressable(vy)
 			}
-			step.mayForce = s.exporters[t]
+			if !mayForceInit {
+				for _, xf := range s.exporters {
+					mayForce = mayForce || xf(t)
+				}
+				mayForceInit = true
+			}
+			step.mayForce = mayForce
 			step.pvx = vax
 			step.pvy = vay
 			step.field = t.Field(i)
@@ -414,8 +848,16 @@ func (s *state) compareStruct(t reflect.Type, vx, vy reflect.Value) {
 	}
 }
 
+// maxRecordedEvents is the maximum number of reporter events that compareSlice
+// retains for the elements of a slice while computing its edit-script.
+const maxRecordedEvents = 1 << 14
+
 func (s *state) compareSlice(t reflect.Type, vx, vy reflect.Value) {
-	step := &sliceIndex{pathStep: pathStep{typ: t.Elem()}}
+	// NOTE: It is incorrect to call curPtrs.Push on the slice header pointer
+	// since slices represents a list of pointers, rather than a single pointer.
+	// The pointer checking logic must be handled on a per-element basis
+	// in compareAny.
+	step := &sliceIndex{pathStep: pathStep{typ: t.Elem()}, isSlice: t.Kind() == reflect.Slice}
 	withIndexes := func(ix, iy int) *sliceIndex {
 		if ix >= 0 {
 			step.vx, step.xkey = vx.Index(ix), ix
@@ -430,6 +872,68 @@ func (s *state) compareSlice(t reflect.Type, vx, vy reflect.Value) {
 		return step
 	}
 
+	// Each element comparison is performed at most once. The results (and any
+	// reporter events) are cached so that replaying the edit-script below does
+	// not need to traverse the elements a second time. Since most compared
+	// pairs of elements are not part of the final edit-script, only up to
+	// maxRecordedEvents events are retained, and the pairs whose events were
+	// dropped are traversed again when they are replayed.
+	type elemResult struct {
+		res diff.Result
+		rec *recorder // Nil if the events were not recorded or were dropped
+	}
+	cache := make(map[[2]int]elemResult)
+	var nevents int // Number of events retained in cache
+	keep := func(k [2]int, res diff.Result, rec *recorder) elemResult {
+		if rec != nil && nevents+len(rec.events) > maxRecordedEvents {
+			rec = nil
+		}
+		if rec != nil {
+			nevents += len(rec.events)
+		}
+		e := elemResult{res, rec}
+		cache[k] = e
+		return e
+	}
+	compareElem := func(ix, iy int) elemResult {
+		k := [2]int{ix, iy}
+		if e, ok := cache[k]; ok {
+			return e
+		}
+		var rec *recorder
+		if len(s.reporters) > 0 {
+			rec = new(recorder)
+		}
+		return keep(k, s.recordCompare(withIndexes(ix, iy), rec), rec)
+	}
+	replayElem := func(ix, iy int) {
+		e := compareElem(ix, iy)
+		if e.rec == nil && len(s.reporters) > 0 {
+			s.compareAny(withIndexes(ix, iy))
+			return
+		}
+		s.replay(e.res, e.rec)
+	}
+
+	// Compare the aligned pairs of elements concurrently, since these are
+	// all of the pairs compared by the edit-script if the slices are equal.
+	if s.parallel > 0 {
+		defer func(n int) { s.parallel = n }(s.parallel)
+		n := vx.Len()
+		if vy.Len() < n {
+			n = vy.Len()
+		}
+		steps := make([]PathStep, n)
+		for i := range steps {
+			steps[i] = &sliceIndex{pathStep{t.Elem(), vx.Index(i), vy.Index(i)}, i, i, step.isSlice}
+		}
+		s.compareConcurrently(steps, func(i int, res diff.Result, rec *recorder) bool {
+			keep([2]int{i, i}, res, rec)
+			return !s.stopAtDiff || res.NumDiff == 0
+		})
+		s.parallel = 0 // Nested collections are compared sequentially
+	}
+
 	// Ignore options are able to ignore missing elements in a slice.
 	// However, detecting these reliably requires an optimal differencing
 	// algorithm, for which diff.Difference is not.
@@ -440,23 +944,43 @@ func (s *state) compareSlice(t reflect.Type, vx, vy reflect.Value) {
 	var indexesX, indexesY []int
 	var ignoredX, ignoredY []bool
 	for ix := 0; ix < vx.Len(); ix++ {
-		ignored := s.statelessCompare(withIndexes(ix, -1)).NumDiff == 0
+		ignored := compareElem(ix, -1).res.NumDiff == 0
 		if !ignored {
 			indexesX = append(indexesX, ix)
 		}
 		ignoredX = append(ignoredX, ignored)
 	}
 	for iy := 0; iy < vy.Len(); iy++ {
-		ignored := s.statelessCompare(withIndexes(-1, iy)).NumDiff == 0
+		ignored := compareElem(-1, iy).res.NumDiff == 0
 		if !ignored {
 			indexesY = append(indexesY, iy)
 		}
 		ignoredY = append(ignoredY, ignored)
 	}
 
+	// When stopping at the first difference, the edit-script is unnecessary
+	// if the slices differ in length or in any pair of aligned elements,
+	// since the slices are then unequal.
+	if s.stopAtDiff {
+		for i := 0; i < len(indexesX) || i < len(indexesY); i++ {
+			var e elemResult
+			switch {
+			case i >= len(indexesY):
+				e = compareElem(indexesX[i], -1)
+			case i >= len(indexesX):
+				e = compareElem(-1, indexesY[i])
+			default:
+				e = compareElem(indexesX[i], indexesY[i])
+			}
+			if e.res.NumDiff > 0 {
+				s.replay(e.res, e.rec) // Stops the traversal
+			}
+		}
+	}
+
 	// Compute an edit-script for slices vx and vy (excluding ignored elements).
 	edits := diff.Difference(len(indexesX), len(indexesY), func(ix, iy int) diff.Result {
-		return s.statelessCompare(withIndexes(indexesX[ix], indexesY[iy]))
+		return compareElem(indexesX[ix], indexesY[iy]).res
 	})
 
 	// Replay the ignore-scripts and the edit-script.
@@ -473,13 +997,13 @@ func (s *state) compareSlice(t reflect.Type, vx, vy reflect.Value) {
 		}
 		switch e {
 		case diff.UniqueX:
-			s.compareAny(withIndexes(ix, -1))
+			replayElem(ix, -1)
 			ix++
 		case diff.UniqueY:
-			s.compareAny(withIndexes(-1, iy))
+			replayElem(-1, iy)
 			iy++
 		default:
-			s.compareAny(withIndexes(ix, iy))
+			replayElem(ix, iy)
 			ix++
 			iy++
 		}
@@ -493,10 +1017,21 @@ func (s *state) compareMap(t reflect.Type, vx, vy reflect.Value) {
 		return
 	}
 
+	// Cycle-detection for maps.
+	if eq, visited := s.curPtrs.Push(vx, vy); visited {
+		s.report(eq, reportByCycle)
+		return
+	}
+	defer s.curPtrs.Pop(vx, vy)
+
 	// We combine and sort the two map keys so that we can perform the
 	// comparisons in a deterministic order.
+	keys := value.SortKeys(append(vx.MapKeys(), vy.MapKeys()...))
+	if s.parallel > 0 && s.compareMapConcurrently(t, vx, vy, keys) {
+		return
+	}
 	step := &mapIndex{pathStep: pathStep{typ: t.Elem()}}
-	for _, k := range value.SortKeys(append(vx.MapKeys(), vy.MapKeys()...)) {
+	for _, k := range keys {
 		step.vx = vx.MapIndex(k)
 		step.vy = vy.MapIndex(k)
 		step.key = k
@@ -533,7 +1068,39 @@ func (s *state) report(eq bool, rf reportFlags) {
 		}
 	}
 	for _, r := range s.reporters {
-		r.Report(rf)
+		r.Report(s.curPath, rf)
+		if ir, ok := r.reporterIface.(ignoreReporter); ok && rf&reportIgnored > 0 {
+			ir.ReportIgnored(s.curPath, s.ignoredBy)
+		}
+	}
+	s.checkStop()
+}
+
+// traversalStopped is the panic value used to unwind the traversal once
+// a difference is found. It is only ever recovered by recordCompare and
+// compareContext.
+type traversalStopped struct{}
+
+// stopAtFirstDiff makes the comparison stop at the first difference,
+// unless an option observes the entire comparison.
+func (s *state) stopAtFirstDiff() {
+	s.stopAtDiff = len(s.reporters) == 0 && !s.strict && len(s.stats) == 0
+}
+
+// stopAfterReport makes the comparison stop once more differences are found
+// than r reports due to MaxDiffs, unless another option observes the entire
+// comparison.
+func (s *state) stopAfterReport(r *defaultReporter) {
+	if r.maxDiffs > 0 && !r.verbose && len(s.reporters) == 0 && !s.strict && len(s.stats) == 0 {
+		s.maxDiffs = r.maxDiffs
+	}
+}
+
+// checkStop unwinds the traversal if it stops at the first difference
+// and a difference has been found, or if more than maxDiffs were found.
+func (s *state) checkStop() {
+	if (s.stopAtDiff && s.result.NumDiff > 0) || (s.maxDiffs > 0 && s.result.NumDiff > s.maxDiffs) {
+		panic(traversalStopped{})
 	}
 }
 
@@ -584,7 +1151,9 @@ type dynChecker struct{ curr, next int }
 // Next increments the state and reports whether a check should be performed.
 //
 // Checks occur every Nth function call, where N is a triangular number:
+//
 //	0 1 3 6 10 15 21 28 36 45 55 66 78 91 105 120 136 153 171 190 ...
+//
 // See https://en.wikipedia.org/wiki/Triangular_number
 //
 // This sequence ensures that the cost of checks drops significantly as
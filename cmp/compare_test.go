@@ -6,8 +6,10 @@ package cmp_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -61,6 +63,8 @@ func TestDiff(t *testing.T) {
 					if ex := recover(); ex != nil {
 						if s, ok := ex.(string); ok {
 							gotPanic = s
+						} else if e, ok := ex.(*cmp.PanicError); ok {
+							gotPanic = e.Error()
 						} else {
 							panic(ex)
 						}
@@ -367,17 +371,13 @@ root:
 {[16]uint8}[10]:
 	-: 0x99
 	+: 0x1c
-{[16]uint8}[11->?]:
+{[16]uint8}[11->?] (element removed):
 	-: 0xe2
-	+: <non-existent>
-{[16]uint8}[12->?]:
+{[16]uint8}[12->?] (element removed):
 	-: 0x69
-	+: <non-existent>
-{[16]uint8}[?->12]:
-	-: <non-existent>
+{[16]uint8}[?->12] (element inserted):
 	+: 0x75
-{[16]uint8}[?->13]:
-	-: <non-existent>
+{[16]uint8}[?->13] (element inserted):
 	+: 0x31
 {[16]uint8}[14]:
 	-: 0x26
@@ -541,10 +541,10 @@ root:
 		x:     []interface{}{map[string]interface{}{"avg": 0.278, "hr": 65, "name": "Mark McGwire"}, map[string]interface{}{"avg": 0.288, "hr": 63, "name": "Sammy Sosa"}},
 		y:     []interface{}{map[string]interface{}{"avg": 0.278, "hr": 65.0, "name": "Mark McGwire"}, map[string]interface{}{"avg": 0.288, "hr": 63.0, "name": "Sammy Sosa"}},
 		wantDiff: `
-root[0]["hr"]:
+root[0]["hr"] (entry modified, type mismatch: int vs float64):
 	-: int(65)
 	+: float64(65)
-root[1]["hr"]:
+root[1]["hr"] (entry modified, type mismatch: int vs float64):
 	-: int(63)
 	+: float64(63)`,
 	}, {
@@ -601,10 +601,140 @@ root[1]["hr"]:
 			}, cmp.Ignore()),
 		},
 		wantDiff: `
-{[2]map[string]int}[1]["keep2"]:
-	-: <non-existent>
+{[2]map[string]int}[1]["keep2"] (entry added):
 	+: 2`,
 		reason: "all zero map entries are ignored (even if missing)",
+	}, {
+		label: label,
+		x:     struct{ A, B int }{1, 2},
+		y:     struct{ A, B int }{1, 3},
+		opts: []cmp.Option{
+			cmp.Strict(),
+			cmp.Comparer(func(x, y int) bool { return true }),
+		},
+		reason: "comparer is applied to both fields",
+	}, {
+		label: label,
+		x:     struct{ A, B int }{1, 2},
+		y:     struct{ A, B int }{1, 3},
+		opts: []cmp.Option{
+			cmp.Strict(),
+			cmp.Comparer(func(x, y int) bool { return true }),
+			cmp.Comparer(func(x, y string) bool { return true }),
+		},
+		wantPanic: "options never applied in strict mode",
+		reason:    "string comparer never applies",
+	}, {
+		label: label,
+		x:     struct{ A, B int }{1, 2},
+		y:     struct{ A, B int }{1, 3},
+		opts: []cmp.Option{
+			cmp.Comparer(func(x, y string) bool { return true }),
+		},
+		wantDiff: "root.B:\n\t-: 2\n\t+: 3\n",
+		reason:   "unused options are permitted in non-strict mode",
+	}, {
+		label: label,
+		x:     struct{ A, B int }{1, 2},
+		y:     struct{ A, B int }{1, 3},
+		opts: []cmp.Option{
+			cmp.Strict(),
+			cmpopts.IgnoreFields(struct{ A, B int }{}, "B"),
+			cmp.FilterPath(func(p cmp.Path) bool { return p.String() == "B" }, cmp.Ignore()),
+		},
+		wantPanic: "FilterPath(cmp_test.comparerTests.func",
+		reason:    "second ignore is shadowed by the first ignore and therefore never applied",
+	}, {
+		label: label,
+		x:     1,
+		y:     2,
+		opts: []cmp.Option{
+			cmp.Priority(1, cmp.Comparer(func(x, y int) bool { return true })),
+			cmp.Transformer("λ", func(x int) float64 { return float64(x) }),
+		},
+		reason: "comparer has higher priority than the transformer",
+	}, {
+		label: label,
+		x:     1,
+		y:     2,
+		opts: []cmp.Option{
+			cmp.Comparer(func(x, y int) bool { return true }),
+			cmp.Priority(1, cmp.Transformer("λ", func(x int) float64 { return float64(x) })),
+		},
+		wantDiff: "λ({int}):\n\t-: 1\n\t+: 2\n",
+		reason:   "transformer has higher priority than the comparer",
+	}, {
+		label: label,
+		x:     1,
+		y:     2,
+		opts: []cmp.Option{
+			cmp.Priority(1, cmp.Comparer(func(x, y int) bool { return true })),
+			cmp.Priority(1, cmp.Transformer("λ", func(x int) float64 { return float64(x) })),
+			cmp.Comparer(func(x, y int) bool { return false }),
+		},
+		wantPanic: "Priority(1, Transformer(λ",
+		reason:    "options with the same highest priority are still ambiguous",
+	}, {
+		label: label,
+		x:     1,
+		y:     2,
+		opts: []cmp.Option{
+			cmp.Priority(2, cmp.Priority(-1, cmp.Comparer(func(x, y int) bool { return true }))),
+			cmp.Priority(1, cmp.Comparer(func(x, y int) bool { return false })),
+		},
+		reason: "outer priority takes precedence over inner priority",
+	}, {
+		label: label,
+		x:     1,
+		y:     2,
+		opts: []cmp.Option{
+			cmp.Priority(1, cmp.Comparer(func(x, y int) bool { return false })),
+			cmp.FilterValues(func(x, y int) bool { return true }, cmp.Ignore()),
+		},
+		reason: "ignore takes precedence regardless of priority",
+	}, {
+		label: label,
+		x:     []string{"a", "b", "c"},
+		y:     []string{"a", "*", "c"},
+		opts: []cmp.Option{
+			cmp.FilterValuesAsymmetric(func(_, want string) bool { return want == "*" }, cmp.Ignore()),
+		},
+		reason: "the wildcard in y matches any value in x",
+	}, {
+		label: label,
+		x:     []string{"a", "*", "c"},
+		y:     []string{"a", "b", "c"},
+		opts: []cmp.Option{
+			cmp.FilterValuesAsymmetric(func(_, want string) bool { return want == "*" }, cmp.Ignore()),
+		},
+		wantDiff: `
+{[]string}[1]:
+	-: "*"
+	+: "b"`,
+		reason: "the wildcard is only recognized in y",
+	}, {
+		label: label,
+		x:     map[string][]int{"a": {1, 2}},
+		y:     map[string][]int{"a": {1, 3}},
+		opts: []cmp.Option{
+			cmp.Comparer(func(x, y int) bool {
+				if x == 3 || y == 3 {
+					panic("unsupported value")
+				}
+				return x == y
+			}),
+		},
+		wantPanic: `at {map[string][]int}["a"][1]: unsupported value`,
+		reason:    "panics within a comparer report the path of the values being compared",
+	}, {
+		label: label,
+		x:     struct{ A []int }{[]int{1, 2}},
+		y:     struct{ A []int }{nil},
+		opts: []cmp.Option{
+			cmp.Transformer("Sum", func(s []int) int { return s[0] + s[1] }),
+		},
+		wantPanic: "panic in cmp_test.comparerTests.func",
+		reason:    "panics within a transformer report the name of the function",
 	}}
 }
 
@@ -684,7 +814,7 @@ func transformerTests() []test {
 			}),
 		},
 		wantDiff: `
-λ({int}):
+λ({int}) (type mismatch: string vs float64):
 	-: "string"
 	+: 1`,
 	}, {
@@ -735,8 +865,7 @@ ParseJSON({string})["address"]["state"]:
 ParseJSON({string})["phoneNumbers"][0]["number"]:
 	-: "212 555-4321"
 	+: "212 555-1234"
-ParseJSON({string})["spouse"]:
-	-: <non-existent>
+ParseJSON({string})["spouse"] (entry added):
 	+: interface {}(nil)`,
 	}, {
 		label: label,
@@ -776,6 +905,7 @@ func embeddedTests() []test {
 	const label = "EmbeddedStruct/"
 
 	privateStruct := *new(ts.ParentStructA).PrivateStruct()
+	tsPkgPath := reflect.TypeOf(ts.ParentStructA{}).PkgPath()
 
 	createStructA := func(i int) ts.ParentStructA {
 		s := ts.ParentStructA{}
@@ -916,6 +1046,28 @@ func embeddedTests() []test {
 {teststructs.ParentStructA}.privateStruct.private:
 	-: 2
 	+: 3`,
+	}, {
+		label: label + "ParentStructA",
+		x:     createStructA(0),
+		y:     createStructA(1),
+		opts: []cmp.Option{
+			cmp.Exporter(func(t reflect.Type) bool { return t.PkgPath() == tsPkgPath }),
+		},
+		wantDiff: `
+{teststructs.ParentStructA}.privateStruct.Public:
+	-: 1
+	+: 2
+{teststructs.ParentStructA}.privateStruct.private:
+	-: 2
+	+: 3`,
+	}, {
+		label: label + "ParentStructA",
+		x:     createStructA(0),
+		y:     createStructA(0),
+		opts: []cmp.Option{
+			cmp.Exporter(func(t reflect.Type) bool { return t == reflect.TypeOf(ts.ParentStructA{}) }),
+		},
+		wantPanic: "cannot handle unexported field",
 	}, {
 		label: label + "ParentStructB",
 		x:     ts.ParentStructB{},
@@ -1823,12 +1975,10 @@ func project1Tests() []test {
 {teststructs.Eagle}.Slaps[0].Immutable.MildSlap:
 	-: false
 	+: true
-{teststructs.Eagle}.Slaps[0].Immutable.LoveRadius.Summer.Summary.Devices[1->?]:
+{teststructs.Eagle}.Slaps[0].Immutable.LoveRadius.Summer.Summary.Devices[1->?] (element removed):
 	-: "bar"
-	+: <non-existent>
-{teststructs.Eagle}.Slaps[0].Immutable.LoveRadius.Summer.Summary.Devices[2->?]:
-	-: "baz"
-	+: <non-existent>`,
+{teststructs.Eagle}.Slaps[0].Immutable.LoveRadius.Summer.Summary.Devices[2->?] (element removed):
+	-: "baz"`,
 	}}
 }
 
@@ -1908,11 +2058,9 @@ func project2Tests() []test {
 		}(),
 		opts: []cmp.Option{cmp.Comparer(pb.Equal), equalDish},
 		wantDiff: `
-{teststructs.GermBatch}.DirtyGerms[18][0->?]:
+{teststructs.GermBatch}.DirtyGerms[18][0->?] (element removed):
 	-: s"germ2"
-	+: <non-existent>
-{teststructs.GermBatch}.DirtyGerms[18][?->2]:
-	-: <non-existent>
+{teststructs.GermBatch}.DirtyGerms[18][?->2] (element inserted):
 	+: s"germ2"`,
 	}, {
 		label: label,
@@ -1940,13 +2088,11 @@ func project2Tests() []test {
 		}(),
 		opts: []cmp.Option{cmp.Comparer(pb.Equal), sortGerms, equalDish},
 		wantDiff: `
-{teststructs.GermBatch}.DirtyGerms[17]:
-	-: <non-existent>
+{teststructs.GermBatch}.DirtyGerms[17] (entry added):
 	+: []*testprotos.Germ{s"germ1"}
-Sort({teststructs.GermBatch}.DirtyGerms[18])[2->?]:
+Sort({teststructs.GermBatch}.DirtyGerms[18])[2->?] (element removed):
 	-: s"germ4"
-	+: <non-existent>
-{teststructs.GermBatch}.DishMap[1]:
+{teststructs.GermBatch}.DishMap[1] (entry modified):
 	-: (*teststructs.Dish)(nil)
 	+: &teststructs.Dish{err: &errors.errorString{s: "unexpected EOF"}}
 {teststructs.GermBatch}.GermStrain:
@@ -2031,10 +2177,9 @@ func project3Tests() []test {
 λ({teststructs.Dirt}.Proto):
 	-: s"blah"
 	+: s"proto"
-{teststructs.Dirt}.wizard["albus"]:
+{teststructs.Dirt}.wizard["albus"] (entry removed):
 	-: s"dumbledore"
-	+: <non-existent>
-{teststructs.Dirt}.wizard["harry"]:
+{teststructs.Dirt}.wizard["harry"] (entry modified):
 	-: s"potter"
 	+: s"otter"`,
 	}}
@@ -2115,9 +2260,8 @@ func project4Tests() []test {
 		}(),
 		opts: []cmp.Option{allowVisibility, transformProtos, cmp.Comparer(pb.Equal)},
 		wantDiff: `
-{teststructs.Cartel}.Headquarter.subDivisions[0->?]:
+{teststructs.Cartel}.Headquarter.subDivisions[0->?] (element removed):
 	-: "alpha"
-	+: <non-existent>
 {teststructs.Cartel}.Headquarter.publicMessage[2]:
 	-: 0x03
 	+: 0x04
@@ -2127,12 +2271,1116 @@ func project4Tests() []test {
 {teststructs.Cartel}.poisons[0].poisonType:
 	-: testprotos.PoisonType(1)
 	+: testprotos.PoisonType(5)
-{teststructs.Cartel}.poisons[1->?]:
-	-: &teststructs.Poison{poisonType: testprotos.PoisonType(2), manufacturer: "acme2"}
-	+: <non-existent>`,
+{teststructs.Cartel}.poisons[1->?] (element removed):
+	-: &teststructs.Poison{poisonType: testprotos.PoisonType(2), manufacturer: "acme2"}`,
 	}}
 }
 
+// TestDiffTraversal tests that Equal and Diff compare each slice element only
+// once, rather than once to compute the edit-script and again to report it.
+func TestDiffTraversal(t *testing.T) {
+	type S struct{ A int }
+	var mu sync.Mutex
+	var calls int
+	opt := cmp.Comparer(func(x, y S) bool {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return x.A == y.A
+	})
+
+	const n = 100
+	var x, y []S
+	for i := 0; i < n; i++ {
+		x = append(x, S{i})
+		y = append(y, S{i})
+	}
+	y[n/2].A = -1
+
+	for _, f := range []func(){
+		func() { cmp.Equal(x, y, opt) },
+		func() { cmp.Diff(x, y, opt) },
+	} {
+		calls = 0
+		f()
+		// Some calls are expected from the edit-script searching for a
+		// better match and from the periodic checks for symmetry,
+		// but they should be far fewer than two calls per element.
+		if calls >= 3*n/2 {
+			t.Errorf("comparer called %d times for %d elements", calls, n)
+		}
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	errUnsupported := errors.New("unsupported value")
+	opt := cmp.Comparer(func(x, y int) bool {
+		if x == 3 || y == 3 {
+			panic(errUnsupported)
+		}
+		return x == y
+	})
+	sum := cmp.Transformer("Sum", func(s []int) int { return s[0] + s[1] })
+
+	recoverPanic := func(f func()) (ex interface{}) {
+		defer func() { ex = recover() }()
+		f()
+		return nil
+	}
+
+	ex := recoverPanic(func() { cmp.Equal([]int{1, 2}, []int{1, 3}, opt) })
+	e, ok := ex.(*cmp.PanicError)
+	if !ok {
+		t.Fatalf("panic value = %#v, want *cmp.PanicError", ex)
+	}
+	if e.Value != errUnsupported || e.Unwrap() != errUnsupported {
+		t.Errorf("panic value = %v, want %v", e.Value, errUnsupported)
+	}
+	if got, want := e.Path.GoString(), "{[]int}[1]"; got != want {
+		t.Errorf("panic path = %v, want %v", got, want)
+	}
+	if !strings.HasPrefix(e.Func, "cmp_test.TestPanicError.func") {
+		t.Errorf("panic function = %v, want cmp_test.TestPanicError.func*", e.Func)
+	}
+
+	ex = recoverPanic(func() { cmp.Equal([]int{1}, []int{1}, sum) })
+	if e, ok := ex.(*cmp.PanicError); !ok {
+		t.Errorf("panic value = %#v, want *cmp.PanicError", ex)
+	} else if _, ok := e.Unwrap().(runtime.Error); !ok {
+		t.Errorf("unwrapped panic = %#v, want runtime.Error", e.Unwrap())
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	type Inner struct{ A, B int }
+	type Outer struct {
+		Name  string
+		Inner Inner
+		Skip  []Inner
+	}
+	x := Outer{"x", Inner{1, 2}, []Inner{{3, 4}}}
+	y := Outer{"y", Inner{1, 2}, []Inner{{3, 5}}}
+
+	var c cmp.Coverage
+	cmp.Equal(x, y, cmp.RecordCoverage(&c), cmpopts.IgnoreFields(Outer{}, "Skip"))
+
+	for _, p := range []string{"Name", "Inner.A", "Inner.B", "Skip", "{cmp_test.Outer}.Inner.A"} {
+		if !c.Visited(p) {
+			t.Errorf("Visited(%q) = false, want true", p)
+		}
+	}
+	for _, p := range []string{"Skip.A", "{cmp_test.Outer}.Skip[0]"} {
+		if c.Visited(p) {
+			t.Errorf("Visited(%q) = true, want false", p)
+		}
+	}
+	if got, want := len(c.Paths()), 6; got != want {
+		t.Errorf("len(Paths()) = %d, want %d\n%v", got, want, c.Paths())
+	}
+	var gotTypes []string
+	for _, t := range c.Types() {
+		gotTypes = append(gotTypes, t.String())
+	}
+	wantTypes := []string{"[]cmp_test.Inner", "cmp_test.Inner", "cmp_test.Outer", "int", "string"}
+	if !reflect.DeepEqual(gotTypes, wantTypes) {
+		t.Errorf("Types() = %v, want %v", gotTypes, wantTypes)
+	}
+	if got, want := c.Ignored(), []string{"{cmp_test.Outer}.Skip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ignored() = %v, want %v", got, want)
+	}
+
+	c = cmp.Coverage{}
+	cmp.Equal(x, y, cmp.RecordCoverage(&c), cmp.Transformer("Len", func(s []Inner) int { return len(s) }))
+	if got, want := c.Transformed(), []string{"{cmp_test.Outer}.Skip"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Transformed() = %v, want %v", got, want)
+	}
+	if !c.Visited("Len({cmp_test.Outer}.Skip)") || c.Visited("{cmp_test.Outer}.Skip[0]") {
+		t.Errorf("transformed sub-values should only be visited in transformed form:\n%v", c.Paths())
+	}
+}
+
+func TestOnDifference(t *testing.T) {
+	type Record struct {
+		Name  string
+		Tags  []string
+		Notes string
+	}
+	x := Record{"a", []string{"x", "y"}, "old"}
+	y := Record{"b", []string{"x"}, "new"}
+	opt := cmpopts.IgnoreFields(Record{}, "Notes")
+
+	var got []cmp.Difference
+	if cmp.Equal(x, y, opt, cmp.OnDifference(func(d cmp.Difference) { got = append(got, d) })) {
+		t.Fatalf("Equal reported unequal values as equal")
+	}
+	want := cmp.DiffResult(x, y, opt)
+	if len(got) != len(want) {
+		t.Fatalf("OnDifference called %d times, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Path.GoString() != want[i].Path.GoString() || got[i].Ignored != want[i].Ignored {
+			t.Errorf("difference %d = %#v, want %#v", i, got[i].Path, want[i].Path)
+		}
+	}
+}
+
+func TestWriteDiffs(t *testing.T) {
+	x := make([]int, 1000)
+	y := make([]int, 1000)
+	for i := range y {
+		y[i] = i
+	}
+
+	// The streamed report is not bound by the default report size.
+	var b bytes.Buffer
+	cmp.Equal(x, y, cmp.WriteDiffs(&b))
+	if want := cmp.Diff(x, y, cmp.MaxReportSize(1<<20, 1<<20)); b.String() != want {
+		t.Errorf("streamed report mismatch:\ngot:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if d := cmp.Diff(x, y); len(b.String()) <= len(d) {
+		t.Errorf("streamed report (%d bytes) is not longer than the limited report (%d bytes)", b.Len(), len(d))
+	}
+
+	// Differences beyond MaxDiffs are not written.
+	b.Reset()
+	cmp.Equal(x, y, cmp.WriteDiffs(&b), cmp.MaxDiffs(3))
+	if got := strings.Count(b.String(), "-: "); got != 3 {
+		t.Errorf("streamed report with MaxDiffs(3) has %d differences, want 3:\n%s", got, b.String())
+	}
+
+	b.Reset()
+	if !cmp.Equal(x, x, cmp.WriteDiffs(&b)) || b.Len() != 0 {
+		t.Errorf("streamed report of equal values = %q, want empty", b.String())
+	}
+}
+
+func TestCaptureFailures(t *testing.T) {
+	type Record struct {
+		Name  string
+		Count int
+	}
+	var captures []cmp.Capture
+	opt := cmp.CaptureFailures(json.Marshal, func(c cmp.Capture) { captures = append(captures, c) })
+
+	x, y := Record{"a", 1}, Record{"a", 2}
+	if !cmp.Equal(x, x, opt) || cmp.Diff(x, x, opt) != "" {
+		t.Fatalf("equal values reported as unequal")
+	}
+	if len(captures) != 0 {
+		t.Fatalf("captured %d equal comparisons, want 0", len(captures))
+	}
+
+	cmp.Equal(x, y, opt)
+	d := cmp.Diff(x, y, opt)
+	if len(captures) != 2 {
+		t.Fatalf("captured %d unequal comparisons, want 2", len(captures))
+	}
+	for _, c := range captures {
+		if c.Err != nil {
+			t.Errorf("unexpected encoding error: %v", c.Err)
+		}
+		if c.Diff != d {
+			t.Errorf("captured diff:\ngot:\n%s\nwant:\n%s", c.Diff, d)
+		}
+		var gotX, gotY Record
+		if err := c.Decode(json.Unmarshal, &gotX, &gotY); err != nil {
+			t.Errorf("Decode error: %v", err)
+		}
+		if gotX != x || gotY != y {
+			t.Errorf("Decode = (%v, %v), want (%v, %v)", gotX, gotY, x, y)
+		}
+		// Re-run the comparison with different options.
+		if !cmp.Equal(gotX, gotY, cmpopts.IgnoreFields(Record{}, "Count")) {
+			t.Errorf("replayed comparison reported unequal")
+		}
+	}
+
+	captures = nil
+	cmp.Equal(func() {}, func() {}, opt)
+	if len(captures) != 1 || captures[0].Err == nil {
+		t.Errorf("expected encoding error to be captured: %v", captures)
+	}
+}
+
+func TestDiffLargeElements(t *testing.T) {
+	// The elements are large enough that the reporter events of the compared
+	// pairs are not all retained while computing the edit-script,
+	// so some are traversed again to report them.
+	var x, y [][]int
+	for i := 0; i < 20; i++ {
+		e := make([]int, 1000)
+		for j := range e {
+			e[j] = 1000*i + j
+		}
+		x = append(x, e)
+		y = append(y, append([]int(nil), e...))
+	}
+	y[10][3] = -1
+	y = append(y[:5], append([][]int{{-1}}, y[5:]...)...)
+
+	var got []string
+	for _, d := range cmp.DiffResult(x, y) {
+		got = append(got, fmt.Sprintf("%#v: %v", d.Path, d.Edit()))
+	}
+	want := []string{
+		`{[][]int}[?->5]: inserted`,
+		`{[][]int}[10->11][3]: modified`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffResult mismatch:\ngot:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+	if d := cmp.Diff(x, y); !strings.Contains(d, "+: []int{-1}") || !strings.Contains(d, "-: 10003") {
+		t.Errorf("Diff missing the differences:\n%s", d)
+	}
+}
+
+func TestStats(t *testing.T) {
+	type Inner struct{ A, B int }
+	type Outer struct {
+		Name  string
+		Inner Inner
+		When  time.Time
+		Tags  []string
+	}
+	x := Outer{"x", Inner{1, 2}, now, []string{"a"}}
+	y := Outer{"y", Inner{1, 2}, now, []string{"a"}}
+
+	var st cmp.Stats
+	opts := []cmp.Option{
+		cmp.RecordStats(&st),
+		cmp.Comparer(func(x, y Inner) bool { return x == y }),
+		cmp.Transformer("Upper", strings.ToUpper),
+	}
+	cmp.Equal(x, y, opts...)
+	cmp.Diff(x, y, opts...)
+
+	// Each call visits at least the root, the four fields, the transformed
+	// Name, and the slice element of Tags together with its transformed form.
+	// Aligning the elements of Tags may visit some of these nodes again.
+	if st.Nodes < 2*8 {
+		t.Errorf("Nodes = %d, want at least %d", st.Nodes, 2*8)
+	}
+	want := cmp.Stats{Calls: 2, Comparers: 2, Transformers: 2 * 2, EqualMethods: 2}
+	got := st
+	got.Nodes, got.Duration = 0, 0
+	if got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if st.Duration <= 0 {
+		t.Errorf("Duration = %v, want positive duration", st.Duration)
+	}
+}
+
+func TestEqualStopsEarly(t *testing.T) {
+	type Item struct {
+		ID   int
+		Tags []string
+	}
+	x := make([]Item, 1000)
+	y := make([]Item, 1000)
+	for i := range x {
+		x[i] = Item{i, []string{"a", "b"}}
+		y[i] = Item{i, []string{"a", "b"}}
+	}
+	y[0].ID = -1
+	y[999].Tags = nil
+
+	var n int
+	opt := cmp.Comparer(func(x, y int) bool { n++; return x == y })
+	tests := []struct {
+		label string
+		opts  []cmp.Option
+		maxN  int // Maximum number of comparisons; zero if all elements are compared
+	}{{
+		// The Comparer may be called twice to check that it is symmetric.
+		label: "Equal",
+		maxN:  2,
+	}, {
+		label: "RecordStats",
+		opts:  []cmp.Option{cmp.RecordStats(new(cmp.Stats))},
+	}, {
+		label: "Strict",
+		opts:  []cmp.Option{cmp.Strict()},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			n = 0
+			if cmp.Equal(x, y, append(tt.opts, opt)...) {
+				t.Errorf("Equal = true, want false")
+			}
+			if tt.maxN > 0 && n > tt.maxN {
+				t.Errorf("compared %d integers, want at most %d", n, tt.maxN)
+			}
+			if tt.maxN == 0 && n < len(x) {
+				t.Errorf("compared %d integers, want at least %d", n, len(x))
+			}
+		})
+	}
+}
+
+func TestParallel(t *testing.T) {
+	type Item struct {
+		ID    int
+		Tags  []string
+		Attrs map[string]int
+	}
+	items := func(n int, mutate func(i int, it *Item)) []Item {
+		var s []Item
+		for i := 0; i < n; i++ {
+			it := Item{i, []string{"a", "b"}, map[string]int{"x": i}}
+			mutate(i, &it)
+			s = append(s, it)
+		}
+		return s
+	}
+	sx := items(500, func(int, *Item) {})
+	sy := items(500, func(i int, it *Item) {
+		if i%97 == 0 {
+			it.Tags = append(it.Tags, "c")
+		}
+		if i%131 == 0 {
+			it.Attrs["y"] = i
+		}
+	})
+	mx, my := make(map[int]Item), make(map[int]Item)
+	for i := range sx {
+		mx[i], my[i] = sx[i], sy[i]
+	}
+	delete(my, 7)
+	my[1000] = Item{ID: 1000}
+
+	tests := []struct {
+		label string
+		x, y  interface{}
+		opts  []cmp.Option
+	}{
+		{label: "SliceEqual", x: sx, y: sx},
+		{label: "Slice", x: sx, y: sy},
+		{label: "SliceInserted", x: sx, y: append([]Item{{ID: -1}}, sy...)},
+		{label: "MapEqual", x: mx, y: mx},
+		{label: "Map", x: mx, y: my},
+		{label: "Nested", x: struct{ M map[int]Item }{mx}, y: struct{ M map[int]Item }{my}},
+		{label: "Transformer", x: mx, y: my, opts: []cmp.Option{
+			cmp.Transformer("Upper", strings.ToUpper),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			popts := append([]cmp.Option{cmp.Parallel(4)}, tt.opts...)
+			if got, want := cmp.Equal(tt.x, tt.y, popts...), cmp.Equal(tt.x, tt.y, tt.opts...); got != want {
+				t.Errorf("Equal = %v, want %v", got, want)
+			}
+			if got, want := cmp.Diff(tt.x, tt.y, popts...), cmp.Diff(tt.x, tt.y, tt.opts...); got != want {
+				t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+
+			// Comparing concurrently may align elements differently and
+			// perform other checks of the options, thereby visiting more nodes.
+			var got, want cmp.Stats
+			cmp.Equal(tt.x, tt.y, append(popts, cmp.RecordStats(&got))...)
+			cmp.Equal(tt.x, tt.y, append(tt.opts, cmp.RecordStats(&want))...)
+			if got.Nodes < want.Nodes || got.Transformers < want.Transformers {
+				t.Errorf("Stats = %+v, want at least %+v", got, want)
+			}
+		})
+	}
+
+	// Unused options are detected across all goroutines.
+	opts := []cmp.Option{
+		cmp.Parallel(4),
+		cmp.Strict(),
+		cmp.FilterPath(func(p cmp.Path) bool { return p.Last().Type() == reflect.TypeOf(0) }, cmp.Ignore()),
+	}
+	func() {
+		defer func() {
+			if ex := recover(); ex != nil {
+				t.Errorf("Equal with Strict panicked: %v", ex)
+			}
+		}()
+		cmp.Equal(mx, my, opts...)
+	}()
+}
+
+func TestTransformerCache(t *testing.T) {
+	type Doc struct{ Body string }
+	shared := &Doc{"shared"}
+	x := []interface{}{"a", "a", shared, shared, Doc{"c"}}
+	y := []interface{}{"a", "A", shared, shared, Doc{"c"}}
+
+	var n int
+	upper := cmp.Transformer("Upper", func(s string) string { n++; return strings.ToUpper(s) })
+	body := cmp.Transformer("Body", func(d *Doc) string { n++; return d.Body })
+
+	// Only "a", "A", "c", and the body of shared are uppercased, and shared
+	// is transformed once. The transformers may be called again to check
+	// that they are deterministic.
+	n = 0
+	if !cmp.Equal(x, y, upper, body) {
+		t.Errorf("Equal = false, want true")
+	}
+	if n > 2*5 {
+		t.Errorf("transformed %d times, want at most %d", n, 2*5)
+	}
+
+	// Without the cache, every occurrence of each value is transformed.
+	n = 0
+	if !cmp.Equal(x, y, upper, body, cmp.NoTransformerCache()) {
+		t.Errorf("Equal with NoTransformerCache = false, want true")
+	}
+	if want := 4 + 2*4 + 2; n < want {
+		t.Errorf("transformed %d times with NoTransformerCache, want at least %d", n, want)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	type List struct {
+		Val  int
+		Next *List
+	}
+	list := func(tail *List, vals ...int) *List {
+		for i := len(vals) - 1; i >= 0; i-- {
+			tail = &List{vals[i], tail}
+		}
+		return tail
+	}
+	tail := list(nil, make([]int, 1000)...)
+
+	// The shared tail is compared by address, rather than descended into.
+	var r leafReporter
+	if !cmp.Equal(list(tail, 1, 2), list(tail, 1, 2), cmp.MaxDepth(4), cmp.Reporter(&r)) {
+		t.Errorf("Equal with shared tail = false, want true")
+	}
+	if got, want := r.leafs[len(r.leafs)-1], "Next.Next=true(depth)"; got != want {
+		t.Errorf("last leaf = %v, want %v", got, want)
+	}
+	if cmp.Equal(list(tail, 1, 2), list(tail, 1, 3), cmp.MaxDepth(4)) {
+		t.Errorf("Equal with different values = true, want false")
+	}
+
+	// Equal tails at different addresses are reported as unequal.
+	got := cmp.Diff(list(tail, 1, 2), list(list(nil, make([]int, 1000)...), 1, 2), cmp.MaxDepth(4))
+	if want := "{*cmp_test.List}.Next.Next (maximum depth reached):"; !strings.Contains(got, want) {
+		t.Errorf("Diff = %q, want it to contain %q", got, want)
+	}
+
+	// Options still apply at the maximum depth.
+	opt := cmp.FilterPath(func(p cmp.Path) bool { return len(p) > 4 }, cmp.Comparer(func(x, y *List) bool { return true }))
+	if !cmp.Equal(list(tail, 1, 2, 3), list(nil, 1, 2, 4), cmp.MaxDepth(4), opt) {
+		t.Errorf("Equal with Comparer at maximum depth = false, want true")
+	}
+
+	// Comparable values, such as structs of integers, are compared by ==.
+	type Point struct{ X, Y int }
+	type Shape struct{ Points []Point }
+	x := Shape{[]Point{{1, 2}, {3, 4}}}
+	y := Shape{[]Point{{1, 2}, {3, 5}}}
+	if got := cmp.Diff(x, y, cmp.MaxDepth(2)); !strings.Contains(got, "Points[1] (maximum depth reached):") {
+		t.Errorf("Diff = %q, want a difference at Points[1]", got)
+	}
+	if !cmp.Equal(x, Shape{[]Point{{1, 2}, {3, 4}}}, cmp.MaxDepth(2)) {
+		t.Errorf("Equal with equal points = false, want true")
+	}
+}
+
+func TestUseTags(t *testing.T) {
+	type Point struct {
+		X, Y float64
+		Z    float64 `cmp:"approx=0"`
+	}
+	type Model struct {
+		Cache   map[string]int `cmp:"-"`
+		Weights []float64      `cmp:"approx=1e-6"`
+		Tags    []string       `cmp:"unordered"`
+		Points  []Point        `cmp:"approx=0.5,unordered"`
+		Name    string         `json:"name"`
+	}
+	base := Model{
+		Cache:   map[string]int{"a": 1},
+		Weights: []float64{0.1, 0.2},
+		Tags:    []string{"a", "b"},
+		Points:  []Point{{X: 1, Y: 2}, {X: 3, Y: 4, Z: 5}},
+		Name:    "model",
+	}
+
+	tests := []struct {
+		label     string
+		modify    func(*Model)
+		wantEqual bool
+	}{{
+		label:     "Identical",
+		modify:    func(m *Model) {},
+		wantEqual: true,
+	}, {
+		label:     "IgnoredField",
+		modify:    func(m *Model) { m.Cache = map[string]int{"b": 2} },
+		wantEqual: true,
+	}, {
+		label:     "ApproxWithinMargin",
+		modify:    func(m *Model) { m.Weights = []float64{0.1 + 1e-7, 0.2 - 1e-7} },
+		wantEqual: true,
+	}, {
+		label:     "ApproxBeyondMargin",
+		modify:    func(m *Model) { m.Weights = []float64{0.1, 0.3} },
+		wantEqual: false,
+	}, {
+		label:     "Unordered",
+		modify:    func(m *Model) { m.Tags = []string{"b", "a"} },
+		wantEqual: true,
+	}, {
+		label:     "UnorderedMissingElement",
+		modify:    func(m *Model) { m.Tags = []string{"b", "b"} },
+		wantEqual: false,
+	}, {
+		label:     "NestedMargins",
+		modify:    func(m *Model) { m.Points = []Point{{X: 3.25, Y: 4, Z: 5}, {X: 1, Y: 2.25}} },
+		wantEqual: true,
+	}, {
+		label:     "NestedMarginOverridden",
+		modify:    func(m *Model) { m.Points = []Point{{X: 1, Y: 2}, {X: 3, Y: 4, Z: 5.25}} },
+		wantEqual: false,
+	}, {
+		label:     "OtherTagsUnused",
+		modify:    func(m *Model) { m.Name = "other" },
+		wantEqual: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			y := base
+			tt.modify(&y)
+			if got := cmp.Equal(base, y, cmp.UseTags("cmp")); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v\n%s", got, tt.wantEqual, cmp.Diff(base, y, cmp.UseTags("cmp")))
+			}
+		})
+	}
+
+	// Without the option, the tags have no effect.
+	y := base
+	y.Tags = []string{"b", "a"}
+	if cmp.Equal(base, y) {
+		t.Errorf("Equal without UseTags = true, want false")
+	}
+
+	// Invalid tags are reported when the field is compared.
+	type Bad struct {
+		A int `cmp:"unordered"`
+	}
+	func() {
+		defer func() {
+			want := `invalid "cmp" tag on field cmp_test.Bad.A: unordered requires a slice or array, not int`
+			if got := fmt.Sprint(recover()); got != want {
+				t.Errorf("panic = %q, want %q", got, want)
+			}
+		}()
+		cmp.Equal(Bad{}, Bad{}, cmp.UseTags("cmp"))
+	}()
+	type Unknown struct {
+		A []int `cmp:"sorted"`
+	}
+	func() {
+		defer func() {
+			want := `invalid "cmp" tag on field cmp_test.Unknown.A: unknown directive "sorted"`
+			if got := fmt.Sprint(recover()); got != want {
+				t.Errorf("panic = %q, want %q", got, want)
+			}
+		}()
+		cmp.Equal(Unknown{}, Unknown{}, cmp.UseTags("cmp"))
+	}()
+}
+
+func TestContext(t *testing.T) {
+	x := make([]int, 10000)
+	y := make([]int, 10000)
+	for i := range y {
+		y[i] = i
+	}
+
+	// A context that is never done does not affect the comparison.
+	eq, err := cmp.EqualContext(context.Background(), x, x)
+	if !eq || err != nil {
+		t.Errorf("EqualContext(Background) = (%v, %v), want (true, nil)", eq, err)
+	}
+	d, err := cmp.DiffContext(context.Background(), x, y)
+	if d != cmp.Diff(x, y) || err != nil {
+		t.Errorf("DiffContext(Background) = (%q, %v), want (%q, nil)", d, err, cmp.Diff(x, y))
+	}
+
+	// A context that is already done stops the comparison immediately.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cmp.EqualContext(ctx, x, y); err != context.Canceled {
+		t.Errorf("EqualContext(Canceled) error = %v, want %v", err, context.Canceled)
+	}
+
+	// A context that is done during the comparison reports the partial result.
+	mx := make(map[int]int)
+	my := make(map[int]int)
+	for i := range y {
+		mx[i], my[i] = x[i], y[i]
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var n int
+	opt := cmp.Comparer(func(x, y int) bool {
+		if n++; n == 1000 {
+			cancel()
+		}
+		return x == y
+	})
+	d, err = cmp.DiffContext(ctx, mx, my, opt)
+	if err != context.Canceled {
+		t.Errorf("DiffContext(Canceled) error = %v, want %v", err, context.Canceled)
+	}
+	if d == "" || n >= len(mx) {
+		t.Errorf("DiffContext(Canceled) compared %d elements with report %q, want partial report", n, d)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	type node struct {
+		Val  int
+		Next *node
+	}
+	ring := func(vals ...int) *node {
+		head := &node{Val: vals[0]}
+		n := head
+		for _, v := range vals[1:] {
+			n.Next = &node{Val: v}
+			n = n.Next
+		}
+		n.Next = head
+		return head
+	}
+	type graph map[string]graph
+	selfGraph := func() graph {
+		g := graph{}
+		g["self"] = g
+		return g
+	}
+	type list []list
+	selfList := func() list {
+		l := list{nil}
+		l[0] = l
+		return l
+	}
+	type ptrGraph struct{ M map[*ptrGraph]bool }
+	pairGraph := func() *ptrGraph {
+		a, b := &ptrGraph{}, &ptrGraph{}
+		a.M = map[*ptrGraph]bool{a: true, b: true}
+		b.M = map[*ptrGraph]bool{a: true, b: true}
+		return a
+	}
+
+	tests := []struct {
+		label     string
+		x, y      interface{}
+		wantEqual bool
+	}{
+		{"SameRings", ring(1, 2, 3), ring(1, 2, 3), true},
+		{"DifferentValues", ring(1, 2, 3), ring(1, 2, 4), false},
+		{"DifferentPeriods", ring(1, 1), ring(1, 1, 1), false},
+		{"Maps", selfGraph(), selfGraph(), true},
+		{"MapsDifferentStructure", selfGraph(), graph{"self": graph{"self": nil}}, false},
+		{"Slices", selfList(), selfList(), true},
+		{"PointerKeyedMaps", pairGraph(), pairGraph(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := cmp.Equal(tt.x, tt.y); got != tt.wantEqual {
+				t.Errorf("Equal = %v, want %v", got, tt.wantEqual)
+			}
+			if got := cmp.Diff(tt.x, tt.y) == ""; got != tt.wantEqual {
+				t.Errorf("Diff reports equal = %v, want %v", got, tt.wantEqual)
+			}
+		})
+	}
+
+	r := new(leafReporter)
+	cmp.Equal(ring(1, 2), ring(1, 2), cmp.Reporter(r))
+	if got, want := r.leafs[len(r.leafs)-1], "Next.Next=true(cycle)"; got != want {
+		t.Errorf("last leaf = %v, want %v", got, want)
+	}
+}
+
+// leafReporter records the result of every leaf node.
+type leafReporter struct {
+	path  cmp.Path
+	leafs []string
+}
+
+func (r *leafReporter) PushStep(ps cmp.PathStep) { r.path = append(r.path, ps) }
+func (r *leafReporter) Report(p cmp.Path, rs cmp.Result) {
+	var how []string
+	switch {
+	case rs.ByIgnore():
+		how = append(how, "ignored")
+	case rs.ByMethod():
+		how = append(how, "method")
+	case rs.ByFunc():
+		how = append(how, "func")
+	case rs.ByCycle():
+		how = append(how, "cycle")
+	case rs.ByDepth():
+		how = append(how, "depth")
+	}
+	if rs.ByTransform() {
+		how = append(how, "transform")
+	}
+	r.leafs = append(r.leafs, fmt.Sprintf("%v=%v(%s)", r.path, rs.Equal(), strings.Join(how, ",")))
+}
+func (r *leafReporter) PopStep() { r.path = r.path[:len(r.path)-1] }
+
+func TestReporter(t *testing.T) {
+	type Record struct {
+		Name  string
+		Time  time.Time
+		Size  int
+		Notes string
+	}
+	now := time.Now()
+	x := Record{"a", now, 1, "x"}
+	y := Record{"a", now, 2, "y"}
+
+	r := new(leafReporter)
+	cmp.Equal(x, y, cmp.Reporter(r), cmpopts.IgnoreFields(Record{}, "Notes"),
+		cmp.Comparer(func(x, y int) bool { return x == y }))
+	got := strings.Join(r.leafs, " ")
+	want := "Name=true() Time=true(method) Size=false(func) Notes=true(ignored)"
+	if got != want {
+		t.Errorf("leaf results mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+
+	r = new(leafReporter)
+	cmp.Equal(x, y, cmp.Reporter(r), cmpopts.IgnoreFields(Record{}, "Time", "Size"),
+		cmpopts.AcyclicTransformer("Upper", strings.ToUpper))
+	got = strings.Join(r.leafs, " ")
+	want = "Name=true(transform) Time=true(ignored) Size=true(ignored) Notes=false(transform)"
+	if got != want {
+		t.Errorf("leaf results mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestDiffResult(t *testing.T) {
+	type Record struct {
+		Name  string
+		Tags  []string
+		Notes string
+	}
+	x := Record{"a", []string{"x", "y"}, "old"}
+	y := Record{"b", []string{"x"}, "new"}
+
+	var got []string
+	for _, d := range cmp.DiffResult(x, y, cmpopts.IgnoreFields(Record{}, "Notes")) {
+		got = append(got, fmt.Sprintf("%#v: %v, %v, ignored=%v, %v", d.Path, d.X, d.Y, d.Ignored, d.Edit()))
+	}
+	want := []string{
+		`{cmp_test.Record}.Name: a, b, ignored=false, modified`,
+		`{cmp_test.Record}.Tags[1->?]: y, <invalid reflect.Value>, ignored=false, removed`,
+		`{cmp_test.Record}.Notes: old, new, ignored=true, modified`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffResult mismatch:\ngot:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+	if got := cmp.DiffResult(x, x); len(got) != 0 {
+		t.Errorf("DiffResult of equal values = %v, want empty", got)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	type Inner struct{ A, B int }
+	type Outer struct {
+		Name  string
+		Inner Inner
+	}
+	x := Outer{"x", Inner{1, 2}}
+	y := Outer{"x", Inner{1, 3}}
+
+	var events []string
+	before := func(p cmp.Path) { events = append(events, "+"+p.String()) }
+	after := func(p cmp.Path, eq bool) { events = append(events, fmt.Sprintf("-%s=%v", p, eq)) }
+	cmp.Equal(x, y, cmp.Hooks(before, after))
+	got := strings.Join(events, " ")
+	want := "+ +Name -Name=true +Inner +Inner.A -Inner.A=true +Inner.B -Inner.B=false -Inner=false -=false"
+	if got != want {
+		t.Errorf("events mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+
+	var n int
+	cmp.Equal(x, y, cmp.Hooks(nil, func(cmp.Path, bool) { n++ }))
+	if n != 5 {
+		t.Errorf("number of nodes = %d, want 5", n)
+	}
+}
+
+func TestAuditIgnored(t *testing.T) {
+	type Inner struct{ A, B int }
+	type Outer struct {
+		Name    string
+		Secret  string
+		Inners  []Inner
+		private int
+	}
+	x := Outer{"x", "a", []Inner{{1, 2}, {3, 4}}, 1}
+	y := Outer{"x", "b", []Inner{{1, 5}, {0, 3}, {3, 6}}, 2}
+
+	var got []string
+	audit := func(p cmp.Path, opt cmp.Option) {
+		got = append(got, fmt.Sprintf("%#v: %v", p, opt))
+	}
+	ignoreB := cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".B" }, cmp.Ignore())
+	cmp.Equal(x, y, cmp.AuditIgnored(audit), cmpopts.IgnoreUnexported(Outer{}), ignoreB,
+		cmpopts.IgnoreFields(Outer{}, "Secret"))
+	want := []string{
+		fmt.Sprintf("{cmp_test.Outer}.Secret: %v", cmpopts.IgnoreFields(Outer{}, "Secret")),
+		fmt.Sprintf("{cmp_test.Outer}.Inners[0].B: %v", ignoreB),
+		fmt.Sprintf("{cmp_test.Outer}.Inners[1->2].B: %v", ignoreB),
+		fmt.Sprintf("{cmp_test.Outer}.private: %v", cmpopts.IgnoreUnexported(Outer{})),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("audited nodes mismatch:\ngot:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestTraceOptions(t *testing.T) {
+	type Inner struct{ A, B int }
+	type Outer struct {
+		Name   string
+		Inners []Inner
+		Score  float64
+	}
+	x := Outer{"x", []Inner{{1, 2}, {3, 4}}, 1.0}
+	y := Outer{"X", []Inner{{1, 5}, {0, 3}, {3, 6}}, 1.05}
+
+	var got []string
+	trace := func(p cmp.Path, opt cmp.Option) {
+		got = append(got, fmt.Sprintf("%#v: %v", p, opt))
+	}
+	ignoreB := cmp.FilterPath(func(p cmp.Path) bool { return p.Last().String() == ".B" }, cmp.Ignore())
+	approx := cmpopts.EquateApprox(0.1, 0)
+	lower := cmp.Transformer("Lower", strings.ToLower)
+	cmp.Equal(x, y, cmp.TraceOptions(trace), ignoreB, approx, lower)
+	want := []string{
+		"{cmp_test.Outer}: <nil>",
+		fmt.Sprintf("{cmp_test.Outer}.Name: %v", lower),
+		"Lower({cmp_test.Outer}.Name): <nil>",
+		"{cmp_test.Outer}.Inners: <nil>",
+		"{cmp_test.Outer}.Inners[0]: <nil>",
+		"{cmp_test.Outer}.Inners[0].A: <nil>",
+		fmt.Sprintf("{cmp_test.Outer}.Inners[0].B: %v", ignoreB),
+		"{cmp_test.Outer}.Inners[?->1]: <nil>",
+		"{cmp_test.Outer}.Inners[1->2]: <nil>",
+		"{cmp_test.Outer}.Inners[1->2].A: <nil>",
+		fmt.Sprintf("{cmp_test.Outer}.Inners[1->2].B: %v", ignoreB),
+		fmt.Sprintf("{cmp_test.Outer}.Score: %v", approx.(cmp.Options)[0]),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("traced nodes mismatch:\ngot:\n\t%s\nwant:\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+// foreign is a type with unexported fields that cannot be modified.
+type foreign struct{ id, cache int }
+
+func init() {
+	cmp.RegisterSurrogate("foreignID", func(f foreign) int { return f.id })
+}
+
+func TestRegisterSurrogate(t *testing.T) {
+	type Record struct {
+		Name string
+		F    foreign
+		P    *foreign
+	}
+	x := Record{"a", foreign{1, 100}, &foreign{2, 200}}
+	y := Record{"a", foreign{1, 300}, &foreign{2, 400}}
+	if !cmp.Equal(x, y) {
+		t.Errorf("Equal = false, want true\n%s", cmp.Diff(x, y))
+	}
+
+	y.P.id = 3
+	got := cmp.Diff(x, y)
+	want := "foreignID((*{cmp_test.Record}.P)):\n\t-: 2\n\t+: 3\n"
+	if got != want {
+		t.Errorf("Diff:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Explicit options take precedence over the surrogate.
+	if cmp.Equal(x, y, cmp.Comparer(func(x, y foreign) bool { return x.cache == y.cache })) {
+		t.Errorf("Equal = true, want false")
+	}
+
+	// Registered surrogates are disabled along with registered options.
+	func() {
+		defer func() {
+			if ex := recover(); ex == nil {
+				t.Errorf("Equal with NoRegisteredOptions did not panic on unexported fields")
+			}
+		}()
+		cmp.Equal(x, y, cmp.NoRegisteredOptions())
+	}()
+}
+
+// canonicalID is an identifier that is case-insensitive.
+type canonicalID string
+
+// registeredRecord is a type with options registered for it.
+type registeredRecord struct {
+	ID      canonicalID
+	Updated int
+	Tags    []string
+}
+
+func init() {
+	cmp.RegisterOptions(canonicalID(""), cmp.Comparer(func(x, y canonicalID) bool {
+		return strings.EqualFold(string(x), string(y))
+	}))
+	cmp.RegisterOptions(registeredRecord{}, cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".Updated"
+	}, cmp.Ignore()))
+}
+
+// message is an interface implemented by generated message types,
+// similar to proto.Message.
+type message interface {
+	ProtoMessage()
+}
+
+// fakeMessage is a message with internal state that must not be compared.
+type fakeMessage struct {
+	Name  string
+	cache []byte
+}
+
+func (*fakeMessage) ProtoMessage() {}
+
+func init() {
+	cmp.RegisterOptions((*message)(nil), cmp.Comparer(func(x, y *fakeMessage) bool {
+		return (x == nil) == (y == nil) && (x == nil || x.Name == y.Name)
+	}))
+}
+
+func TestRegisterInterfaceOptions(t *testing.T) {
+	type container struct {
+		M  *fakeMessage
+		Ms []message
+	}
+	x := container{&fakeMessage{"a", []byte{1}}, []message{&fakeMessage{"b", nil}}}
+	y := container{&fakeMessage{"a", []byte{2}}, []message{&fakeMessage{"b", []byte{3}}}}
+	if !cmp.Equal(x, y) {
+		t.Errorf("Equal = false, want true\n%s", cmp.Diff(x, y))
+	}
+	y.Ms[0].(*fakeMessage).Name = "c"
+	if cmp.Equal(x, y) {
+		t.Errorf("Equal = true, want false")
+	}
+}
+
+// hexID is an identifier type with a registered formatter.
+type hexID uint32
+
+func init() {
+	cmp.RegisterFormatter(func(v hexID) string { return fmt.Sprintf("#%04x", uint32(v)) })
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	x, y := []hexID{0xbeef}, []hexID{0xcafe}
+	tests := []struct {
+		label string
+		opts  []cmp.Option
+		want  string
+	}{{
+		label: "Registered",
+		want:  "{[]cmp_test.hexID}[0]:\n\t-: #beef\n\t+: #cafe\n",
+	}, {
+		label: "Explicit",
+		opts:  []cmp.Option{cmp.Formatter(func(v hexID) string { return fmt.Sprint(uint32(v)) })},
+		want:  "{[]cmp_test.hexID}[0]:\n\t-: 48879\n\t+: 51966\n",
+	}, {
+		label: "NoRegisteredOptions",
+		opts:  []cmp.Option{cmp.NoRegisteredOptions()},
+		want:  "{[]cmp_test.hexID}[0]:\n\t-: cmp_test.hexID(48879)\n\t+: cmp_test.hexID(51966)\n",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := cmp.Diff(x, y, tt.opts...); got != tt.want {
+				t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterOptions(t *testing.T) {
+	x := []registeredRecord{{"abc", 1, []string{"a"}}}
+	y := []registeredRecord{{"ABC", 2, []string{"a"}}}
+	if !cmp.Equal(x, y) {
+		t.Errorf("Equal = false, want true\n%s", cmp.Diff(x, y))
+	}
+
+	// Registered options only apply within values of the registered type.
+	type other struct{ Updated int }
+	if cmp.Equal(other{1}, other{2}) {
+		t.Errorf("Equal = true, want false")
+	}
+
+	// Explicit options take precedence over registered options.
+	if cmp.Equal(x, y, cmp.Comparer(func(x, y canonicalID) bool { return x == y })) {
+		t.Errorf("Equal = true, want false")
+	}
+
+	got := cmp.Diff(x, y, cmp.NoRegisteredOptions())
+	want := `{[]cmp_test.registeredRecord}[0].ID:
+	-: cmp_test.canonicalID("abc")
+	+: cmp_test.canonicalID("ABC")
+{[]cmp_test.registeredRecord}[0].Updated:
+	-: 1
+	+: 2
+`
+	if got != want {
+		t.Errorf("Diff:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	type state struct {
+		Name    string
+		Version int
+	}
+	x := state{"a", 1}
+	y := state{"a", 2}
+	if cmp.Equal(x, y) {
+		t.Errorf("Equal = true, want false")
+	}
+
+	cmp.SetDefaultOptions(cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".Version"
+	}, cmp.Ignore()))
+	defer cmp.SetDefaultOptions()
+	if !cmp.Equal(x, y) {
+		t.Errorf("Equal = false, want true\n%s", cmp.Diff(x, y))
+	}
+	if cmp.Equal(x, y, cmp.NoRegisteredOptions()) {
+		t.Errorf("Equal with NoRegisteredOptions = true, want false")
+	}
+
+	// Explicit options take precedence over default options.
+	if cmp.Equal(x, y, cmp.Comparer(func(x, y int) bool { return x == y })) {
+		t.Errorf("Equal with explicit option = true, want false")
+	}
+
+	// Default options are combined with registered options.
+	if !cmp.Equal(registeredRecord{ID: "a"}, registeredRecord{ID: "A"}) {
+		t.Errorf("Equal = false, want true")
+	}
+
+	cmp.SetDefaultOptions()
+	if cmp.Equal(x, y) {
+		t.Errorf("Equal after removing default options = true, want false")
+	}
+}
+
 // BenchmarkBytes benchmarks the performance of performing Equal or Diff on
 // large slices of bytes.
 func BenchmarkBytes(b *testing.B) {
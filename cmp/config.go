@@ -0,0 +1,140 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "reflect"
+
+// Config consolidates the options that configure the behavior of Equal and
+// Diff, rather than configuring each through a separate Option.
+// The zero value of each field means that the corresponding behavior is left
+// at its default. A Config may be passed to Equal and Diff as an Option,
+// or may be used directly through its Equal and Diff methods.
+type Config struct {
+	// MaxOutput is the approximate maximum number of bytes in a report from
+	// Diff before further differences are summarized. The default is 4096.
+	MaxOutput int
+
+	// MaxOutputLines is the approximate maximum number of lines in a report
+	// from Diff before further differences are summarized. The default is 256.
+	MaxOutputLines int
+
+	// MaxDifferences is the maximum number of differences in a report from
+	// Diff before further differences are summarized. The default is no limit
+	// other than the one imposed by MaxOutput.
+	MaxDifferences int
+
+	// MaxDiffsPerPath is equivalent to the MaxDiffsPerPath option.
+	MaxDiffsPerPath int
+
+	// MaxDiffsPerContainer is equivalent to the MaxDiffsPerContainer option.
+	MaxDiffsPerContainer int
+
+	// GroupDigits is equivalent to the GroupDigits option.
+	GroupDigits bool
+
+	// FloatExponentRange, if non-zero, is equivalent to the FloatExponentRange
+	// option with the minimum and maximum exponents.
+	FloatExponentRange [2]int
+
+	// ErrorDetails is equivalent to the ErrorDetails option.
+	ErrorDetails bool
+
+	// FieldTag, if non-empty, is equivalent to the FieldNamesByTag option.
+	FieldTag string
+
+	// Strict is equivalent to the Strict option.
+	Strict bool
+
+	// Verbose is equivalent to the Verbose option.
+	Verbose bool
+
+	// Colorize is equivalent to the Colorize option.
+	Colorize bool
+
+	// Parallelism, if non-zero, is equivalent to the Parallel option with
+	// the number of goroutines.
+	Parallelism int
+
+	// MaxDepth, if non-zero, is equivalent to the MaxDepth option.
+	MaxDepth int
+
+	// UseTags, if non-empty, is equivalent to the UseTags option with
+	// the key of the struct tags.
+	UseTags string
+
+	// NoTransformerCache is equivalent to the NoTransformerCache option.
+	NoTransformerCache bool
+
+	// NoRegisteredOptions is equivalent to the NoRegisteredOptions option.
+	NoRegisteredOptions bool
+}
+
+// Equal is identical to the Equal function with c as the first option.
+func (c Config) Equal(x, y interface{}, opts ...Option) bool {
+	return Equal(x, y, append([]Option{c}, opts...)...)
+}
+
+// Diff is identical to the Diff function with c as the first option.
+func (c Config) Diff(x, y interface{}, opts ...Option) string {
+	return Diff(x, y, append([]Option{c}, opts...)...)
+}
+
+// options returns the list of options equivalent to c.
+func (c Config) options() Options {
+	var opts Options
+	if c.MaxOutput != 0 || c.MaxOutputLines != 0 {
+		opts = append(opts, MaxReportSize(c.MaxOutput, c.MaxOutputLines))
+	}
+	if c.MaxDifferences != 0 {
+		opts = append(opts, MaxDiffs(c.MaxDifferences))
+	}
+	if c.MaxDiffsPerPath != 0 {
+		opts = append(opts, MaxDiffsPerPath(c.MaxDiffsPerPath))
+	}
+	if c.MaxDiffsPerContainer != 0 {
+		opts = append(opts, MaxDiffsPerContainer(c.MaxDiffsPerContainer))
+	}
+	if c.GroupDigits {
+		opts = append(opts, GroupDigits())
+	}
+	if c.FloatExponentRange != [2]int{} {
+		opts = append(opts, FloatExponentRange(c.FloatExponentRange[0], c.FloatExponentRange[1]))
+	}
+	if c.ErrorDetails {
+		opts = append(opts, ErrorDetails())
+	}
+	if c.FieldTag != "" {
+		opts = append(opts, FieldNamesByTag(c.FieldTag))
+	}
+	if c.Strict {
+		opts = append(opts, Strict())
+	}
+	if c.Verbose {
+		opts = append(opts, Verbose())
+	}
+	if c.Colorize {
+		opts = append(opts, Colorize())
+	}
+	if c.Parallelism != 0 {
+		opts = append(opts, Parallel(c.Parallelism))
+	}
+	if c.MaxDepth != 0 {
+		opts = append(opts, MaxDepth(c.MaxDepth))
+	}
+	if c.UseTags != "" {
+		opts = append(opts, UseTags(c.UseTags))
+	}
+	if c.NoTransformerCache {
+		opts = append(opts, NoTransformerCache())
+	}
+	if c.NoRegisteredOptions {
+		opts = append(opts, NoRegisteredOptions())
+	}
+	return opts
+}
+
+func (Config) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
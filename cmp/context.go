@@ -0,0 +1,87 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "context"
+
+// contextCheckInterval is the number of nodes visited between checks of
+// whether the context is done. Checking the context takes a lock,
+// so it is not done on every node.
+const contextCheckInterval = 256
+
+// EqualContext is identical to Equal, except that the comparison stops
+// early if ctx is done before the traversal of the values completes.
+//
+// If the comparison is stopped early, then the result is incomplete and
+// EqualContext returns the error from ctx.Err(). In that case, the boolean
+// only reports whether the portion of the values compared so far is equal;
+// a true result does not imply that x and y are equal.
+func EqualContext(ctx context.Context, x, y interface{}, opts ...Option) (bool, error) {
+	s := newState(opts)
+	s.ctx = ctx
+	s.stopAtFirstDiff()
+	s.compareRoot(x, y)
+	return s.result.Equal(), s.ctxErr
+}
+
+// DiffContext is identical to Diff, except that the comparison stops
+// early if ctx is done before the traversal of the values completes.
+//
+// If the comparison is stopped early, then the result is incomplete and
+// DiffContext returns the error from ctx.Err() along with a report of the
+// differences found so far.
+func DiffContext(ctx context.Context, x, y interface{}, opts ...Option) (string, error) {
+	s := newState(opts)
+	s.ctx = ctx
+	r := s.newReporter()
+	s.stopAfterReport(r)
+	s.reporters = append(s.reporters, reporterOption{r})
+	s.compareRoot(x, y)
+	r.stopped = s.stopped
+	d := r.String()
+	if s.ctxErr == nil && (d == "") != s.result.Equal() {
+		panic("inconsistent difference and equality results")
+	}
+	return d, s.ctxErr
+}
+
+// contextDone is the panic value used to unwind the traversal once the
+// context is done. It is only ever recovered by compareContext.
+type contextDone struct{ err error }
+
+// checkContext unwinds the traversal if the context is done.
+// It only consults the context periodically.
+func (s *state) checkContext() {
+	if s.ctx == nil || s.work.Nodes%contextCheckInterval != 1 {
+		return
+	}
+	if err := s.ctx.Err(); err != nil {
+		panic(contextDone{err})
+	}
+}
+
+// compareContext is identical to compareAny, except that it recovers from
+// the traversal being unwound by checkContext and records the error,
+// or by checkStop. It reports whether the comparison was completed.
+func (s *state) compareContext(step PathStep) (ok bool) {
+	if s.ctx == nil && !s.stopAtDiff && s.maxDiffs == 0 {
+		s.compareAny(step)
+		return true
+	}
+	defer func() {
+		if ex := recover(); ex != nil {
+			switch ex := ex.(type) {
+			case contextDone:
+				s.ctxErr = ex.err
+			case traversalStopped:
+				s.stopped = true
+			default:
+				panic(ex)
+			}
+		}
+	}()
+	s.compareAny(step)
+	return true
+}
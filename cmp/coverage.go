@@ -0,0 +1,154 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Coverage is a record of the nodes in the value tree that were visited by
+// one or more calls to Equal or Diff. It can be used to verify that a test
+// actually compares the fields that it is expected to compare, and that
+// filters are not unintentionally excluding whole subtrees.
+//
+// A node is visited if it or any node beneath it is compared or ignored as
+// part of the final comparison, such that a composite value with nothing to
+// compare, such as an empty slice, is not visited. Nodes only inspected while
+// searching for a good alignment of slice elements are not considered to have
+// been visited.
+//
+// A Coverage also records the nodes whose sub-values were skipped because of
+// an Ignore option or because a Transformer compared them in another form,
+// which serves as a safety net against overly broad options that
+// hide regressions.
+//
+// The zero value is ready for use. A Coverage is safe for concurrent use.
+type Coverage struct {
+	mu          sync.Mutex
+	paths       map[string]bool       // Set of Path.GoString
+	names       map[string]bool       // Set of Path.String
+	types       map[reflect.Type]bool // Set of Path.Last().Type
+	ignored     map[string]bool       // Set of Path.GoString for ignored nodes
+	transformed map[string]bool       // Set of Path.GoString for transformed nodes
+}
+
+// RecordCoverage returns an Option that records every node visited while
+// comparing into c.
+func RecordCoverage(c *Coverage) Option {
+	if c == nil {
+		panic("invalid nil Coverage")
+	}
+	return reporter(&coverageReporter{c: c})
+}
+
+// Paths returns the GoString of the Path to every visited node,
+// sorted in lexicographical order.
+func (c *Coverage) Paths() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedKeys(c.paths)
+}
+
+// Ignored returns the GoString of the Path to every node that was ignored
+// by an Ignore option, sorted in lexicographical order.
+// None of the sub-values of an ignored node are visited.
+func (c *Coverage) Ignored() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedKeys(c.ignored)
+}
+
+// Transformed returns the GoString of the Path to every node that was
+// compared by applying a Transformer, sorted in lexicographical order.
+// The sub-values of a transformed node are only visited in their
+// transformed form, if at all.
+func (c *Coverage) Transformed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedKeys(c.transformed)
+}
+
+// Types returns the Type of every visited node,
+// sorted by the string representation of each type.
+func (c *Coverage) Types() []reflect.Type {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var ts []reflect.Type
+	for t := range c.types {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].String() < ts[j].String() })
+	return ts
+}
+
+// Visited reports whether a node with the given Path was visited,
+// where p is either the GoString or the String of a Path.
+func (c *Coverage) Visited(p string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paths[p] || c.names[p]
+}
+
+func (c *Coverage) visit(p Path) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paths == nil {
+		c.paths = make(map[string]bool)
+		c.names = make(map[string]bool)
+		c.types = make(map[reflect.Type]bool)
+	}
+	c.paths[p.GoString()] = true
+	c.names[p.String()] = true
+	if t := p.Last().Type(); t != nil {
+		c.types[t] = true
+	}
+}
+
+func (c *Coverage) ignore(p Path) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ignored == nil {
+		c.ignored = make(map[string]bool)
+	}
+	c.ignored[p.GoString()] = true
+}
+
+func (c *Coverage) transform(p Path) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transformed == nil {
+		c.transformed = make(map[string]bool)
+	}
+	c.transformed[p.GoString()] = true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var ss []string
+	for s := range m {
+		ss = append(ss, s)
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// coverageReporter records the nodes of the Path of every reported leaf,
+// which includes all of its ancestors.
+type coverageReporter struct{ c *Coverage }
+
+func (*coverageReporter) PushStep(PathStep) {}
+func (r *coverageReporter) Report(p Path, f reportFlags) {
+	for i := range p {
+		if _, ok := p[i].(*transform); ok && i > 0 {
+			r.c.transform(p[:i])
+		}
+		r.c.visit(p[:i+1])
+	}
+	if f&reportIgnored > 0 {
+		r.c.ignore(p)
+	}
+}
+func (*coverageReporter) PopStep() {}
@@ -0,0 +1,68 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "reflect"
+
+// MaxDepth returns an Option that stops the comparison from descending into
+// nodes more than n steps below the root, which guards against exhausting
+// the stack when comparing deeply nested or adversarial values.
+// Every step in the Path counts towards the depth, including pointer
+// indirections and type assertions.
+//
+// Options, Equal methods, and registered surrogates still apply to the nodes
+// at the maximum depth. Otherwise, a struct, array, or interface is compared
+// by the == operator if its type is comparable, while a pointer, slice, or map
+// is compared by whether both values reference the same memory.
+// Any other node is reported as unequal, as is a node whose dynamic values
+// cannot be compared by ==. Reporters observe such nodes through
+// Result.ByDepth.
+func MaxDepth(n int) Option {
+	if n <= 0 {
+		panic("maximum depth must be a positive number")
+	}
+	return maxDepthOption(n)
+}
+
+type maxDepthOption int
+
+func (maxDepthOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+// tryMaxDepth compares the current node by shallowEqual, rather than
+// descending into it, if it is a composite node at the maximum depth.
+func (s *state) tryMaxDepth(t reflect.Type, vx, vy reflect.Value) bool {
+	if s.maxDepth <= 0 || len(s.curPath) <= s.maxDepth {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr, reflect.Interface:
+		s.report(shallowEqual(t, vx, vy), reportByDepth)
+		return true
+	}
+	return false
+}
+
+// shallowEqual reports whether vx and vy are equal without descending into
+// them, as documented by MaxDepth.
+func shallowEqual(t reflect.Type, vx, vy reflect.Value) (eq bool) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return vx.Pointer() == vy.Pointer()
+	case reflect.Slice:
+		return vx.IsNil() == vy.IsNil() && vx.Pointer() == vy.Pointer() && vx.Len() == vy.Len()
+	}
+	if !t.Comparable() || !vx.CanInterface() || !vy.CanInterface() {
+		return false
+	}
+	// The == operator panics for dynamic values of incomparable types.
+	defer func() {
+		if recover() != nil {
+			eq = false
+		}
+	}()
+	return vx.Interface() == vy.Interface()
+}
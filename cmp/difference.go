@@ -0,0 +1,66 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import "reflect"
+
+// Difference is a single node in the value tree reported by DiffResult.
+type Difference struct {
+	// Path is the path from the root to the node.
+	// Unlike the Path passed to a reporter, it remains valid indefinitely.
+	Path Path
+
+	// X and Y are the values of the node in x and y, respectively.
+	// A value is invalid if the node does not exist in that value,
+	// such as for a slice element that was inserted or removed.
+	X, Y reflect.Value
+
+	// Ignored reports whether the node was skipped by an Ignore option,
+	// rather than being determined to be unequal.
+	Ignored bool
+}
+
+// Edit reports how the node differs between x and y.
+func (d Difference) Edit() EditKind {
+	return d.Path.Edit()
+}
+
+// DiffResult is identical to Diff, except that it returns the differences
+// between x and y as a list of nodes for programmatic consumption,
+// rather than as a human-readable report. The list contains every node in the
+// value tree that is unequal, and every node that was ignored, in the order
+// that they were compared. Thus, x and y are equal if and only if every
+// Difference in the list is Ignored. Each node is a leaf of the value tree,
+// whose sub-values (if any) were not compared.
+func DiffResult(x, y interface{}, opts ...Option) []Difference {
+	s := newState(opts)
+	r := new(differenceReporter)
+	s.reporters = append(s.reporters, reporterOption{r})
+	s.compareRoot(x, y)
+	return r.diffs
+}
+
+type differenceReporter struct {
+	diffs []Difference
+}
+
+func (r *differenceReporter) PushStep(PathStep) {}
+func (r *differenceReporter) Report(p Path, f reportFlags) {
+	if f&(reportUnequal|reportIgnored) == 0 {
+		return
+	}
+	r.diffs = append(r.diffs, newDifference(p, f))
+}
+func (r *differenceReporter) PopStep() {}
+
+// newDifference returns the Difference for the node reported with flags f.
+func newDifference(p Path, f reportFlags) Difference {
+	d := Difference{Path: make(Path, len(p)), Ignored: f&reportIgnored > 0}
+	for i, ps := range p {
+		d.Path[i] = copyStep(ps)
+	}
+	d.X, d.Y = p.Last().Values()
+	return d
+}
@@ -98,26 +98,21 @@ func ExampleDiff_testing() {
 
 	// Output:
 	// add to empty: after AddCrew, manifest differs: (-want +got)
-	// {*cmp_test.ShipManifest}.Crew["Galactic President"]:
+	// {*cmp_test.ShipManifest}.Crew["Galactic President"] (entry removed):
 	// 	-: "Zaphod Beeblebrox"
-	// 	+: <non-existent>
-	// {*cmp_test.ShipManifest}.Crew["Zaphod Beeblebrox"]:
-	// 	-: <non-existent>
+	// {*cmp_test.ShipManifest}.Crew["Zaphod Beeblebrox"] (entry added):
 	// 	+: "Galactic President"
 	//
 	// add another: after AddCrew, manifest differs: (-want +got)
-	// {*cmp_test.ShipManifest}.Crew["Human"]:
+	// {*cmp_test.ShipManifest}.Crew["Human"] (entry removed):
 	// 	-: "Trillian"
-	// 	+: <non-existent>
-	// {*cmp_test.ShipManifest}.Crew["Trillian"]:
-	// 	-: <non-existent>
+	// {*cmp_test.ShipManifest}.Crew["Trillian"] (entry added):
 	// 	+: "Human"
 	//
 	// overwrite: after AddCrew, manifest differs: (-want +got)
-	// {*cmp_test.ShipManifest}.Crew["Just this guy, you know?"]:
+	// {*cmp_test.ShipManifest}.Crew["Just this guy, you know?"] (entry removed):
 	// 	-: "Zaphod Beeblebrox"
-	// 	+: <non-existent>
-	// {*cmp_test.ShipManifest}.Crew["Zaphod Beeblebrox"]:
+	// {*cmp_test.ShipManifest}.Crew["Zaphod Beeblebrox"] (entry modified):
 	// 	-: "Galactic President"
 	// 	+: "Just this guy, you know?"
 }
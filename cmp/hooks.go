@@ -0,0 +1,53 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+// Hooks returns an Option that calls before as Equal descends into each node
+// in the value tree and after as it finishes comparing the node, where equal
+// reports whether no differences were found at or beneath the node.
+// Ignored nodes are reported as equal. Either function may be nil.
+//
+// This allows for custom instrumentation of a comparison, such as
+// progress reporting for very large values or checking side-conditions on
+// the values being compared, without implementing a complete reporter.
+// Nodes only inspected while searching for a good alignment of slice elements
+// are not visited. As with every Path passed to a function, the Path must not
+// be retained after the call without copying its steps.
+func Hooks(before func(Path), after func(p Path, equal bool)) Option {
+	if before == nil && after == nil {
+		panic("invalid nil hook functions")
+	}
+	return reporter(&hookReporter{before: before, after: after})
+}
+
+type hookReporter struct {
+	before func(Path)
+	after  func(Path, bool)
+	path   Path
+	equal  []bool // Whether each node in path is equal so far
+}
+
+func (r *hookReporter) PushStep(ps PathStep) {
+	r.path = append(r.path, ps)
+	r.equal = append(r.equal, true)
+	if r.before != nil {
+		r.before(r.path)
+	}
+}
+func (r *hookReporter) Report(_ Path, f reportFlags) {
+	if f&reportUnequal > 0 {
+		r.equal[len(r.equal)-1] = false
+	}
+}
+func (r *hookReporter) PopStep() {
+	last := len(r.path) - 1
+	if r.after != nil {
+		r.after(r.path, r.equal[last])
+	}
+	if !r.equal[last] && last > 0 {
+		r.equal[last-1] = false
+	}
+	r.path, r.equal = r.path[:last], r.equal[:last]
+}
@@ -20,15 +20,27 @@ const (
 	ttbFunc // func(T, T) bool
 	tibFunc // func(T, I) bool
 	trFunc  // func(T) R
+	ttiFunc // func(T, T) int
+	tbFunc  // func(T) bool
+	trbFunc // func(T, R) bool
+	tsFunc  // func(T) string
 
-	Equal           = ttbFunc // func(T, T) bool
-	EqualAssignable = tibFunc // func(T, I) bool; encapsulates func(T, T) bool
-	Transformer     = trFunc  // func(T) R
-	ValueFilter     = ttbFunc // func(T, T) bool
-	Less            = ttbFunc // func(T, T) bool
+	Equal             = ttbFunc // func(T, T) bool
+	EqualAssignable   = tibFunc // func(T, I) bool; encapsulates func(T, T) bool
+	Transformer       = trFunc  // func(T) R
+	ValueFilter       = ttbFunc // func(T, T) bool
+	Less              = ttbFunc // func(T, T) bool
+	Compare           = ttiFunc // func(T, T) int
+	ValuePredicate    = tbFunc  // func(T) bool
+	KeyValuePredicate = trbFunc // func(T, R) bool
+	Formatter         = tsFunc  // func(T) string
 )
 
-var boolType = reflect.TypeOf(true)
+var (
+	boolType   = reflect.TypeOf(true)
+	intType    = reflect.TypeOf(0)
+	stringType = reflect.TypeOf("")
+)
 
 // IsType reports whether the reflect.Type is of the specified function type.
 func IsType(t reflect.Type, ft funcType) bool {
@@ -49,11 +61,39 @@ func IsType(t reflect.Type, ft funcType) bool {
 		if ni == 1 && no == 1 {
 			return true
 		}
+	case ttiFunc: // func(T, T) int
+		if ni == 2 && no == 1 && t.In(0) == t.In(1) && t.Out(0) == intType {
+			return true
+		}
+	case tbFunc: // func(T) bool
+		if ni == 1 && no == 1 && t.Out(0) == boolType {
+			return true
+		}
+	case trbFunc: // func(T, R) bool
+		if ni == 2 && no == 1 && t.Out(0) == boolType {
+			return true
+		}
+	case tsFunc: // func(T) string
+		if ni == 1 && no == 1 && t.Out(0) == stringType {
+			return true
+		}
 	}
 	return false
 }
 
-var lastIdentRx = regexp.MustCompile(`[_\p{L}][_\p{L}\p{N}]*$`)
+// identRx represents a valid identifier according to the Go specification.
+const identRx = `[_\p{L}][_\p{L}\p{N}]*`
+
+var (
+	lastIdentRx = regexp.MustCompile(identRx + `$`)
+	identsRx    = regexp.MustCompile(`^` + identRx + `(\.` + identRx + `)*$`)
+)
+
+// IsIdent reports whether name is a valid identifier or qualified identifier
+// according to the Go specification.
+func IsIdent(name string) bool {
+	return identsRx.MatchString(name)
+}
 
 // NameOf returns the name of the function value.
 func NameOf(v reflect.Value) string {
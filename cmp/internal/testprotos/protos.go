@@ -114,3 +114,21 @@ type (
 		Stringer
 	}
 )
+
+// Well-known protocol buffers
+type (
+	Timestamp struct {
+		proto
+		Seconds int64
+		Nanos   int32
+	}
+	Duration struct {
+		proto
+		Seconds int64
+		Nanos   int32
+	}
+	Int64Value  struct{ Value int64 }
+	StringValue struct{ Value string }
+	BoolValue   struct{ Value bool }
+	BytesValue  struct{ Value []byte }
+)
@@ -13,7 +13,10 @@ import (
 	"unicode"
 )
 
-var stringerIface = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+var (
+	stringerIface = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorIface    = reflect.TypeOf((*error)(nil)).Elem()
+)
 
 // Format formats the value v as a string.
 //
@@ -30,11 +33,33 @@ func Format(v reflect.Value, conf FormatConfig) string {
 }
 
 type FormatConfig struct {
-	UseStringer        bool // Should the String method be used if available?
-	printType          bool // Should we print the type before the value?
-	PrintPrimitiveType bool // Should we print the type of primitives?
-	followPointers     bool // Should we recursively follow pointers?
-	realPointers       bool // Should we print the real address of pointers?
+	UseStringer        bool   // Should the String method be used if available?
+	UseError           bool   // Should the Error method be used if available?
+	ErrorDetails       bool   // Should errors be printed structurally in addition to using the Error method?
+	GroupDigits        bool   // Should decimal integers have digits grouped by underscores?
+	PlainFloatRange    [2]int // If non-empty, decimal exponents of floats printed without an exponent
+	printType          bool   // Should we print the type before the value?
+	PrintPrimitiveType bool   // Should we print the type of primitives?
+	PrintNestedTypes   bool   // Should we print the type of every nested value?
+	followPointers     bool   // Should we recursively follow pointers?
+	realPointers       bool   // Should we print the real address of pointers?
+	skipMethods        bool   // Should the Error and String methods of this value be skipped?
+
+	// FieldTag is the key of the struct tag, if any, whose names are used in
+	// place of the Go names of struct fields.
+	FieldTag string
+
+	// EnumNames maps integer types to tables of names for their values,
+	// where each table is a map[T]string.
+	EnumNames map[reflect.Type]reflect.Value
+
+	// Formatters maps types to functions that format their values,
+	// where each function is a func(T) string.
+	Formatters map[reflect.Type]reflect.Value
+
+	// FormatHooks are functions consulted in order to format any value,
+	// where the first to report true provides the formatted string.
+	FormatHooks []func(interface{}) (string, bool)
 }
 
 func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
@@ -43,28 +68,94 @@ func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
 	if !v.IsValid() {
 		return "<non-existent>"
 	}
-	if conf.UseStringer && v.Type().Implements(stringerIface) && v.CanInterface() {
+	if f, ok := conf.Formatters[v.Type()]; ok && v.CanInterface() {
+		s, ex := callString(func() string { return f.Call([]reflect.Value{v})[0].String() })
+		if ex == nil {
+			return s
+		}
+		subConf := conf
+		subConf.Formatters = nil
+		return fmt.Sprintf("%s (Formatter panicked: %v)", formatAny(v, subConf, m), ex)
+	}
+	if len(conf.FormatHooks) > 0 && v.Kind() != reflect.Interface && v.CanInterface() {
+		for _, f := range conf.FormatHooks {
+			var ok bool
+			s, ex := callString(func() (s string) { s, ok = f(v.Interface()); return s })
+			if ex != nil {
+				subConf := conf
+				subConf.FormatHooks = nil
+				return fmt.Sprintf("%s (FormatValue function panicked: %v)", formatAny(v, subConf, m), ex)
+			}
+			if ok {
+				return s
+			}
+		}
+	}
+	if names, ok := conf.EnumNames[v.Type()]; ok {
+		if s, ok := formatEnum(v, names, conf); ok {
+			return s
+		}
+	}
+	if conf.UseError && !conf.skipMethods && v.Type().Implements(errorIface) && v.CanInterface() {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return "<nil>"
+		}
+
+		const errorPrefix = "e" // Indicates that the Error method was used
+		subConf := conf
+		subConf.skipMethods = true
+		s, ex := callString(v.Interface().(error).Error)
+		switch {
+		case ex != nil:
+			return fmt.Sprintf("%s (Error method panicked: %v)", formatAny(v, subConf, m), ex)
+		case conf.ErrorDetails:
+			return fmt.Sprintf("%s%s %s", errorPrefix, formatString(s), formatAny(v, subConf, m))
+		default:
+			return errorPrefix + formatString(s)
+		}
+	}
+	if conf.UseStringer && !conf.skipMethods && v.Type().Implements(stringerIface) && v.CanInterface() {
 		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
 			return "<nil>"
 		}
 
 		const stringerPrefix = "s" // Indicates that the String method was used
-		s := v.Interface().(fmt.Stringer).String()
-		return stringerPrefix + formatString(s)
+		s, ex := callString(v.Interface().(fmt.Stringer).String)
+		if ex == nil {
+			return stringerPrefix + formatString(s)
+		}
+
+		// A panic while formatting must not mask the actual difference,
+		// so fall back to formatting the value structurally.
+		subConf := conf
+		subConf.skipMethods = true
+		return fmt.Sprintf("%s (String method panicked: %v)", formatAny(v, subConf, m), ex)
+	}
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		conf.skipMethods = false // Only skip up to the underlying value
 	}
 
 	switch v.Kind() {
 	case reflect.Bool:
 		return formatPrimitive(v.Type(), v.Bool(), conf)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if conf.GroupDigits {
+			return formatPrimitive(v.Type(), groupDigits(strconv.FormatInt(v.Int(), 10)), conf)
+		}
 		return formatPrimitive(v.Type(), v.Int(), conf)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		if v.Type().PkgPath() == "" || v.Kind() == reflect.Uintptr {
 			// Unnamed uints are usually bytes or words, so use hexadecimal.
 			return formatPrimitive(v.Type(), formatHex(v.Uint()), conf)
 		}
+		if conf.GroupDigits {
+			return formatPrimitive(v.Type(), groupDigits(strconv.FormatUint(v.Uint(), 10)), conf)
+		}
 		return formatPrimitive(v.Type(), v.Uint(), conf)
 	case reflect.Float32, reflect.Float64:
+		if r := conf.PlainFloatRange; r[0] < r[1] {
+			return formatPrimitive(v.Type(), formatFloat(v.Float(), v.Type().Bits(), r), conf)
+		}
 		return formatPrimitive(v.Type(), v.Float(), conf)
 	case reflect.Complex64, reflect.Complex128:
 		return formatPrimitive(v.Type(), v.Complex(), conf)
@@ -102,7 +193,7 @@ func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
 	case reflect.Array:
 		var ss []string
 		subConf := conf
-		subConf.printType = v.Type().Elem().Kind() == reflect.Interface
+		subConf.printType = conf.PrintNestedTypes || v.Type().Elem().Kind() == reflect.Interface
 		for i := 0; i < v.Len(); i++ {
 			vi := v.Index(i)
 			if vi.CanAddr() { // Check for recursive elements
@@ -134,10 +225,12 @@ func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
 
 		var ss []string
 		keyConf, valConf := conf, conf
-		keyConf.printType = v.Type().Key().Kind() == reflect.Interface
+		keyConf.printType = conf.PrintNestedTypes || v.Type().Key().Kind() == reflect.Interface
 		keyConf.followPointers = false
-		valConf.printType = v.Type().Elem().Kind() == reflect.Interface
-		for _, k := range SortKeys(v.MapKeys()) {
+		valConf.printType = conf.PrintNestedTypes || v.Type().Elem().Kind() == reflect.Interface
+		// Pointers are not followed to order the keys of nested maps,
+		// since that would recurse without end on cyclic values.
+		for _, k := range sortKeys(v.MapKeys(), conf.followPointers) {
 			sk := formatAny(k, keyConf, m)
 			sv := formatAny(v.MapIndex(k), valConf, m)
 			ss = append(ss, fmt.Sprintf("%s: %s", sk, sv))
@@ -157,6 +250,11 @@ func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
 				continue // Elide zero value fields
 			}
 			name := v.Type().Field(i).Name
+			if conf.FieldTag != "" {
+				if tn := FieldTagName(v.Type().Field(i), conf.FieldTag); tn != "" {
+					name = tn
+				}
+			}
 			subConf.UseStringer = conf.UseStringer
 			s := formatAny(vv, subConf, m)
 			ss = append(ss, fmt.Sprintf("%s: %s", name, s))
@@ -171,6 +269,27 @@ func formatAny(v reflect.Value, conf FormatConfig, m visited) string {
 	}
 }
 
+// FieldTagName returns the name of the struct field f according to the
+// struct tag with the given key (e.g., "json"), where the name is the part of
+// the tag value before the first comma. It returns the empty string if the tag
+// does not specify a name or if it is "-".
+func FieldTagName(f reflect.StructField, key string) string {
+	name := f.Tag.Get(key)
+	if i := strings.IndexByte(name, ','); i >= 0 {
+		name = name[:i]
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// callString calls the Error or String method f, recovering from any panic.
+func callString(f func() string) (s string, ex interface{}) {
+	defer func() { ex = recover() }()
+	return f(), nil
+}
+
 func formatString(s string) string {
 	// Use quoted string if it the same length as a raw string literal.
 	// Otherwise, attempt to use the raw string form.
@@ -190,6 +309,60 @@ func formatString(s string) string {
 	return qs
 }
 
+// formatEnum formats the integer v as its name in the names table followed
+// by its number (e.g., "StatusActive (2)"). It reports false if v has no name.
+func formatEnum(v reflect.Value, names reflect.Value, conf FormatConfig) (string, bool) {
+	// Copy v to a new value since it may have been derived from
+	// an unexported field, which cannot be used to index the table.
+	k := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		k.SetInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		k.SetUint(v.Uint())
+	default:
+		return "", false
+	}
+	name := names.MapIndex(k)
+	if !name.IsValid() {
+		return "", false
+	}
+	conf.EnumNames = nil
+	conf.printType = false
+	return fmt.Sprintf("%s (%s)", name.String(), formatAny(v, conf, nil)), true
+}
+
+// groupDigits inserts an underscore between every group of three digits
+// in the decimal integer s, counting from the right (e.g., "-1_234_567").
+func groupDigits(s string) string {
+	var sign string
+	if s != "" && s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	var b []byte
+	for i := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b = append(b, '_')
+		}
+		b = append(b, s[i])
+	}
+	return sign + string(b)
+}
+
+// formatFloat formats f using the shortest representation, where the value
+// is printed without an exponent only if its decimal exponent is within r.
+func formatFloat(f float64, bits int, r [2]int) string {
+	s := strconv.FormatFloat(f, 'e', -1, bits)
+	i := strings.LastIndexByte(s, 'e')
+	if i < 0 {
+		return s // NaN or infinity
+	}
+	if exp, _ := strconv.Atoi(s[i+1:]); exp < r[0] || exp >= r[1] {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', -1, bits)
+}
+
 func formatPrimitive(t reflect.Type, v interface{}, conf FormatConfig) string {
 	if conf.printType && (conf.PrintPrimitiveType || t.PkgPath() != "") {
 		return fmt.Sprintf("%v(%v)", t, v)
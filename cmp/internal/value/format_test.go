@@ -6,7 +6,10 @@ package value
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -91,3 +94,227 @@ func TestFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatGroupDigits(t *testing.T) {
+	type myUint uint
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   0,
+		want: "0",
+	}, {
+		in:   123,
+		want: "123",
+	}, {
+		in:   1234,
+		want: "1_234",
+	}, {
+		in:   -1234567,
+		want: "-1_234_567",
+	}, {
+		in:   myUint(1000000),
+		want: "1_000_000",
+	}, {
+		in:   uint(1000000),
+		want: "0x0f4240",
+	}, {
+		in:   []int64{9999999999, 10000000000},
+		want: "{9_999_999_999, 10_000_000_000}",
+	}}
+
+	for i, tt := range tests {
+		got := formatAny(reflect.ValueOf(tt.in), FormatConfig{GroupDigits: true}, visited{})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPlainFloatRange(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   1.0,
+		want: "1",
+	}, {
+		in:   1.0000000000000002e-07,
+		want: "0.00000010000000000000002",
+	}, {
+		in:   float32(0.00125),
+		want: "0.00125",
+	}, {
+		in:   1e-10,
+		want: "1e-10",
+	}, {
+		in:   123456789.0,
+		want: "1.23456789e+08",
+	}, {
+		in:   math.Inf(-1),
+		want: "-Inf",
+	}}
+
+	for i, tt := range tests {
+		got := formatAny(reflect.ValueOf(tt.in), FormatConfig{PlainFloatRange: [2]int{-9, 6}}, visited{})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNestedTypes(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   5,
+		want: "int(5)",
+	}, {
+		in:   []int{1, 2},
+		want: "[]int{int(1), int(2)}",
+	}, {
+		in:   map[string]bool{"a": true},
+		want: `map[string]bool{string("a"): bool(true)}`,
+	}, {
+		in:   struct{ A []uint8 }{[]uint8{1}},
+		want: "struct { A []uint8 }{A: []uint8{uint8(0x01)}}",
+	}}
+
+	for i, tt := range tests {
+		got := Format(reflect.ValueOf(tt.in), FormatConfig{PrintPrimitiveType: true, PrintNestedTypes: true})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+type panicStringer struct{ A int }
+
+func (panicStringer) String() string { panic("boom") }
+
+func TestFormatPanickingStringer(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   panicStringer{5},
+		want: "value.panicStringer{A: 5} (String method panicked: boom)",
+	}, {
+		in:   []panicStringer{{1}},
+		want: "[]value.panicStringer{{A: 1} (String method panicked: boom)}",
+	}, {
+		in:   &panicStringer{2},
+		want: "&value.panicStringer{A: 2} (String method panicked: boom)",
+	}}
+
+	for i, tt := range tests {
+		got := Format(reflect.ValueOf(tt.in), FormatConfig{UseStringer: true})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+type panicError struct{}
+
+func (*panicError) Error() string { panic("boom") }
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		details bool
+		want    string
+	}{{
+		in:   errors.New("EOF"),
+		want: `e"EOF"`,
+	}, {
+		in:      errors.New("EOF"),
+		details: true,
+		want:    `e"EOF" &errors.errorString{s: "EOF"}`,
+	}, {
+		in:   []error{nil, io.ErrUnexpectedEOF},
+		want: `[]error{<nil>, e"unexpected EOF"}`,
+	}, {
+		in:   &panicError{},
+		want: "&value.panicError{} (Error method panicked: boom)",
+	}}
+
+	for i, tt := range tests {
+		got := Format(reflect.ValueOf(tt.in), FormatConfig{UseError: true, ErrorDetails: tt.details})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFormatters(t *testing.T) {
+	type id int
+	formatters := map[reflect.Type]reflect.Value{
+		reflect.TypeOf(id(0)): reflect.ValueOf(func(v id) string {
+			if v < 0 {
+				panic("negative")
+			}
+			return fmt.Sprintf("#%d", int(v))
+		}),
+	}
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   id(5),
+		want: "#5",
+	}, {
+		in:   []id{1, 2},
+		want: "[]value.id{#1, #2}",
+	}, {
+		in:   map[id]int{3: 3},
+		want: "map[value.id]int{#3: 3}",
+	}, {
+		in:   id(-1),
+		want: "value.id(-1) (Formatter panicked: negative)",
+	}}
+
+	for i, tt := range tests {
+		got := Format(reflect.ValueOf(tt.in), FormatConfig{Formatters: formatters})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestFormatHooks(t *testing.T) {
+	hooks := []func(interface{}) (string, bool){
+		func(v interface{}) (string, bool) {
+			s, ok := v.(string)
+			if ok && s == "boom" {
+				panic("boom")
+			}
+			return "<" + s + ">", ok
+		},
+		func(v interface{}) (string, bool) {
+			_, ok := v.(string)
+			return "unreachable", ok
+		},
+	}
+	tests := []struct {
+		in   interface{}
+		want string
+	}{{
+		in:   "a",
+		want: "<a>",
+	}, {
+		in:   []interface{}{"b", 1, nil},
+		want: "[]interface {}{<b>, 1, interface {}(nil)}",
+	}, {
+		in:   "boom",
+		want: `"boom" (FormatValue function panicked: boom)`,
+	}}
+
+	for i, tt := range tests {
+		got := Format(reflect.ValueOf(tt.in), FormatConfig{FormatHooks: hooks})
+		if got != tt.want {
+			t.Errorf("test %d, Format():\ngot  %q\nwant %q", i, got, tt.want)
+		}
+	}
+}
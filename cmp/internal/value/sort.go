@@ -13,24 +13,70 @@ import (
 
 // SortKeys sorts a list of map keys, deduplicating keys if necessary.
 // The type of each value must be comparable.
+//
+// Pointer keys are ordered by the values they point to, rather than by
+// their addresses, such that the order is the same in every run of a program.
+// Distinct pointers to equal values are ordered by address.
 func SortKeys(vs []reflect.Value) []reflect.Value {
+	return sortKeys(vs, true)
+}
+
+// sortKeys sorts a list of map keys, where pointer keys are ordered by
+// their pointees only if byPointee is set.
+func sortKeys(vs []reflect.Value, byPointee bool) []reflect.Value {
 	if len(vs) == 0 {
 		return vs
 	}
 
 	// Sort the map keys.
-	sort.Slice(vs, func(i, j int) bool { return isLess(vs[i], vs[j]) })
+	if byPointee && vs[0].Kind() == reflect.Ptr {
+		sortPointers(vs)
+	} else {
+		sort.Slice(vs, func(i, j int) bool { return isLess(vs[i], vs[j]) })
+	}
 
 	// Deduplicate keys (fails for NaNs).
 	vs2 := vs[:1]
 	for _, v := range vs[1:] {
-		if isLess(vs2[len(vs2)-1], v) {
+		if isLess(vs2[len(vs2)-1], v) || isLess(v, vs2[len(vs2)-1]) {
 			vs2 = append(vs2, v)
 		}
 	}
 	return vs2
 }
 
+// sortPointers sorts a list of pointers by the values they point to,
+// and then by address. Each pointee is formatted only once and without
+// following any further pointers, so that cyclic values are formatted
+// in bounded time.
+func sortPointers(vs []reflect.Value) {
+	ss := make([]string, len(vs))
+	for i, v := range vs {
+		if !v.IsNil() {
+			conf := FormatConfig{printType: true}
+			ss[i] = formatAny(v.Elem(), conf, visited{})
+		}
+	}
+	sort.Sort(pointerSorter{vs, ss})
+}
+
+type pointerSorter struct {
+	vs []reflect.Value
+	ss []string // Formatted pointee of each pointer
+}
+
+func (s pointerSorter) Len() int { return len(s.vs) }
+func (s pointerSorter) Less(i, j int) bool {
+	if s.ss[i] != s.ss[j] {
+		return s.ss[i] < s.ss[j]
+	}
+	return s.vs[i].Pointer() < s.vs[j].Pointer()
+}
+func (s pointerSorter) Swap(i, j int) {
+	s.vs[i], s.vs[j] = s.vs[j], s.vs[i]
+	s.ss[i], s.ss[j] = s.ss[j], s.ss[i]
+}
+
 // isLess is a generic function for sorting arbitrary map keys.
 // The inputs must be of the same type and must be comparable.
 func isLess(x, y reflect.Value) bool {
@@ -102,3 +148,27 @@ func isLess(x, y reflect.Value) bool {
 		panic(fmt.Sprintf("%T is not comparable", x.Type()))
 	}
 }
+
+// IsHashable reports whether values of type t identify themselves when used
+// as map keys. Floating-point values do not, since the zero value equals
+// negative zero and NaN equals nothing, and neither do interface values,
+// whose dynamic values may not be usable as map keys.
+func IsHashable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return IsHashable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !IsHashable(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
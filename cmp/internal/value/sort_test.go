@@ -157,3 +157,43 @@ func TestSortKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestSortKeysPointers(t *testing.T) {
+	type K struct{ A int }
+	var ks []*K
+	for _, a := range []int{3, 1, 2, 1} {
+		ks = append(ks, &K{a})
+	}
+
+	// Pointers are ordered by the values they point to, regardless of
+	// the order in which they were allocated.
+	m := map[*K]bool{ks[0]: true, ks[1]: true, ks[2]: true, ks[3]: true, nil: true}
+	v := reflect.ValueOf(m)
+	var got []int
+	for _, k := range value.SortKeys(append(v.MapKeys(), v.MapKeys()...)) {
+		if k.IsNil() {
+			got = append(got, 0)
+			continue
+		}
+		got = append(got, k.Interface().(*K).A)
+	}
+	if want := []int{0, 1, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestSortKeysCycles(t *testing.T) {
+	type Node struct{ M map[*Node]bool }
+	a, b := &Node{}, &Node{}
+	a.M = map[*Node]bool{a: true, b: true}
+	b.M = map[*Node]bool{a: true, b: true}
+
+	// Formatting the pointees must not follow the cycle back to the keys.
+	v := reflect.ValueOf(a.M)
+	if got := value.SortKeys(v.MapKeys()); len(got) != 2 {
+		t.Errorf("SortKeys() returned %d keys, want 2", len(got))
+	}
+	if s := value.Format(reflect.ValueOf(a), value.FormatConfig{}); s == "" {
+		t.Errorf("Format() = %q, want non-empty", s)
+	}
+}
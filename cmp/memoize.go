@@ -0,0 +1,106 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// NoTransformerCache returns an Option that disables the caching of
+// Transformer outputs within a single comparison.
+//
+// By default, the output of a Transformer is cached for the duration of
+// a comparison, such that a Transformer applied to the same input more than
+// once (e.g., to repeated substructures, or to both x and y when they share
+// a sub-value) is only called once for that input. Pointers, slices, and maps
+// are identified by their address (and the length of slices), while other
+// values are identified by their contents if they are only composed of
+// booleans, integers, strings, pointers, and channels.
+// Since Transformer functions must already be deterministic and pure,
+// this option is only needed for functions whose output depends on state
+// other than their input, such as the contents of a file.
+func NoTransformerCache() Option {
+	return noTransformerCache{}
+}
+
+type noTransformerCache struct{}
+
+func (noTransformerCache) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+// transformKey identifies the input to a transformer.
+type transformKey struct {
+	tr  *transformer
+	ptr value.Pointer // Address of a pointer, slice, or map input
+	len int           // Length of a slice input
+	v   interface{}   // Any other input, if it can be used as a map key
+}
+
+// callTransformer is identical to callTRFunc, except that the output is
+// cached for each input that can be identified by a transformKey.
+func (s *state) callTransformer(tr *transformer, v reflect.Value, step *transform) reflect.Value {
+	if s.noTrCache || s.isTransforming(tr) {
+		return s.callTRFunc(tr.fnc, v, step)
+	}
+	k, ok := makeTransformKey(tr, v)
+	if !ok {
+		return s.callTRFunc(tr.fnc, v, step)
+	}
+	if out, ok := s.transformed[k]; ok {
+		return out
+	}
+	out := s.callTRFunc(tr.fnc, v, step)
+	if s.transformed == nil {
+		s.transformed = make(map[transformKey]reflect.Value)
+	}
+	s.transformed[k] = out
+	return out
+}
+
+// isTransforming reports whether tr was applied to an ancestor of the current
+// node. The outputs of such recursively applied transformers are not cached,
+// since a cached output nested within itself would appear to be a cycle,
+// rather than the recursion being detected by recChecker.
+func (s *state) isTransforming(tr *transformer) bool {
+	for _, ps := range s.curPath {
+		if t, ok := ps.(*transform); ok && t.trans == tr {
+			return true
+		}
+	}
+	return false
+}
+
+// makeTransformKey returns the transformKey for applying tr to v.
+// It reports false if v cannot be identified.
+func makeTransformKey(tr *transformer, v reflect.Value) (transformKey, bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return transformKey{}, false
+		}
+		v = v.Elem()
+	}
+	k := transformKey{tr: tr}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			return transformKey{}, false
+		}
+		k.ptr = value.PointerOf(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return transformKey{}, false
+		}
+		k.ptr, k.len = value.PointerOf(v), v.Len()
+	default:
+		if !v.CanInterface() || !value.IsHashable(v.Type()) {
+			return transformKey{}, false
+		}
+		k.v = v.Interface()
+	}
+	return k, true
+}
@@ -7,7 +7,6 @@ package cmp
 import (
 	"fmt"
 	"reflect"
-	"regexp"
 	"strings"
 
 	"github.com/google/go-cmp/cmp/internal/function"
@@ -17,8 +16,9 @@ import (
 // the fundamental Option functions (Ignore, Transformer, and Comparer),
 // configure how equality is determined.
 //
-// The fundamental options may be composed with filters (FilterPath and
-// FilterValues) to control the scope over which they are applied.
+// The fundamental options may be composed with filters (FilterPath,
+// FilterValues, and FilterValuesAsymmetric) to control the scope over which
+// they are applied.
 //
 // The cmp/cmpopts package provides helper functions for creating options that
 // may be used with Equal and Diff.
@@ -34,7 +34,7 @@ type Option interface {
 
 // applicableOption represents the following types:
 //	Fundamental: ignore | validator | *comparer | *transformer
-//	Grouping:    Options
+//	Grouping:    Options | prioritized
 type applicableOption interface {
 	Option
 
@@ -44,7 +44,7 @@ type applicableOption interface {
 
 // coreOption represents the following types:
 //	Fundamental: ignore | validator | *comparer | *transformer
-//	Filters:     *pathFilter | *valuesFilter
+//	Filters:     *pathFilter | *valuesFilter | *priorityFilter
 type coreOption interface {
 	Option
 	isCore()
@@ -63,25 +63,41 @@ func (core) isCore() {}
 // on all individual options held within.
 type Options []Option
 
-func (opts Options) filter(s *state, t reflect.Type, vx, vy reflect.Value) (out applicableOption) {
-	for _, opt := range opts {
+func (opts Options) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	out, _ := opts.filterIndex(s, t, vx, vy)
+	return out
+}
+
+// filterIndex is identical to filter, but also reports the index of the
+// option in opts that produced the applicable option.
+// The index is -1 if no single option in opts is responsible for the result.
+func (opts Options) filterIndex(s *state, t reflect.Type, vx, vy reflect.Value) (out applicableOption, idx int) {
+	idx = -1
+	for i, opt := range opts {
 		switch opt := opt.filter(s, t, vx, vy); opt.(type) {
 		case ignore:
-			return ignore{} // Only ignore can short-circuit evaluation
+			return ignore{}, i // Only ignore can short-circuit evaluation
 		case validator:
-			out = validator{} // Takes precedence over comparer or transformer
-		case *comparer, *transformer, Options:
+			out, idx = validator{}, -1 // Takes precedence over comparer or transformer
+		case *comparer, *transformer, Options, prioritized:
 			switch out.(type) {
 			case nil:
-				out = opt
+				out, idx = opt, i
 			case validator:
 				// Keep validator
-			case *comparer, *transformer, Options:
-				out = Options{out, opt} // Conflicting comparers or transformers
+			case *comparer, *transformer, Options, prioritized:
+				switch po, pn := priorityOf(out), priorityOf(opt); {
+				case pn > po:
+					out, idx = opt, i // Higher priority replaces the current option
+				case pn < po:
+					// Keep the current higher priority option
+				default:
+					out, idx = Options{out, opt}, -1 // Conflicting comparers or transformers
+				}
 			}
 		}
 	}
-	return out
+	return out, idx
 }
 
 func (opts Options) apply(s *state, _, _ reflect.Value) {
@@ -169,27 +185,144 @@ func FilterValues(f interface{}, opt Option) Option {
 	return nil
 }
 
+// FilterValuesAsymmetric returns a new Option where opt is only evaluated if
+// filter f, which is a function of the form "func(T, T) bool", returns true for
+// the current pair of values being compared. It is identical to FilterValues,
+// except that f is always called with the value from x as the first argument
+// and the value from y as the second argument, and f need not be symmetric.
+//
+// This permits filters that treat x and y differently. For example, when the
+// y argument to Equal is the expected value, an Ignore may be applied only
+// where the expected value is some wildcard sentinel value:
+//
+//	FilterValuesAsymmetric(func(_, want string) bool {
+//		return want == "*"
+//	}, Ignore())
+//
+// Note that the use of an asymmetric filter implies that Equal may report
+// different results if x and y are swapped.
+//
+// The filter function must be deterministic (i.e., produces the same result
+// when given the same inputs).
+func FilterValuesAsymmetric(f interface{}, opt Option) Option {
+	v := reflect.ValueOf(f)
+	if !function.IsType(v.Type(), function.ValueFilter) || v.IsNil() {
+		panic(fmt.Sprintf("invalid values filter function: %T", f))
+	}
+	if opt := normalizeOption(opt); opt != nil {
+		vf := &valuesFilter{fnc: v, opt: opt, asymmetric: true}
+		if ti := v.Type().In(0); ti.Kind() != reflect.Interface || ti.NumMethod() > 0 {
+			vf.typ = ti
+		}
+		return vf
+	}
+	return nil
+}
+
 type valuesFilter struct {
 	core
-	typ reflect.Type  // T
-	fnc reflect.Value // func(T, T) bool
-	opt Option
+	typ        reflect.Type  // T
+	fnc        reflect.Value // func(T, T) bool
+	opt        Option
+	asymmetric bool // Whether fnc may treat x and y differently
 }
 
 func (f valuesFilter) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
 	if !vx.IsValid() || !vx.CanInterface() || !vy.IsValid() || !vy.CanInterface() {
 		return nil
 	}
-	if (f.typ == nil || t.AssignableTo(f.typ)) && s.callTTBFunc(f.fnc, vx, vy) {
+	if f.typ != nil && !t.AssignableTo(f.typ) {
+		return nil
+	}
+	var ok bool
+	if f.asymmetric {
+		ok = s.callXYBFunc(f.fnc, vx, vy)
+	} else {
+		ok = s.callTTBFunc(f.fnc, vx, vy)
+	}
+	if ok {
 		return f.opt.filter(s, t, vx, vy)
 	}
 	return nil
 }
 
 func (f valuesFilter) String() string {
+	if f.asymmetric {
+		return fmt.Sprintf("FilterValuesAsymmetric(%s, %v)", function.NameOf(f.fnc), f.opt)
+	}
 	return fmt.Sprintf("FilterValues(%s, %v)", function.NameOf(f.fnc), f.opt)
 }
 
+// Priority returns a new Option where opt takes precedence over all other
+// Transformer and Comparer options with a lower priority that also apply
+// to the same node in the value tree. Options that are not wrapped by Priority
+// have a priority of zero. If multiple Transformer or Comparer options
+// with the same, highest priority apply, then Equal panics as usual since
+// it remains ambiguous which option to use. If Priority is applied to
+// an option that was already wrapped by Priority, the outer priority is used.
+//
+// Priority has no effect on Ignore options, which always take precedence.
+// The chosen priority is shown in the String of the resulting option.
+//
+// The option passed in may be an Ignore, Transformer, Comparer, Options, or
+// a previously filtered Option.
+func Priority(p int, opt Option) Option {
+	if opt := normalizeOption(opt); opt != nil {
+		return &priorityFilter{pri: p, opt: opt}
+	}
+	return nil
+}
+
+type priorityFilter struct {
+	core
+	pri int
+	opt Option
+}
+
+func (f priorityFilter) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	switch opt := f.opt.filter(s, t, vx, vy).(type) {
+	case nil:
+		return nil
+	case ignore, validator:
+		return opt
+	case prioritized:
+		return prioritized{pri: f.pri, opt: opt.opt}
+	default:
+		return prioritized{pri: f.pri, opt: opt}
+	}
+}
+
+func (f priorityFilter) String() string {
+	return fmt.Sprintf("Priority(%d, %v)", f.pri, f.opt)
+}
+
+// prioritized is a Transformer or Comparer (or an ambiguous set of them)
+// that applies with a given priority.
+type prioritized struct {
+	core
+	pri int
+	opt applicableOption
+}
+
+func (p prioritized) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	return p
+}
+func (p prioritized) apply(s *state, vx, vy reflect.Value) { p.opt.apply(s, vx, vy) }
+func (p prioritized) String() string                       { return fmt.Sprintf("Priority(%d, %v)", p.pri, p.opt) }
+
+// priorityOf reports the priority of an applicable option.
+func priorityOf(opt applicableOption) int {
+	switch opt := opt.(type) {
+	case prioritized:
+		return opt.pri
+	case Options:
+		// All options in an ambiguous set have the same priority.
+		return priorityOf(opt[0].(applicableOption))
+	default:
+		return 0
+	}
+}
+
 // Ignore is an Option that causes all comparisons to be ignored.
 // This value is intended to be combined with FilterPath or FilterValues.
 // It is an error to pass an unfiltered Ignore option to Equal.
@@ -232,17 +365,15 @@ func (validator) apply(s *state, vx, vy reflect.Value) {
 	panic("not reachable")
 }
 
-// identRx represents a valid identifier according to the Go specification.
-const identRx = `[_\p{L}][_\p{L}\p{N}]*`
-
-var identsRx = regexp.MustCompile(`^` + identRx + `(\.` + identRx + `)*$`)
-
 // Transformer returns an Option that applies a transformation function that
 // converts values of a certain type into that of another.
 //
 // The transformer f must be a function "func(T) R" that converts values of
 // type T to those of type R and is implicitly filtered to input values
 // assignable to T. The transformer must not mutate T in any way.
+// Since the output for each input is cached for the duration of a comparison,
+// the transformer may be called fewer times than it is applied
+// (see NoTransformerCache).
 //
 // To help prevent some cases of infinite recursive cycles applying the
 // same transform to the output of itself (e.g., in the case where the
@@ -264,10 +395,10 @@ func Transformer(name string, f interface{}) Option {
 	}
 	if name == "" {
 		name = function.NameOf(v)
-		if !identsRx.MatchString(name) {
+		if !function.IsIdent(name) {
 			name = "λ" // Lambda-symbol as placeholder name
 		}
-	} else if !identsRx.MatchString(name) {
+	} else if !function.IsIdent(name) {
 		panic(fmt.Sprintf("invalid name: %q", name))
 	}
 	tr := &transformer{name: name, fnc: reflect.ValueOf(f)}
@@ -301,9 +432,10 @@ func (tr *transformer) filter(s *state, t reflect.Type, _, _ reflect.Value) appl
 }
 
 func (tr *transformer) apply(s *state, vx, vy reflect.Value) {
+	s.work.Transformers++
 	step := &transform{pathStep{typ: tr.fnc.Type().Out(0)}, tr}
-	vvx := s.callTRFunc(tr.fnc, vx, step)
-	vvy := s.callTRFunc(tr.fnc, vy, step)
+	vvx := s.callTransformer(tr, vx, step)
+	vvy := s.callTransformer(tr, vy, step)
 	step.vx, step.vy = vvx, vvy
 	s.compareAny(step)
 }
@@ -352,6 +484,7 @@ func (cm *comparer) filter(_ *state, t reflect.Type, _, _ reflect.Value) applica
 }
 
 func (cm *comparer) apply(s *state, vx, vy reflect.Value) {
+	s.work.Comparers++
 	eq := s.callTTBFunc(cm.fnc, vx, vy)
 	s.report(eq, reportByFunc)
 }
@@ -401,12 +534,54 @@ func AllowUnexported(types ...interface{}) Option {
 	return visibleStructs(m)
 }
 
+// Exporter returns an Option that specifies whether Equal is allowed to
+// introspect into the unexported fields of certain struct types,
+// which are those for which f reports true.
+// It is a generalization of AllowUnexported for when the set of types is not
+// known ahead of time, such as all struct types declared within a package.
+// The same caveats apply: comparing on the unexported fields of types from
+// external packages is not safe, since the internal implementation of
+// those types may change.
+func Exporter(f func(reflect.Type) bool) Option {
+	if !supportAllowUnexported {
+		panic("Exporter is not supported on purego builds, Google App Engine Standard, or GopherJS")
+	}
+	if f == nil {
+		panic("invalid nil exporter function")
+	}
+	return exporter(f)
+}
+
+type exporter func(reflect.Type) bool
+
+func (exporter) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
 type visibleStructs map[reflect.Type]bool
 
 func (visibleStructs) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
 	panic("not implemented")
 }
 
+// Strict returns an Option that causes Equal and Diff to panic if any of the
+// other options passed to the same call never applied to any node in the
+// value tree. That is, every Ignore, Transformer, and Comparer option
+// (after having applied all filters) must have been used at least once.
+//
+// Options that no longer match anything (e.g., because a field was renamed)
+// otherwise silently remain in test suites, where they give a false sense of
+// what the comparison actually checks.
+func Strict() Option {
+	return strictOption{}
+}
+
+type strictOption struct{}
+
+func (strictOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
 // reportFlags is a bit-set representing how a comparison was determined.
 type reportFlags uint
 
@@ -428,16 +603,24 @@ const (
 	// reportByFunc reports whether equality was determined by calling a custom
 	// Comparer function. This may be ORed with reportEqual or reportUnequal.
 	reportByFunc
+	// reportByCycle reports whether equality was determined by comparing
+	// the structure of a cycle. This may be ORed with reportEqual or
+	// reportUnequal.
+	reportByCycle
+	// reportByDepth reports whether equality was determined without
+	// descending into the node since it is at the maximum depth.
+	// This may be ORed with reportEqual or reportUnequal.
+	reportByDepth
 )
 
-// reporter is an Option that can be passed to Equal. When Equal traverses
-// the value trees, it calls PushStep as it descends into each node in the
-// tree and PopStep as it ascend out of the node. The leaves of the tree are
-// either compared (determined to be equal or not equal) or ignored and reported
-// as such by calling the Report method.
-func reporter(r interface {
-	// TODO: Export this option.
-
+// Reporter returns an Option that passes the progress of the comparison to r,
+// such that users can render differences in their own output formats
+// (e.g., JSON, HTML, or assertions of a test framework).
+// When Equal traverses the value trees, it calls PushStep as it descends
+// into each node in the tree and PopStep as it ascends out of the node.
+// The leaves of the tree are either compared (determined to be equal or
+// not equal) or ignored and reported as such by calling the Report method.
+func Reporter(r interface {
 	// PushStep is called when a tree-traversal operation is performed.
 	// The PathStep itself is only valid until the step is popped.
 	// The PathStep.Values are valid for the duration of the entire traversal.
@@ -452,19 +635,108 @@ func reporter(r interface {
 	// comparison identified the node as equal, unequal, or ignored.
 	// A leaf node is one that is immediately preceded by and followed by
 	// a pair of PushStep and PopStep calls.
-	Report(reportFlags)
+	//
+	// The Path is the full stack of steps from the root to the leaf node,
+	// where the last step is the step most recently pushed.
+	// Every step in the Path, including those of ancestor nodes,
+	// reports the values at that node in the value tree through Values,
+	// so that a reporter may print identifying context from parent nodes.
+	// See Path for how long the Path remains valid.
+	Report(Path, Result)
 
 	// PopStep ascends back up the value tree.
 	// There is always a matching pop call for every push call.
 	PopStep()
 }) Option {
+	if r == nil {
+		panic("invalid nil reporter")
+	}
+	return reporterOption{publicReporter{r}}
+}
+
+// Result represents the comparison result for a single node and
+// is provided by Equal to the Reporter option.
+//
+// If none of ByIgnore, ByMethod, ByFunc, ByCycle, and ByDepth report true,
+// then the node was compared by the == operator on its underlying kind,
+// or by whether the node is nil if it is a pointer, slice, map, or interface.
+type Result struct {
+	flags       reportFlags
+	transformed bool
+}
+
+// Equal reports whether the node was determined to be equal or not.
+// As a special case, ignored nodes are considered equal.
+func (r Result) Equal() bool {
+	return r.flags&reportUnequal == 0
+}
+
+// ByIgnore reports whether the node is equal because it was ignored.
+// This never reports true if Equal reports false.
+func (r Result) ByIgnore() bool {
+	return r.flags&reportIgnored != 0
+}
+
+// ByMethod reports whether the Equal method determined equality.
+func (r Result) ByMethod() bool {
+	return r.flags&reportByMethod != 0
+}
+
+// ByFunc reports whether a Comparer function determined equality.
+func (r Result) ByFunc() bool {
+	return r.flags&reportByFunc != 0
+}
+
+// ByCycle reports whether a reference cycle was detected.
+func (r Result) ByCycle() bool {
+	return r.flags&reportByCycle != 0
+}
+
+// ByDepth reports whether the node was compared without descending into it,
+// since it is at the depth limited by MaxDepth.
+func (r Result) ByDepth() bool {
+	return r.flags&reportByDepth != 0
+}
+
+// ByTransform reports whether the node was compared in a transformed form,
+// which is the case if the path to the node contains a Transform step.
+// This may be true in combination with any of the other methods.
+func (r Result) ByTransform() bool {
+	return r.transformed
+}
+
+// publicReporter adapts a reporter passed to Reporter to reporterIface.
+type publicReporter struct {
+	r interface {
+		PushStep(PathStep)
+		Report(Path, Result)
+		PopStep()
+	}
+}
+
+func (r publicReporter) PushStep(ps PathStep) { r.r.PushStep(ps) }
+func (r publicReporter) Report(p Path, f reportFlags) {
+	var transformed bool
+	for _, ps := range p {
+		if _, ok := ps.(Transform); ok {
+			transformed = true
+			break
+		}
+	}
+	r.r.Report(p, Result{f, transformed})
+}
+func (r publicReporter) PopStep() { r.r.PopStep() }
+
+// reporter is identical to Reporter, except that it is used by the reporters
+// within this package, which also have access to the underlying reportFlags.
+func reporter(r reporterIface) Option {
 	return reporterOption{r}
 }
 
 type reporterOption struct{ reporterIface }
 type reporterIface interface {
 	PushStep(PathStep)
-	Report(reportFlags)
+	Report(Path, reportFlags)
 	PopStep()
 }
 
@@ -5,7 +5,9 @@
 package cmp
 
 import (
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"reflect"
 	"strings"
 	"testing"
@@ -181,6 +183,217 @@ func TestOptionPanic(t *testing.T) {
 		fnc:       FilterValues,
 		args:      []interface{}{func(int, int) bool { return true }, Options{Ignore(), reporter(&defaultReporter{})}},
 		wantPanic: "invalid option type",
+	}, {
+		label:     "FilterValuesAsymmetric",
+		fnc:       FilterValuesAsymmetric,
+		args:      []interface{}{0, Ignore()},
+		wantPanic: "invalid values filter function",
+	}, {
+		label: "FilterValuesAsymmetric",
+		fnc:   FilterValuesAsymmetric,
+		args:  []interface{}{func(x, y int) bool { return true }, Ignore()},
+	}, {
+		label:     "FilterValuesAsymmetric",
+		fnc:       FilterValuesAsymmetric,
+		args:      []interface{}{func(x io.Reader, y interface{}) bool { return true }, Ignore()},
+		wantPanic: "invalid values filter function",
+	}, {
+		label:     "Priority",
+		fnc:       Priority,
+		args:      []interface{}{1, reporter(&defaultReporter{})},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "CaptureFailures",
+		fnc:       CaptureFailures,
+		args:      []interface{}{(func(interface{}) ([]byte, error))(nil), func(Capture) {}},
+		wantPanic: "invalid nil encoder or save function",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, CaptureFailures(json.Marshal, func(Capture) {})},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "RegisterSurrogate",
+		fnc:       RegisterSurrogate,
+		args:      []interface{}{"", 5},
+		wantPanic: "invalid transformer function",
+	}, {
+		label:     "RegisterSurrogate",
+		fnc:       RegisterSurrogate,
+		args:      []interface{}{"", func(io.Reader) string { return "" }},
+		wantPanic: "invalid surrogate function",
+	}, {
+		label:     "RegisterSurrogate",
+		fnc:       RegisterSurrogate,
+		args:      []interface{}{"", func(x ts.StructA) ts.StructA { return x }},
+		wantPanic: "invalid surrogate function",
+	}, {
+		label:     "Exporter",
+		fnc:       Exporter,
+		args:      []interface{}{(func(reflect.Type) bool)(nil)},
+		wantPanic: "invalid nil exporter function",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, Exporter(func(reflect.Type) bool { return true })},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "Hooks",
+		fnc:       Hooks,
+		args:      []interface{}{(func(Path))(nil), (func(Path, bool))(nil)},
+		wantPanic: "invalid nil hook functions",
+	}, {
+		label:     "AuditIgnored",
+		fnc:       AuditIgnored,
+		args:      []interface{}{(func(Path, Option))(nil)},
+		wantPanic: "invalid nil audit function",
+	}, {
+		label:     "TraceOptions",
+		fnc:       TraceOptions,
+		args:      []interface{}{(func(Path, Option))(nil)},
+		wantPanic: "invalid nil trace function",
+	}, {
+		label:     "RegisterOptions",
+		fnc:       RegisterOptions,
+		args:      []interface{}{ts.StructA{}, Strict()},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "SetDefaultOptions",
+		fnc:       SetDefaultOptions,
+		args:      []interface{}{Strict()},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, NoRegisteredOptions()},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "MaxDiffsPerPath",
+		fnc:       MaxDiffsPerPath,
+		args:      []interface{}{0},
+		wantPanic: "limit must be a positive number",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, MaxDiffsPerPath(1)},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "Config.Diff",
+		fnc:       Config.Diff,
+		args:      []interface{}{Config{MaxDifferences: -1}, 0, 1},
+		wantPanic: "limit must be a positive number",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, Config{}},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "MaxDiffsPerContainer",
+		fnc:       MaxDiffsPerContainer,
+		args:      []interface{}{-1},
+		wantPanic: "limit must be a positive number",
+	}, {
+		label: "MaxReportSize",
+		fnc:   MaxReportSize,
+		args:  []interface{}{0, 100},
+	}, {
+		label:     "MaxReportSize",
+		fnc:       MaxReportSize,
+		args:      []interface{}{100, -1},
+		wantPanic: "limit must be a non-negative number",
+	}, {
+		label:     "MaxDiffs",
+		fnc:       MaxDiffs,
+		args:      []interface{}{0},
+		wantPanic: "limit must be a positive number",
+	}, {
+		label:     "FilterPath",
+		fnc:       FilterPath,
+		args:      []interface{}{func(Path) bool { return true }, Verbose()},
+		wantPanic: "invalid option type",
+	}, {
+		label:     "FloatExponentRange",
+		fnc:       FloatExponentRange,
+		args:      []interface{}{0, 0},
+		wantPanic: "invalid exponent range",
+	}, {
+		label: "EnumNames",
+		fnc:   EnumNames,
+		args:  []interface{}{map[uint8]string{}},
+	}, {
+		label:     "EnumNames",
+		fnc:       EnumNames,
+		args:      []interface{}{map[string]string{}},
+		wantPanic: "invalid enum name table",
+	}, {
+		label:     "FieldNamesByTag",
+		fnc:       FieldNamesByTag,
+		args:      []interface{}{""},
+		wantPanic: "invalid empty struct tag key",
+	}, {
+		label: "Formatter",
+		fnc:   Formatter,
+		args:  []interface{}{func(int) string { return "" }},
+	}, {
+		label:     "Formatter",
+		fnc:       Formatter,
+		args:      []interface{}{func(int) []byte { return nil }},
+		wantPanic: "invalid formatter function",
+	}, {
+		label:     "Formatter",
+		fnc:       Formatter,
+		args:      []interface{}{(func(int) string)(nil)},
+		wantPanic: "invalid formatter function",
+	}, {
+		label:     "FormatValue",
+		fnc:       FormatValue,
+		args:      []interface{}{(func(interface{}) (string, bool))(nil)},
+		wantPanic: "invalid nil format function",
+	}, {
+		label: "Parallel",
+		fnc:   Parallel,
+		args:  []interface{}{4},
+	}, {
+		label:     "Parallel",
+		fnc:       Parallel,
+		args:      []interface{}{0},
+		wantPanic: "number of goroutines must be a positive number",
+	}, {
+		label: "MaxDepth",
+		fnc:   MaxDepth,
+		args:  []interface{}{8},
+	}, {
+		label:     "MaxDepth",
+		fnc:       MaxDepth,
+		args:      []interface{}{0},
+		wantPanic: "maximum depth must be a positive number",
+	}, {
+		label: "UseTags",
+		fnc:   UseTags,
+		args:  []interface{}{"cmp"},
+	}, {
+		label:     "UseTags",
+		fnc:       UseTags,
+		args:      []interface{}{""},
+		wantPanic: "invalid empty tag key",
+	}, {
+		label: "OnDifference",
+		fnc:   OnDifference,
+		args:  []interface{}{func(Difference) {}},
+	}, {
+		label:     "OnDifference",
+		fnc:       OnDifference,
+		args:      []interface{}{(func(Difference))(nil)},
+		wantPanic: "invalid nil difference function",
+	}, {
+		label: "WriteDiffs",
+		fnc:   WriteDiffs,
+		args:  []interface{}{ioutil.Discard},
+	}, {
+		label:     "RegisterFormatter",
+		fnc:       RegisterFormatter,
+		args:      []interface{}{func(int, int) string { return "" }},
+		wantPanic: "invalid formatter function",
 	}}
 
 	for _, tt := range tests {
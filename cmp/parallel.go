@@ -0,0 +1,152 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp/internal/diff"
+)
+
+// Parallel returns an Option that compares the elements of the outermost
+// slices and arrays, and the entries of the outermost maps, on n goroutines.
+// This speeds up comparing very large collections whose elements are
+// expensive to compare, such as slices of deeply nested structs.
+// The values within each element are compared by a single goroutine.
+//
+// The results and reports are identical to those of a sequential comparison,
+// since the reporter events of each element are delivered in order.
+// However, all options passed to the comparison (e.g., the functions of
+// Comparer, Transformer, and filter options) must be safe to call
+// concurrently. Equal still stops comparing once a difference is found,
+// although other goroutines may first complete the elements they are comparing.
+func Parallel(n int) Option {
+	if n <= 0 {
+		panic("number of goroutines must be a positive number")
+	}
+	return parallelOption(n)
+}
+
+type parallelOption int
+
+func (parallelOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+// fork returns a copy of s for comparing a sub-value of the current node on
+// another goroutine, such that the copy shares no mutable state with s.
+// Nested collections are compared sequentially by the copy.
+func (s *state) fork() *state {
+	c := *s
+	c.curPath = append(Path(nil), s.curPath...)
+	c.curPtrs = s.curPtrs.clone()
+	c.scopes = append([]int(nil), s.scopes...)
+	c.reporters = nil
+	c.used = nil
+	c.transformed = nil
+	c.parallel = 0
+	return &c
+}
+
+// compareMapConcurrently compares the entries of maps vx and vy for the
+// given keys using compareConcurrently. It reports false without comparing
+// any entries if a key contains a NaN, such that compareMap reports it.
+func (s *state) compareMapConcurrently(t reflect.Type, vx, vy reflect.Value, keys []reflect.Value) bool {
+	steps := make([]PathStep, len(keys))
+	for i, k := range keys {
+		step := &mapIndex{pathStep{t.Elem(), vx.MapIndex(k), vy.MapIndex(k)}, k}
+		if !step.vx.IsValid() && !step.vy.IsValid() {
+			return false
+		}
+		steps[i] = step
+	}
+	s.compareConcurrently(steps, func(_ int, res diff.Result, rec *recorder) bool {
+		s.replay(res, rec)
+		return true
+	})
+	return true
+}
+
+// concurrentResult is the result of comparing the values at a single step
+// by compareConcurrently.
+type concurrentResult struct {
+	res  diff.Result
+	rec  *recorder   // Recorded reporter events; nil if there are no reporters
+	work Stats       // Work performed by the comparison
+	used []bool      // Options used by the comparison; nil unless Strict
+	ex   interface{} // Value that the comparison panicked with, if any
+	done chan struct{}
+}
+
+// compareConcurrently compares the values at each of the steps, which must
+// be children of the current node, on up to s.parallel goroutines.
+// It calls f with the result of each comparison in order, on the current
+// goroutine, until f reports false. The work performed and the options used
+// are merged into s, and any panic is propagated as if it occurred in f.
+func (s *state) compareConcurrently(steps []PathStep, f func(i int, res diff.Result, rec *recorder) bool) {
+	results := make([]concurrentResult, len(steps))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+
+	// Stop all goroutines once f no longer needs further results.
+	quit := make(chan struct{})
+	defer close(quit)
+	next := make(chan int)
+	go func() {
+		defer close(next)
+		for i := range steps {
+			select {
+			case next <- i:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	n := s.parallel
+	if n > len(steps) {
+		n = len(steps)
+	}
+	for w := 0; w < n; w++ {
+		c := s.fork()
+		hasReporters := len(s.reporters) > 0
+		strict := s.used != nil
+		go func() {
+			for i := range next {
+				r := &results[i]
+				c.work = Stats{}
+				if strict {
+					c.used = make([]bool, len(c.opts))
+				}
+				if hasReporters {
+					r.rec = new(recorder)
+				}
+				func() {
+					defer func() { r.ex = recover() }()
+					r.res = c.recordCompare(steps[i], r.rec)
+				}()
+				r.work, r.used = c.work, c.used
+				close(r.done)
+			}
+		}()
+	}
+
+	for i := range results {
+		r := &results[i]
+		<-r.done
+		s.work.add(r.work)
+		for j, used := range r.used {
+			s.used[j] = s.used[j] || used
+		}
+		if r.ex != nil {
+			panic(r.ex)
+		}
+		if !f(i, r.res, r.rec) {
+			return
+		}
+		r.rec = nil // Release the events once they are replayed
+	}
+}
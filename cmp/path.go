@@ -10,6 +10,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp/internal/value"
 )
 
 type (
@@ -22,6 +24,11 @@ type (
 	// always be accessed as a field before traversing the fields of the
 	// embedded struct themselves. That is, an exported field from the
 	// embedded struct will never be accessed directly from the parent struct.
+	//
+	// A Path passed to a function during a comparison (e.g., to a FilterPath
+	// filter or to a Reporter) is only valid for the duration of the call,
+	// since its steps are reused as the traversal proceeds.
+	// Steps retained beyond that point must be copied.
 	Path []PathStep
 
 	// PathStep is a union-type for specific operations to traverse
@@ -169,6 +176,12 @@ func (pa Path) String() string {
 // For example:
 //	(*root.MyMap["key"].(*mypkg.MyStruct).MySlices)[2][3].MyField
 func (pa Path) GoString() string {
+	return pa.goString("")
+}
+
+// goString is identical to GoString, except that struct fields are named
+// according to the struct tag with the given key, if non-empty.
+func (pa Path) goString(tag string) string {
 	var ssPre, ssPost []string
 	var numIndirect int
 	for i, s := range pa {
@@ -207,6 +220,14 @@ func (pa Path) GoString() string {
 			if s.Type().PkgPath() == "" {
 				continue
 			}
+		case *structField:
+			if tag != "" && i > 0 {
+				f := pa[i-1].Type().Field(s.idx)
+				if name := value.FieldTagName(f, tag); name != "" {
+					ssPost = append(ssPost, "."+name)
+					continue
+				}
+			}
 		}
 		ssPost = append(ssPost, s.String())
 	}
@@ -216,6 +237,51 @@ func (pa Path) GoString() string {
 	return strings.Join(ssPre, "") + strings.Join(ssPost, "")
 }
 
+// EditKind classifies how the values at the end of a Path differ.
+type EditKind int
+
+const (
+	// Modified indicates that the value exists in both x and y.
+	Modified EditKind = iota
+	// Inserted indicates that the value only exists in y,
+	// such as an element inserted into a slice or an entry added to a map.
+	Inserted
+	// Removed indicates that the value only exists in x,
+	// such as an element removed from a slice or an entry removed from a map.
+	Removed
+)
+
+func (k EditKind) String() string {
+	switch k {
+	case Modified:
+		return "modified"
+	case Inserted:
+		return "inserted"
+	case Removed:
+		return "removed"
+	default:
+		return fmt.Sprintf("EditKind(%d)", int(k))
+	}
+}
+
+// Edit reports how the values at the last step differ.
+// For a SliceIndex, the positions of an inserted or removed element are
+// available through SplitKeys; for a MapIndex, the key is available through Key.
+//
+// Elements that move within a slice are reported as a removal from the
+// old position and an insertion at the new position.
+func (pa Path) Edit() EditKind {
+	vx, vy := pa.Last().Values()
+	switch {
+	case !vx.IsValid() && vy.IsValid():
+		return Inserted
+	case vx.IsValid() && !vy.IsValid():
+		return Removed
+	default:
+		return Modified
+	}
+}
+
 type (
 	pathStep struct {
 		typ    reflect.Type
@@ -237,6 +303,7 @@ type (
 	sliceIndex struct {
 		pathStep
 		xkey, ykey int
+		isSlice    bool // False for reflect.Array
 	}
 	mapIndex struct {
 		pathStep
@@ -340,3 +407,75 @@ func isExported(id string) bool {
 	r, _ := utf8.DecodeRuneInString(id)
 	return unicode.IsUpper(r)
 }
+
+// pointerPath represents a dual-stack of pointers encountered when
+// recursively traversing the x and y values. This data structure supports
+// detection of cycles and determining whether the cycles are equal.
+// In Go, cycles can occur via pointers, slices, and maps.
+//
+// The pointerPath uses a map to represent a stack; where descension into a
+// pointer pushes the address onto the stack, and ascension from a pointer
+// pops the address from the stack. Thus, when traversing into a pointer from
+// reflect.Ptr, reflect.Slice element, or reflect.Map, we can detect cycles
+// by checking whether the pointer has already been visited. The cycle detection
+// uses a separate stack for the x and y values.
+//
+// If a cycle is detected we need to determine whether the two pointers
+// should be considered equal. The definition of equality chosen by Equal
+// requires two graphs to have the same structure. To determine this, both the
+// x and y values must have a cycle where the previous pointers were also
+// encountered together as a pair.
+//
+// Using a map as a stack is more performant as we can perform cycle detection
+// in O(1) instead of O(N) where N is len(Path).
+type pointerPath struct {
+	// mx is keyed by x pointers, where the value is the associated y pointer.
+	mx map[value.Pointer]value.Pointer
+	// my is keyed by y pointers, where the value is the associated x pointer.
+	my map[value.Pointer]value.Pointer
+}
+
+func (p *pointerPath) Init() {
+	p.mx = make(map[value.Pointer]value.Pointer)
+	p.my = make(map[value.Pointer]value.Pointer)
+}
+
+// clone returns a copy of p that may be modified independently of p.
+func (p pointerPath) clone() pointerPath {
+	var c pointerPath
+	c.Init()
+	for px, py := range p.mx {
+		c.mx[px] = py
+	}
+	for py, px := range p.my {
+		c.my[py] = px
+	}
+	return c
+}
+
+// Push indicates intent to descend into pointers vx and vy where
+// visited reports whether either has been seen before. If visited before,
+// equal reports whether both pointers were encountered together.
+// Pop must be called if and only if the pointers were never visited.
+//
+// The pointers vx and vy must be a reflect.Ptr, reflect.Slice, or reflect.Map
+// and be non-nil.
+func (p pointerPath) Push(vx, vy reflect.Value) (equal, visited bool) {
+	px := value.PointerOf(vx)
+	py := value.PointerOf(vy)
+	_, ok1 := p.mx[px]
+	_, ok2 := p.my[py]
+	if ok1 || ok2 {
+		equal = p.mx[px] == py && p.my[py] == px // Pointers paired together
+		return equal, true
+	}
+	p.mx[px] = py
+	p.my[py] = px
+	return false, false
+}
+
+// Pop ascends from pointers vx and vy.
+func (p pointerPath) Pop(vx, vy reflect.Value) {
+	delete(p.mx, value.PointerOf(vx))
+	delete(p.my, value.PointerOf(vy))
+}
@@ -0,0 +1,172 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Reduce returns copies of x and y that only contain the portions that differ
+// according to Equal with the same options. It is intended for constructing
+// minimal counter-examples from a failed comparison, suitable for pasting into
+// bug reports and regression fixtures.
+//
+// Within the copies, struct fields and array elements that are equal are
+// set to the zero value, slice elements that are equal are elided, and
+// map entries that are equal are deleted. Values that differ are retained in
+// full, as are values that differ only after applying a Transformer,
+// since a transformation cannot be inverted. If x and y are equal,
+// then the returned copies are the zero values of their types.
+//
+// The copies share any retained sub-values with x and y,
+// which should not be mutated while the copies are in use.
+func Reduce(x, y interface{}, opts ...Option) (rx, ry interface{}) {
+	r := new(reduceReporter)
+	s := newState(opts)
+	s.reporters = append(s.reporters, reporterOption{r})
+	step := s.compareRoot(x, y)
+
+	vx, vy := step.Values()
+	if vx = reduceValue(vx, &r.root, true); vx.IsValid() {
+		rx = vx.Interface()
+	}
+	if vy = reduceValue(vy, &r.root, false); vy.IsValid() {
+		ry = vy.Interface()
+	}
+	return rx, ry
+}
+
+// reduceNode is a node in a tree of the paths to every difference.
+type reduceNode struct {
+	step     PathStep
+	leaf     bool // Whether the entire value at this node is retained
+	children []*reduceNode
+	index    map[string]*reduceNode
+}
+
+func (n *reduceNode) child(ps PathStep) *reduceNode {
+	key := ps.String()
+	if si, ok := ps.(*sliceIndex); ok {
+		key = fmt.Sprintf("[%d,%d]", si.xkey, si.ykey)
+	}
+	if c, ok := n.index[key]; ok {
+		return c
+	}
+	if n.index == nil {
+		n.index = make(map[string]*reduceNode)
+	}
+	c := &reduceNode{step: copyStep(ps)}
+	n.index[key] = c
+	n.children = append(n.children, c)
+	return c
+}
+
+type reduceReporter struct{ root reduceNode }
+
+func (r *reduceReporter) PushStep(PathStep) {}
+func (r *reduceReporter) Report(p Path, f reportFlags) {
+	if f&reportUnequal == 0 {
+		return
+	}
+	n := &r.root
+	for _, ps := range p[1:] {
+		if _, ok := ps.(*transform); ok {
+			break // Retain the entire value prior to the transformation
+		}
+		n = n.child(ps)
+	}
+	n.leaf = true
+}
+func (r *reduceReporter) PopStep() {}
+
+// reduceValue returns a copy of v that only retains the sub-values on the path
+// to a difference in n. The isX flag reports whether v is from the x tree.
+func reduceValue(v reflect.Value, n *reduceNode, isX bool) reflect.Value {
+	if n.leaf || !v.IsValid() {
+		return v
+	}
+	t := v.Type()
+	if len(n.children) == 0 {
+		return reflect.Zero(t)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		var vc reflect.Value // Addressable copy of v
+		for _, c := range n.children {
+			sf := c.step.(*structField)
+			src, dst := v.Field(sf.idx), out.Field(sf.idx)
+			if sf.unexported {
+				if !sf.mayForce {
+					continue
+				}
+				if !vc.IsValid() {
+					vc = reflect.New(t).Elem()
+					vc.Set(v)
+				}
+				src = retrieveUnexportedField(vc, sf.field)
+				dst = retrieveUnexportedField(out, sf.field)
+			}
+			dst.Set(reduceValue(src, c, isX))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, 0, len(n.children))
+		for _, c := range n.children {
+			if k := sliceKey(c.step, isX); k >= 0 {
+				out = reflect.Append(out, reduceValue(v.Index(k), c, isX))
+			}
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for _, c := range n.children {
+			if k := sliceKey(c.step, isX); k >= 0 {
+				out.Index(k).Set(reduceValue(v.Index(k), c, isX))
+			}
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMap(t)
+		for _, c := range n.children {
+			k := c.step.(*mapIndex).key
+			if e := v.MapIndex(k); e.IsValid() {
+				out.SetMapIndex(k, reduceValue(e, c, isX))
+			}
+		}
+		return out
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(reduceValue(v.Elem(), n.children[0], isX))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(reduceValue(v.Elem(), n.children[0], isX))
+		return out
+	default:
+		return v
+	}
+}
+
+func sliceKey(ps PathStep, isX bool) int {
+	si := ps.(*sliceIndex)
+	if isX {
+		return si.xkey
+	}
+	return si.ykey
+}
@@ -0,0 +1,143 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReduce(t *testing.T) {
+	type (
+		item struct {
+			Name  string
+			Count int
+			Tags  []string
+		}
+		order struct {
+			ID    int
+			Items []item
+			Meta  map[string]string
+			Next  *order
+			Any   interface{}
+			Fixed [3]int
+		}
+		private struct {
+			a, b int
+		}
+		words string
+	)
+
+	tests := []struct {
+		label  string
+		x, y   interface{}
+		opts   []Option
+		wantX  interface{}
+		wantY  interface{}
+		reason string
+	}{{
+		label:  "Equal",
+		x:      order{ID: 1, Items: []item{{Name: "a"}}},
+		y:      order{ID: 1, Items: []item{{Name: "a"}}},
+		wantX:  order{},
+		wantY:  order{},
+		reason: "equal values reduce to the zero value",
+	}, {
+		label:  "Nil",
+		x:      nil,
+		y:      nil,
+		reason: "equal nil values reduce to nil",
+	}, {
+		label: "StructFields",
+		x: order{
+			ID:    1,
+			Items: []item{{"a", 1, nil}, {"b", 2, []string{"x", "y"}}, {"c", 3, nil}},
+			Meta:  map[string]string{"k1": "v1", "k2": "v2"},
+			Any:   "hello",
+			Fixed: [3]int{1, 2, 3},
+		},
+		y: order{
+			ID:    1,
+			Items: []item{{"a", 1, nil}, {"b", 2, []string{"x", "z"}}, {"c", 3, nil}},
+			Meta:  map[string]string{"k1": "v1", "k2": "changed"},
+			Any:   "hello",
+			Fixed: [3]int{1, 5, 3},
+		},
+		wantX: order{
+			Items: []item{{Tags: []string{"y"}}},
+			Meta:  map[string]string{"k2": "v2"},
+			Fixed: [3]int{0, 2, 0},
+		},
+		wantY: order{
+			Items: []item{{Tags: []string{"z"}}},
+			Meta:  map[string]string{"k2": "changed"},
+			Fixed: [3]int{0, 5, 0},
+		},
+		reason: "only differing fields, elements, and entries are retained",
+	}, {
+		label:  "InsertedElements",
+		x:      []int{1, 2, 3},
+		y:      []int{1, 2, 3, 4},
+		wantX:  []int{},
+		wantY:  []int{4},
+		reason: "inserted elements are only retained on the side they exist",
+	}, {
+		label:  "MissingKeys",
+		x:      map[string]int{"a": 1, "b": 2},
+		y:      map[string]int{"a": 1},
+		wantX:  map[string]int{"b": 2},
+		wantY:  map[string]int{},
+		reason: "missing map entries are only retained on the side they exist",
+	}, {
+		label:  "Pointers",
+		x:      &order{ID: 1, Next: &order{ID: 2, Any: 5}},
+		y:      &order{ID: 1, Next: &order{ID: 2, Any: "5"}},
+		wantX:  &order{Next: &order{Any: 5}},
+		wantY:  &order{Next: &order{Any: "5"}},
+		reason: "pointers are followed and values of differing types are retained",
+	}, {
+		label:  "NilPointer",
+		x:      &order{ID: 1, Next: &order{ID: 2}},
+		y:      &order{ID: 1},
+		wantX:  &order{Next: &order{ID: 2}},
+		wantY:  &order{},
+		reason: "a non-nil pointer compared against a nil pointer is retained in full",
+	}, {
+		label:  "DifferentTypes",
+		x:      5,
+		y:      "5",
+		wantX:  5,
+		wantY:  "5",
+		reason: "values of different types are retained in full",
+	}, {
+		label: "Transformer",
+		x:     []words{"a b", "c d"},
+		y:     []words{"a b", "c e"},
+		opts: []Option{
+			Transformer("Split", func(s words) []string { return strings.Fields(string(s)) }),
+		},
+		wantX:  []words{"c d"},
+		wantY:  []words{"c e"},
+		reason: "transformed values are retained in their original form",
+	}, {
+		label:  "Unexported",
+		x:      []private{{a: 1, b: 2}},
+		y:      []private{{a: 1, b: 3}},
+		opts:   []Option{AllowUnexported(private{})},
+		wantX:  []private{{b: 2}},
+		wantY:  []private{{b: 3}},
+		reason: "unexported fields are retained if they may be compared",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			gotX, gotY := Reduce(tt.x, tt.y, tt.opts...)
+			opts := []Option{AllowUnexported(private{})}
+			if !Equal(gotX, tt.wantX, opts...) || !Equal(gotY, tt.wantY, opts...) {
+				t.Errorf("Reduce:\ngot  (%v, %v)\nwant (%v, %v)\nreason: %v", gotX, gotY, tt.wantX, tt.wantY, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/go-cmp/cmp/internal/function"
+)
+
+var registered struct {
+	sync.RWMutex
+	types    map[reflect.Type]bool
+	opts     Options // Options scoped to each type in types
+	defaults Options // Options set by SetDefaultOptions
+
+	formatters map[reflect.Type]reflect.Value // Formatters set by RegisterFormatter
+}
+
+// RegisterOptions registers options that apply by default to all values of
+// the type of v, and to all values within them, such as their fields and
+// elements. Once registered, the options are used automatically by every call
+// to Equal and Diff, so that a package can declare how its own types are to be
+// compared (e.g., comparing an identifier type by its canonical form) without
+// every caller needing to pass the same options.
+//
+// Options passed to Equal or Diff take precedence over registered options:
+// the registered options are only consulted for values to which no explicit
+// option applies. The NoRegisteredOptions option disables registered options
+// for a single call. Only filterable options may be registered, and only one
+// set of options may be registered for each type.
+//
+// If v is a nil pointer to an interface type, such as (*proto.Message)(nil),
+// then the options apply to all values of the types that implement the
+// interface, and to all values within them. This is the extension point for
+// packages that integrate a whole family of types with Equal and Diff.
+// For example, a package for generated protocol buffer messages may register
+// a Comparer that compares all messages according to proto.Equal, such that
+// their internal fields are never compared directly.
+//
+// RegisterOptions is intended to be called from init functions.
+func RegisterOptions(v interface{}, opts ...Option) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("invalid nil type")
+	}
+	opt := &registeredOption{typ: t, opt: normalizeOption(Options(opts))}
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface && reflect.ValueOf(v).IsNil() {
+		opt.typ, opt.iface = t.Elem(), true
+	}
+
+	registered.Lock()
+	defer registered.Unlock()
+	if registered.types[opt.typ] {
+		panic(fmt.Sprintf("options for %v already registered", opt.typ))
+	}
+	if registered.types == nil {
+		registered.types = make(map[reflect.Type]bool)
+	}
+	registered.types[opt.typ] = true
+	opt.id = len(registered.opts)
+	registered.opts = append(registered.opts[:len(registered.opts):len(registered.opts)], opt)
+}
+
+// registeredOption is an option registered with RegisterOptions, which only
+// applies within values of its type.
+//
+// Whether the current node is within such a value is tracked by the state as
+// each step is pushed (see state.enterScopes), rather than by searching
+// the entire path at every node.
+type registeredOption struct {
+	core
+	id    int          // Index of the option within registered.opts
+	typ   reflect.Type // Registered type
+	iface bool         // Whether the option applies to implementations of typ
+	opt   Option       // Normalized registered options; may be nil
+}
+
+// match reports whether the option applies within values of type t.
+func (o *registeredOption) match(t reflect.Type) bool {
+	if o.iface {
+		return t != nil && t.Implements(o.typ)
+	}
+	return t == o.typ
+}
+
+func (o *registeredOption) filter(s *state, t reflect.Type, vx, vy reflect.Value) applicableOption {
+	if o.opt != nil && o.id < len(s.scopes) && s.scopes[o.id] > 0 {
+		return o.opt.filter(s, t, vx, vy)
+	}
+	return nil
+}
+
+func (o *registeredOption) String() string {
+	return fmt.Sprintf("RegisterOptions(%v, %v)", o.typ, o.opt)
+}
+
+// SetDefaultOptions sets options that apply by default to every call to
+// Equal and Diff in the process, replacing any previously set by
+// SetDefaultOptions. This allows conventions shared by an entire program or
+// test suite (e.g., ignoring all sync.Mutex values) to be stated once,
+// such as in TestMain, rather than at every call site.
+// Calling SetDefaultOptions with no options removes the default options.
+//
+// Default options are treated the same as options registered with
+// RegisterOptions, except that they apply to all values.
+// Thus, options passed to Equal or Diff take precedence over them, and
+// the NoRegisteredOptions option disables them for a single call.
+// Only filterable options may be used as default options.
+func SetDefaultOptions(opts ...Option) {
+	defaults := flattenOptions(nil, opts)
+
+	registered.Lock()
+	defer registered.Unlock()
+	registered.defaults = defaults
+}
+
+// RegisterFormatter registers a function that Diff uses by default to print
+// values of type T, where f must be a func(T) string. Once registered,
+// the function is used automatically by every call to Diff, as if the
+// Formatter option had been passed to it, so that a package can declare how
+// its own types are to be displayed (e.g., printing identifiers in
+// hexadecimal) without every caller needing to pass the same option.
+//
+// A Formatter option passed to Diff for the same type takes precedence over
+// the registered function, and the NoRegisteredOptions option disables
+// registered functions for a single call. Only one function may be registered
+// for each type. RegisterFormatter has no effect on Equal.
+//
+// RegisterFormatter is intended to be called from init functions.
+func RegisterFormatter(f interface{}) {
+	v := reflect.ValueOf(f)
+	if !function.IsType(v.Type(), function.Formatter) || v.IsNil() {
+		panic(fmt.Sprintf("invalid formatter function: %T", f))
+	}
+	t := v.Type().In(0)
+
+	registered.Lock()
+	defer registered.Unlock()
+	if _, ok := registered.formatters[t]; ok {
+		panic(fmt.Sprintf("formatter for %v already registered", t))
+	}
+	registered.formatters = withFormatter(registered.formatters, t, v)
+}
+
+// registeredFormatters returns all functions registered with RegisterFormatter.
+func registeredFormatters() map[reflect.Type]reflect.Value {
+	registered.RLock()
+	defer registered.RUnlock()
+	return registered.formatters
+}
+
+// registeredOptions returns all options registered with RegisterOptions
+// and SetDefaultOptions.
+func registeredOptions() Options {
+	registered.RLock()
+	defer registered.RUnlock()
+	if len(registered.defaults) == 0 {
+		return registered.opts
+	}
+	opts := append(Options(nil), registered.opts...)
+	return append(opts, registered.defaults...)
+}
+
+// NoRegisteredOptions returns an Option that disables all options registered
+// with RegisterOptions and SetDefaultOptions, as well as all functions
+// registered with RegisterFormatter and RegisterSurrogate, such that only
+// explicitly passed options are used.
+func NoRegisteredOptions() Option {
+	return noRegisteredOptions{}
+}
+
+type noRegisteredOptions struct{}
+
+func (noRegisteredOptions) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+// enterScopes records the registered options whose type is the type of step,
+// which is the step just pushed onto the current path, such that they apply
+// to the current node and all nodes beneath it. It returns the function that
+// must be called before the step is popped.
+func (s *state) enterScopes(step PathStep) (exit func()) {
+	var entered []int
+	for _, o := range s.scoped {
+		if o.match(step.Type()) {
+			s.scopes[o.id]++
+			entered = append(entered, o.id)
+		}
+	}
+	if len(entered) == 0 {
+		return func() {}
+	}
+	return func() {
+		for _, i := range entered {
+			s.scopes[i]--
+		}
+	}
+}
+
+// filterRegistered returns the registered option that applies to the current
+// node in place of opt, which was selected from the explicit options,
+// along with the registered option that it originates from.
+// It reports false if opt takes precedence. Since a validator selected from
+// the explicit options indicates that the values cannot be compared,
+// it is only overridden by registered options that ignore the current node.
+func (s *state) filterRegistered(opt applicableOption, t reflect.Type, vx, vy reflect.Value) (applicableOption, Option, bool) {
+	if len(s.registered) == 0 {
+		return nil, nil, false
+	}
+	if _, ok := opt.(validator); opt != nil && !ok {
+		return nil, nil, false
+	}
+	ropt, idx := s.registered.filterIndex(s, t, vx, vy)
+	if _, ok := ropt.(ignore); ok || (opt == nil && ropt != nil) {
+		var src Option
+		if idx >= 0 {
+			src = s.registered[idx]
+		}
+		return ropt, src, true
+	}
+	return nil, nil, false
+}
@@ -5,58 +5,605 @@
 package cmp
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 
+	"github.com/google/go-cmp/cmp/internal/diff"
+	"github.com/google/go-cmp/cmp/internal/function"
 	"github.com/google/go-cmp/cmp/internal/value"
 )
 
-type defaultReporter struct {
-	curPath Path
+// MaxDiffsPerPath returns an Option that limits the number of differences
+// that Diff reports beneath any single path prefix, other than the root, to n.
+// Further differences beneath that prefix are summarized by a single line
+// stating how many more there are. This keeps one badly corrupted sub-value
+// from consuming the entire report and hiding differences elsewhere.
+//
+// This option has no effect on Equal.
+func MaxDiffsPerPath(n int) Option {
+	if n <= 0 {
+		panic("limit must be a positive number")
+	}
+	return reportOption(func(r *defaultReporter) { r.maxPerPath = n })
+}
+
+// MaxDiffsPerContainer returns an Option that limits the number of differing
+// elements that Diff reports for any single slice, array, or map to n.
+// Further differing elements are summarized by a single line stating how many
+// more there are. Unlike the overall limit on the size of the report,
+// this keeps the report balanced across all containers in a large value.
+//
+// This option has no effect on Equal.
+func MaxDiffsPerContainer(n int) Option {
+	if n <= 0 {
+		panic("limit must be a positive number")
+	}
+	return reportOption(func(r *defaultReporter) { r.maxPerContainer = n })
+}
+
+// MaxReportSize returns an Option that limits the report from Diff to
+// approximately the given number of bytes and lines, after which further
+// differences are summarized by a single line stating how many more there are.
+// A zero limit leaves the corresponding default in place,
+// which is 4096 bytes and 256 lines.
+//
+// This option has no effect on Equal.
+func MaxReportSize(bytes, lines int) Option {
+	if bytes < 0 || lines < 0 {
+		panic("limit must be a non-negative number")
+	}
+	return reportOption(func(r *defaultReporter) {
+		if bytes > 0 {
+			r.maxBytes = bytes
+		}
+		if lines > 0 {
+			r.maxLines = lines
+		}
+	})
+}
+
+// MaxDiffs returns an Option that limits the number of differences that Diff
+// reports to n, after which further differences are summarized by a single
+// line stating how many more there are. The report is still subject to
+// the limits on its size set by MaxReportSize.
+//
+// Since further differences are not reported, Diff stops comparing the values
+// once it finds more than n differences, such that the summary line only
+// states the least number of further differences. Even so, all elements of
+// a slice are compared to determine which elements are to be aligned.
+// The comparison is not stopped early if the Verbose option or any option
+// that observes the entire comparison (e.g., Reporter or Strict) is used.
+//
+// This option has no effect on Equal.
+func MaxDiffs(n int) Option {
+	if n <= 0 {
+		panic("limit must be a positive number")
+	}
+	return reportOption(func(r *defaultReporter) { r.maxDiffs = n })
+}
+
+// Verbose returns an Option that makes Diff print every difference in full
+// for debugging deeply nested mismatches. The limits on the size of the report
+// and on the number of differences are lifted, including those set by
+// other options, and values are printed structurally along with the type of
+// every nested value, rather than by their String or Error methods.
+//
+// This option has no effect on Equal.
+func Verbose() Option {
+	return reportOption(func(r *defaultReporter) {
+		r.verbose = true
+		r.format.PrintPrimitiveType = true
+		r.format.PrintNestedTypes = true
+	})
+}
+
+// Colorize returns an Option that makes Diff print removed values in red and
+// added values in green using ANSI escape sequences, which makes long reports
+// easier to read on a terminal. It also applies to multi-line strings that are
+// reported line by line. Since a report is often written to a log or a file
+// rather than a terminal, color must be requested explicitly.
+//
+// This option has no effect on Equal.
+func Colorize() Option {
+	return reportOption(func(r *defaultReporter) { r.color = true })
+}
+
+// GroupDigits returns an Option that makes Diff print decimal integers with
+// their digits grouped in threes by underscores (e.g., 1_234_567), so that
+// large numbers that differ only slightly are easier to tell apart.
+//
+// This option has no effect on Equal.
+func GroupDigits() Option {
+	return reportOption(func(r *defaultReporter) { r.format.GroupDigits = true })
+}
+
+// FloatExponentRange returns an Option that makes Diff print floating-point
+// numbers in plain decimal notation if their decimal exponent is within
+// [min, max), and in exponent notation otherwise (e.g., 1e-07 has an exponent
+// of -7). By default, the range is that of the %v verb, which is [-4, 21).
+// Widening the range avoids exponents when comparing small-magnitude values
+// such as probabilities or monetary amounts.
+//
+// This option has no effect on Equal.
+func FloatExponentRange(min, max int) Option {
+	if min >= max {
+		panic("invalid exponent range")
+	}
+	return reportOption(func(r *defaultReporter) { r.format.PlainFloatRange = [2]int{min, max} })
+}
+
+// EnumNames returns an Option that makes Diff print values of an integer type T
+// by their name followed by their number (e.g., "StatusActive (2)"), where
+// names must be a map[T]string. Values of type T without an entry in names
+// are printed as usual. This is useful for enum-like types whose numeric
+// values are meaningless to readers, and takes precedence over any String
+// method of T.
+//
+// This option only affects how values are displayed and has no effect on Equal.
+func EnumNames(names interface{}) Option {
+	v := reflect.ValueOf(names)
+	if v.Kind() != reflect.Map || v.Type().Elem().Kind() != reflect.String || !isInteger(v.Type().Key()) {
+		panic(fmt.Sprintf("invalid enum name table: %T", names))
+	}
+	t := v.Type().Key()
+	return reportOption(func(r *defaultReporter) {
+		m := make(map[reflect.Type]reflect.Value, len(r.format.EnumNames)+1)
+		for t, v := range r.format.EnumNames {
+			m[t] = v
+		}
+		m[t] = v
+		r.format.EnumNames = m
+	})
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
 
+// ErrorDetails returns an Option that makes Diff print the structure of
+// error values in addition to their Error message. By default, only the
+// message is printed, unless two unequal errors have the same message.
+//
+// This option has no effect on Equal.
+func ErrorDetails() Option {
+	return reportOption(func(r *defaultReporter) { r.format.ErrorDetails = true })
+}
+
+// FieldNamesByTag returns an Option that makes Diff name struct fields
+// according to the struct tag with the given key (e.g., "json" or "yaml"),
+// both in the reported paths and in the printed values, so that the report
+// lines up with the serialized form of the values. Fields without a name in
+// the tag are named by their Go name.
+//
+// This option has no effect on Equal.
+func FieldNamesByTag(key string) Option {
+	if key == "" {
+		panic("invalid empty struct tag key")
+	}
+	return reportOption(func(r *defaultReporter) { r.format.FieldTag = key })
+}
+
+// Formatter returns an Option that makes Diff print values of type T
+// using the function f, which must be a func(T) string, in place of
+// the usual formatting of values (e.g., printing identifiers in hexadecimal).
+// The string returned by f is printed verbatim, and takes precedence over
+// any String method of T. Formatters for types registered with
+// RegisterFormatter are replaced by the formatter for the same type passed
+// to Diff, if any.
+//
+// This option only affects how values are displayed and has no effect on Equal.
+func Formatter(f interface{}) Option {
+	v := reflect.ValueOf(f)
+	if !function.IsType(v.Type(), function.Formatter) || v.IsNil() {
+		panic(fmt.Sprintf("invalid formatter function: %T", f))
+	}
+	t := v.Type().In(0)
+	return reportOption(func(r *defaultReporter) { r.format.Formatters = withFormatter(r.format.Formatters, t, v) })
+}
+
+// FormatValue returns an Option that makes Diff print values using the
+// function f, which reports the string to print for a value and true,
+// or false if the value is to be printed as usual. This allows overriding
+// how values of specific types are displayed (e.g., printing time.Time values
+// in RFC 3339 format, or byte slices in base64) without changing how
+// they are compared. The function f is called with every value that Diff
+// prints, including the fields and elements within printed values,
+// but not with interface values themselves, only with their dynamic values.
+// Values of types with a formatter set by the Formatter option or registered
+// with RegisterFormatter are printed by that formatter instead.
+//
+// If multiple FormatValue options are passed, the functions are called in
+// the order the options were passed, until one of them reports true.
+//
+// This option only affects how values are displayed and has no effect on Equal.
+func FormatValue(f func(v interface{}) (string, bool)) Option {
+	if f == nil {
+		panic("invalid nil format function")
+	}
+	return reportOption(func(r *defaultReporter) {
+		hooks := r.format.FormatHooks
+		r.format.FormatHooks = append(hooks[:len(hooks):len(hooks)], f)
+	})
+}
+
+// withFormatter returns a copy of m with f as the formatter for t.
+func withFormatter(m map[reflect.Type]reflect.Value, t reflect.Type, f reflect.Value) map[reflect.Type]reflect.Value {
+	m2 := make(map[reflect.Type]reflect.Value, len(m)+1)
+	for t, f := range m {
+		m2[t] = f
+	}
+	m2[t] = f
+	return m2
+}
+
+// reportOption is an Option that configures the report produced by Diff.
+type reportOption func(*defaultReporter)
+
+func (reportOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
+
+type defaultReporter struct {
 	diffs  []string // List of differences, possibly truncated
 	ndiffs int      // Total number of differences
+	nshown int      // Number of differences accounted for in diffs
 	nbytes int      // Number of bytes in diffs
 	nlines int      // Number of lines in diffs
+
+	format   value.FormatConfig // Base configuration for formatting values
+	maxBytes int                // Maximum bytes in diffs; zero for the default
+	maxLines int                // Maximum lines in diffs; zero for the default
+	maxDiffs int                // Maximum differences in diffs; zero if unlimited
+	verbose  bool               // Whether all limits are lifted
+	stopped  bool               // Whether the comparison stopped before all differences were found
+	color    bool               // Whether values are colorized with ANSI escapes
+	w        io.Writer          // Writer that differences are streamed to, rather than kept in diffs
+
+	// These fields are only used for limiting the differences under a path.
+	maxPerPath      int          // Maximum differences under a path; zero if unlimited
+	maxPerContainer int          // Maximum differing elements; zero if unlimited
+	path            Path         // The current path in the value tree
+	nodes           []reportNode // Counts for each step in path
+
+	// These fields are only used for reporting byte sequences as hexdumps.
+	depth int       // Depth of the current node in the value tree
+	hex   hexReport // The byte sequence being reported, if any
+}
+
+// reportNode counts the differences beneath a node in the value tree.
+type reportNode struct {
+	nreported   int // Number of differences reported
+	nsuppressed int // Number of differences summarized instead of reported
+
+	// These fields are only used for nodes that are containers or elements.
+	isElem           bool // Whether the node is an element of a container
+	admitted         bool // Whether the element counts towards nelems
+	summarized       bool // Whether the element counts towards nelemsSuppressed
+	nelems           int  // Number of differing elements reported
+	nelemsSuppressed int  // Number of differing elements summarized
+	nelemDiffs       int  // Number of differences within nelemsSuppressed
+}
+
+func (r *defaultReporter) limited() bool {
+	return !r.verbose && (r.maxPerPath > 0 || r.maxPerContainer > 0)
 }
 
 func (r *defaultReporter) PushStep(ps PathStep) {
-	r.curPath.push(ps)
+	r.depth++
+	if r.hex.depth > 0 {
+		r.hex.cancel(ps, r.depth)
+	}
+	if r.hex.depth == 0 && !r.verbose {
+		r.hex.push(ps, r.depth)
+	}
+	if r.limited() {
+		var isElem bool
+		switch ps.(type) {
+		case *sliceIndex, *mapIndex:
+			isElem = true
+		}
+		r.path = append(r.path, ps)
+		r.nodes = append(r.nodes, reportNode{isElem: isElem})
+	}
 }
-func (r *defaultReporter) Report(f reportFlags) {
+func (r *defaultReporter) Report(p Path, f reportFlags) {
 	if f&reportUnequal > 0 {
-		vx, vy := r.curPath.Last().Values()
-		r.report(vx, vy, r.curPath)
+		if r.hex.depth > 0 {
+			r.hex.add(p, r.format.FieldTag)
+			return
+		}
+		if r.suppress() {
+			return
+		}
+		vx, vy := p.Last().Values()
+		r.report(vx, vy, p, f)
 	}
 }
 func (r *defaultReporter) PopStep() {
-	r.curPath.pop()
+	if r.hex.depth == r.depth {
+		if r.hex.ndiffs > 0 {
+			r.ndiffs += r.hex.ndiffs
+			r.append(fmt.Sprintf("%s:\n%s", r.hex.path, r.hexDump(r.hex.x, r.hex.y)), r.hex.ndiffs)
+		}
+		r.hex = hexReport{}
+	}
+	r.depth--
+	if r.limited() {
+		last := len(r.nodes) - 1
+		if n := r.nodes[last].nelemDiffs; n > 0 {
+			r.append(fmt.Sprintf("%s:\n\t... %d more differing elements ...\n", r.path.goString(r.format.FieldTag), r.nodes[last].nelemsSuppressed), n)
+		}
+		if n := r.nodes[last].nsuppressed; n > 0 {
+			r.append(fmt.Sprintf("%s:\n\t... %d more differences under this path ...\n", r.path.goString(r.format.FieldTag), n), n)
+		}
+		r.path, r.nodes = r.path[:last], r.nodes[:last]
+	}
+}
+
+// suppress reports whether the current difference should be summarized
+// rather than reported because an ancestor has already reached its limit.
+func (r *defaultReporter) suppress() bool {
+	if !r.limited() {
+		return false
+	}
+	// The shallowest ancestor at its limit summarizes the difference.
+	for i := 0; i < len(r.nodes)-1; i++ {
+		// The root is skipped since limiting it would limit the entire report.
+		if i > 0 && r.maxPerPath > 0 && r.nodes[i].nreported >= r.maxPerPath {
+			r.nodes[i].nsuppressed++
+			r.ndiffs++
+			return true
+		}
+		e := &r.nodes[i+1]
+		if r.maxPerContainer > 0 && e.isElem && !e.admitted && r.nodes[i].nelems >= r.maxPerContainer {
+			if !e.summarized {
+				e.summarized = true
+				r.nodes[i].nelemsSuppressed++
+			}
+			r.nodes[i].nelemDiffs++
+			r.ndiffs++
+			return true
+		}
+	}
+	for i := range r.nodes {
+		r.nodes[i].nreported++
+		if i+1 < len(r.nodes) && r.nodes[i+1].isElem && !r.nodes[i+1].admitted {
+			r.nodes[i+1].admitted = true
+			r.nodes[i].nelems++
+		}
+	}
+	return false
 }
 
-func (r *defaultReporter) report(x, y reflect.Value, p Path) {
-	const maxBytes = 4096
-	const maxLines = 256
+func (r *defaultReporter) report(x, y reflect.Value, p Path, f reportFlags) {
 	r.ndiffs++
-	if r.nbytes < maxBytes && r.nlines < maxLines {
-		sx := value.Format(x, value.FormatConfig{UseStringer: true})
-		sy := value.Format(y, value.FormatConfig{UseStringer: true})
-		if sx == sy {
+	if r.canAppend() {
+		conf := r.format
+		conf.UseStringer = !r.verbose
+		conf.UseError = !r.verbose
+		sx := value.Format(x, conf)
+		sy := value.Format(y, conf)
+		if sx == sy && !r.verbose {
 			// Unhelpful output, so use more exact formatting.
-			sx = value.Format(x, value.FormatConfig{PrintPrimitiveType: true})
-			sy = value.Format(y, value.FormatConfig{PrintPrimitiveType: true})
+			conf = r.format
+			conf.PrintPrimitiveType = true
+			sx = value.Format(x, conf)
+			sy = value.Format(y, conf)
+		}
+		ps := p.goString(r.format.FieldTag)
+		var notes []string
+		switch p.Last().(type) {
+		case MapIndex:
+			// Differences in map entries are labeled by how the entry changed.
+			switch p.Edit() {
+			case Inserted:
+				r.append(fmt.Sprintf("%s (entry added):\n\t%s\n", ps, r.paint("+", "+: "+sy)), 1)
+				return
+			case Removed:
+				r.append(fmt.Sprintf("%s (entry removed):\n\t%s\n", ps, r.paint("-", "-: "+sx)), 1)
+				return
+			}
+			notes = append(notes, "entry modified")
+		case SliceIndex:
+			// Elements that were inserted or removed by the edit script
+			// only exist on one side, so only that side is printed.
+			switch p.Edit() {
+			case Inserted:
+				r.append(fmt.Sprintf("%s (element inserted):\n\t%s\n", ps, r.paint("+", "+: "+sy)), 1)
+				return
+			case Removed:
+				r.append(fmt.Sprintf("%s (element removed):\n\t%s\n", ps, r.paint("-", "-: "+sx)), 1)
+				return
+			}
+		}
+		if tx, ty := dynamicType(x), dynamicType(y); tx != nil && ty != nil && tx != ty {
+			notes = append(notes, fmt.Sprintf("type mismatch: %v vs %v", tx, ty))
+		}
+		if f&reportByDepth > 0 {
+			notes = append(notes, "maximum depth reached")
 		}
-		s := fmt.Sprintf("%#v:\n\t-: %s\n\t+: %s\n", p, sx, sy)
-		r.diffs = append(r.diffs, s)
-		r.nbytes += len(s)
-		r.nlines += strings.Count(s, "\n")
+		if len(notes) > 0 {
+			ps += " (" + strings.Join(notes, ", ") + ")"
+		}
+		if lx, ly, ok := multilineStrings(x, y); ok {
+			r.append(fmt.Sprintf("%s:\n%s", ps, r.lineDiff(lx, ly)), 1)
+			return
+		}
+		r.append(fmt.Sprintf("%s:\n\t%s\n\t%s\n", ps, r.paint("-", "-: "+sx), r.paint("+", "+: "+sy)), 1)
 	}
 }
 
+// multilineStrings reports the lines of x and y if both are strings and
+// at least one of them spans multiple lines. A trailing newline common to
+// both strings does not count as a line.
+func multilineStrings(x, y reflect.Value) (lx, ly []string, ok bool) {
+	for x.IsValid() && x.Kind() == reflect.Interface && !x.IsNil() {
+		x = x.Elem()
+	}
+	for y.IsValid() && y.Kind() == reflect.Interface && !y.IsNil() {
+		y = y.Elem()
+	}
+	if !x.IsValid() || !y.IsValid() || x.Kind() != reflect.String || y.Kind() != reflect.String {
+		return nil, nil, false
+	}
+	sx, sy := x.String(), y.String()
+	if strings.HasSuffix(sx, "\n") && strings.HasSuffix(sy, "\n") {
+		sx, sy = sx[:len(sx)-1], sy[:len(sy)-1]
+	}
+	lx, ly = strings.Split(sx, "\n"), strings.Split(sy, "\n")
+	return lx, ly, len(lx) > 1 || len(ly) > 1
+}
+
+// lineDiff formats the differences between the lines of two strings as a
+// unified diff, where removed lines are prefixed by "-", inserted lines
+// by "+", and unchanged lines by a space. Only a few unchanged lines around
+// each change are printed, while the remainder are summarized.
+func (r *defaultReporter) lineDiff(lx, ly []string) string {
+	const context = 3 // Number of unchanged lines printed around changes
+	es := diff.Difference(len(lx), len(ly), func(ix, iy int) diff.Result {
+		if lx[ix] == ly[iy] {
+			return diff.Result{NumSame: 1}
+		}
+		return diff.Result{NumDiff: 1}
+	})
+
+	var b bytes.Buffer
+	var ix, iy int
+	for i := 0; i < len(es); {
+		if es[i] == diff.Identity {
+			// Print unchanged lines that are near a change on either side.
+			var n int
+			for i+n < len(es) && es[i+n] == diff.Identity {
+				n++
+			}
+			head, tail := context, context
+			if i == 0 {
+				head = 0
+			}
+			if i+n == len(es) {
+				tail = 0
+			}
+			if head+tail >= n {
+				b.WriteString(r.formatLines(" ", lx[ix:ix+n]))
+			} else {
+				b.WriteString(r.formatLines(" ", lx[ix:ix+head]))
+				fmt.Fprintf(&b, "\t  ... %d identical lines ...\n", n-head-tail)
+				b.WriteString(r.formatLines(" ", lx[ix+n-tail:ix+n]))
+			}
+			i, ix, iy = i+n, ix+n, iy+n
+			continue
+		}
+
+		// Group all changed lines such that removals precede insertions.
+		var nx, ny int
+		for ; i < len(es) && es[i] != diff.Identity; i++ {
+			switch es[i] {
+			case diff.UniqueX:
+				nx++
+			case diff.UniqueY:
+				ny++
+			case diff.Modified:
+				nx, ny = nx+1, ny+1
+			}
+		}
+		b.WriteString(r.formatLines("-", lx[ix:ix+nx]))
+		b.WriteString(r.formatLines("+", ly[iy:iy+ny]))
+		ix, iy = ix+nx, iy+ny
+	}
+	return b.String()
+}
+
+func (r *defaultReporter) formatLines(prefix string, lines []string) string {
+	var b bytes.Buffer
+	for _, l := range lines {
+		b.WriteString("\t" + r.paint(prefix, prefix+" "+l) + "\n")
+	}
+	return b.String()
+}
+
+// ANSI escape sequences used by the Colorize option.
+const (
+	colorRemoved = "\x1b[31m" // Red
+	colorAdded   = "\x1b[32m" // Green
+	colorReset   = "\x1b[0m"
+)
+
+// paint wraps s in the color for the given prefix, which is "-" for
+// removed values and "+" for added values, if the report is colorized.
+func (r *defaultReporter) paint(prefix, s string) string {
+	if !r.color {
+		return s
+	}
+	switch prefix {
+	case "-":
+		return colorRemoved + s + colorReset
+	case "+":
+		return colorAdded + s + colorReset
+	}
+	return s
+}
+
+// dynamicType reports the type of the value held by the interface v.
+// It returns nil if v is not a non-nil interface.
+func dynamicType(v reflect.Value) reflect.Type {
+	if v.Kind() != reflect.Interface || v.IsNil() {
+		return nil
+	}
+	return v.Elem().Type()
+}
+
+// canAppend reports whether the report is still within its size budget.
+func (r *defaultReporter) canAppend() bool {
+	const defaultMaxBytes = 4096
+	const defaultMaxLines = 256
+	if r.verbose {
+		return true
+	}
+	if r.w != nil {
+		return r.maxDiffs == 0 || r.nshown < r.maxDiffs
+	}
+	maxBytes, maxLines := r.maxBytes, r.maxLines
+	if maxBytes == 0 {
+		maxBytes = defaultMaxBytes
+	}
+	if maxLines == 0 {
+		maxLines = defaultMaxLines
+	}
+	return r.nbytes < maxBytes && r.nlines < maxLines && (r.maxDiffs == 0 || r.nshown < r.maxDiffs)
+}
+
+// append adds s, which accounts for n differences, to the report
+// if it is still within its size budget.
+func (r *defaultReporter) append(s string, n int) {
+	if !r.canAppend() {
+		return
+	}
+	if r.w != nil {
+		io.WriteString(r.w, s)
+		r.nshown += n
+		return
+	}
+	r.diffs = append(r.diffs, s)
+	r.nshown += n
+	r.nbytes += len(s)
+	r.nlines += strings.Count(s, "\n")
+}
+
 func (r *defaultReporter) String() string {
 	s := strings.Join(r.diffs, "")
-	if r.ndiffs == len(r.diffs) {
+	if r.ndiffs == r.nshown {
 		return s
 	}
-	return fmt.Sprintf("%s... %d more differences ...", s, r.ndiffs-len(r.diffs))
+	if r.stopped {
+		return fmt.Sprintf("%s... at least %d more differences ...", s, r.ndiffs-r.nshown)
+	}
+	return fmt.Sprintf("%s... %d more differences ...", s, r.ndiffs-r.nshown)
 }
@@ -0,0 +1,139 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// minHexBytes is the minimum length of a byte sequence for it to be
+	// reported as a hexdump rather than element by element.
+	minHexBytes = 4 * hexRowBytes
+
+	hexRowBytes = 8                                   // Number of bytes in each row of a hexdump
+	hexRowWidth = 3*hexRowBytes - 1 + 4 + hexRowBytes // Width of the formatted row
+)
+
+// hexReport accumulates the differences within a byte sequence, such that
+// they are reported together as a single hexdump of the differing regions.
+type hexReport struct {
+	depth  int    // Depth of the byte sequence; zero if none
+	x, y   []byte // Contents of the byte sequence in x and y
+	path   string // The path to the byte sequence
+	ndiffs int    // Number of differences within the byte sequence
+}
+
+// push starts accumulating the differences beneath ps if it is a byte slice
+// or array that is large enough to be reported as a hexdump.
+func (h *hexReport) push(ps PathStep, depth int) {
+	t := ps.Type()
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || t.Elem().Kind() != reflect.Uint8 {
+		return
+	}
+	vx, vy := ps.Values()
+	if !vx.IsValid() || !vy.IsValid() || (t.Kind() == reflect.Slice && (vx.IsNil() || vy.IsNil())) {
+		return // Missing and nil values are clearer when formatted as usual
+	}
+	if vx.Len() < minHexBytes && vy.Len() < minHexBytes {
+		return
+	}
+	*h = hexReport{depth: depth, x: bytesOf(vx), y: bytesOf(vy)}
+}
+
+// cancel stops accumulating the differences beneath the byte sequence if ps
+// is not one of its elements (e.g., since a Transformer applied to it).
+func (h *hexReport) cancel(ps PathStep, depth int) {
+	if _, ok := ps.(*sliceIndex); !ok && depth == h.depth+1 {
+		*h = hexReport{}
+	}
+}
+
+// add records a difference at p, which is at or beneath the byte sequence.
+func (h *hexReport) add(p Path, tag string) {
+	if h.ndiffs == 0 {
+		h.path = p[:h.depth].goString(tag)
+	}
+	h.ndiffs++
+}
+
+func bytesOf(v reflect.Value) []byte {
+	b := make([]byte, v.Len())
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+	return b
+}
+
+// hexDump formats the rows that differ between bx and by side by side,
+// where consecutive identical rows are elided.
+func (r *defaultReporter) hexDump(bx, by []byte) string {
+	var b bytes.Buffer
+	hx, hy := fmt.Sprintf("-: %d bytes", len(bx)), fmt.Sprintf("+: %d bytes", len(by))
+	fmt.Fprintf(&b, "\t%8s  %s  %s\n", "", r.paint("-", fmt.Sprintf("%-*s", hexRowWidth, hx)), r.paint("+", hy))
+	n := len(bx)
+	if len(by) > n {
+		n = len(by)
+	}
+	var nsame int
+	for off := 0; off < n; off += hexRowBytes {
+		rx, ry := hexRow(bx, off), hexRow(by, off)
+		if len(rx) == len(ry) && bytes.Equal(rx, ry) {
+			nsame += len(rx)
+			continue
+		}
+		if nsame > 0 {
+			fmt.Fprintf(&b, "\t  ... %d identical bytes ...\n", nsame)
+			nsame = 0
+		}
+		sx := fmt.Sprintf("%-*s", hexRowWidth, formatHexRow(rx))
+		if len(rx) > 0 {
+			sx = r.paint("-", sx)
+		}
+		sy := formatHexRow(ry)
+		if len(ry) > 0 {
+			sy = r.paint("+", sy)
+		}
+		b.WriteString(strings.TrimRight(fmt.Sprintf("\t%08x  %s  %s", off, sx, sy), " ") + "\n")
+	}
+	if nsame > 0 {
+		fmt.Fprintf(&b, "\t  ... %d identical bytes ...\n", nsame)
+	}
+	return b.String()
+}
+
+// hexRow returns the row of b that starts at off, which is empty if
+// b ends before off.
+func hexRow(b []byte, off int) []byte {
+	if off >= len(b) {
+		return nil
+	}
+	if end := off + hexRowBytes; end < len(b) {
+		return b[off:end]
+	}
+	return b[off:]
+}
+
+// formatHexRow formats b as hexadecimal bytes followed by their ASCII
+// representation, where non-printable characters are shown as '.'.
+// It returns an empty string if b is empty.
+func formatHexRow(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	hex := make([]string, len(b))
+	ascii := make([]byte, len(b))
+	for i, c := range b {
+		hex[i] = fmt.Sprintf("%02x", c)
+		ascii[i] = '.'
+		if c >= 0x20 && c < 0x7f {
+			ascii[i] = c
+		}
+	}
+	return fmt.Sprintf("%-*s  |%s|", 3*hexRowBytes-1, strings.Join(hex, " "), ascii)
+}
@@ -0,0 +1,355 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// DiffGoSyntax returns a report of the differences between x and y where both
+// values are printed in full as Go expressions, formatted as by gofmt, so that
+// the value printed for y can be copied into source code as is (e.g., to
+// update the expected value of a failing test).
+// It returns an empty string if and only if Equal returns true for
+// the same inputs.
+//
+// For example:
+//
+//	-: cmp_test.User{
+//		Name: "Alice",
+//	}
+//	+: cmp_test.User{
+//		Name:   "Bob",
+//		Groups: []string{"admin"},
+//	}
+//
+// Types are qualified by the name of their package, which must be removed for
+// types declared in the package that the value is pasted into.
+// Zero-valued struct fields are omitted, while unexported fields are printed
+// even though they can only be set within their own package. Values that cannot be written as
+// Go expressions, such as non-nil functions and channels, are printed as nil
+// followed by a comment, as are references that form a cycle.
+func DiffGoSyntax(x, y interface{}, opts ...Option) string {
+	s := newState(opts)
+	s.stopAtFirstDiff()
+	step := s.compareRoot(x, y)
+	if s.result.Equal() {
+		return ""
+	}
+	vx, vy := step.Values()
+	return "-: " + formatGoSyntax(vx) + "\n+: " + formatGoSyntax(vy) + "\n"
+}
+
+// formatGoSyntax formats v as a Go expression, formatted as by gofmt.
+func formatGoSyntax(v reflect.Value) string {
+	var p goSyntaxPrinter
+	p.print(v, contextUntyped)
+	const prefix = "package p\n\nvar _ = "
+	b, err := format.Source([]byte(prefix + p.b.String()))
+	if err != nil {
+		return p.b.String() // Should not happen, but is still readable
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(string(b), prefix), "\n")
+}
+
+// The types of untyped constants by default, which need not be converted to.
+var (
+	boolType       = reflect.TypeOf(false)
+	intType        = reflect.TypeOf(0)
+	float64Type    = reflect.TypeOf(0.0)
+	complex128Type = reflect.TypeOf(0i)
+	stringType     = reflect.TypeOf("")
+)
+
+// goSyntaxPrinter prints values as Go expressions, where elements of
+// composite literals are printed on separate lines for gofmt to indent.
+type goSyntaxPrinter struct {
+	b       bytes.Buffer
+	visited map[value.Pointer]bool // Pointers, slices, and maps on the current path
+}
+
+// goSyntaxContext is the context in which a Go expression is printed,
+// which determines whether its type must be printed.
+type goSyntaxContext int
+
+const (
+	// contextUntyped requires the type of the expression to be printed,
+	// such as for the dynamic value of an interface.
+	contextUntyped goSyntaxContext = iota
+	// contextTyped is where the type of the expression is known, such as for
+	// a struct field, so that constants and nil need not be converted.
+	contextTyped
+	// contextElided is where the type of a composite literal may be elided,
+	// such as for the elements, keys, and values of a composite literal.
+	contextElided
+)
+
+// print prints v in the given context.
+func (p *goSyntaxPrinter) print(v reflect.Value, ctx goSyntaxContext) {
+	if !v.IsValid() {
+		p.b.WriteString("nil")
+		return
+	}
+	t := v.Type()
+	typed := ctx != contextUntyped
+	switch t.Kind() {
+	case reflect.Bool:
+		p.constant(t, strconv.FormatBool(v.Bool()), typed || t == boolType)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.constant(t, strconv.FormatInt(v.Int(), 10), typed || t == intType)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.constant(t, strconv.FormatUint(v.Uint(), 10), typed)
+	case reflect.Float32, reflect.Float64:
+		// NaN and infinities are not constants, but rather float64 values.
+		f := v.Float()
+		finite := !math.IsNaN(f) && !math.IsInf(f, 0)
+		p.constant(t, formatGoFloat(f, t.Bits()), (typed && finite) || t == float64Type)
+	case reflect.Complex64, reflect.Complex128:
+		c, bits := v.Complex(), t.Bits()/2
+		p.constant(t, fmt.Sprintf("complex(%s, %s)", formatGoFloat(real(c), bits), formatGoFloat(imag(c), bits)), typed || t == complex128Type)
+	case reflect.String:
+		p.constant(t, strconv.Quote(v.String()), typed || t == stringType)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		p.nil(t, typed)
+		if v.Pointer() != 0 {
+			fmt.Fprintf(&p.b, " /* non-nil %v */", t.Kind())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			p.b.WriteString("nil")
+			return
+		}
+		p.print(v.Elem(), contextUntyped)
+	case reflect.Ptr:
+		if v.IsNil() {
+			p.nil(t, typed)
+			return
+		}
+		if !p.push(v) {
+			p.nil(t, typed)
+			p.b.WriteString(" /* cycle */")
+			return
+		}
+		defer p.pop(v)
+		switch t.Elem().Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+			if ctx != contextElided {
+				p.b.WriteString("&")
+			}
+			p.print(v.Elem(), ctx)
+		default:
+			// There is no literal for a pointer to any other kind.
+			fmt.Fprintf(&p.b, "func() %v { v := ", t)
+			p.print(v.Elem(), contextUntyped)
+			p.b.WriteString("; return &v }()")
+		}
+	case reflect.Struct:
+		p.typ(t, ctx)
+		p.b.WriteString("{")
+		var n int
+		for i := 0; i < v.NumField(); i++ {
+			if isZeroValue(v.Field(i)) {
+				continue
+			}
+			if n++; n == 1 {
+				p.b.WriteString("\n")
+			}
+			p.b.WriteString(t.Field(i).Name + ": ")
+			p.print(v.Field(i), contextTyped)
+			p.b.WriteString(",\n")
+		}
+		p.b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice {
+			if v.IsNil() {
+				p.nil(t, typed)
+				return
+			}
+			if !p.push(v) {
+				p.nil(t, typed)
+				p.b.WriteString(" /* cycle */")
+				return
+			}
+			defer p.pop(v)
+		}
+		p.typ(t, ctx)
+		p.b.WriteString("{")
+		if isGoSyntaxScalar(t.Elem()) {
+			// Elements of basic kinds are printed on a single line.
+			for i := 0; i < v.Len(); i++ {
+				if i > 0 {
+					p.b.WriteString(", ")
+				}
+				p.print(v.Index(i), contextElided)
+			}
+		} else {
+			if v.Len() > 0 {
+				p.b.WriteString("\n")
+			}
+			for i := 0; i < v.Len(); i++ {
+				p.print(v.Index(i), contextElided)
+				p.b.WriteString(",\n")
+			}
+		}
+		p.b.WriteString("}")
+	case reflect.Map:
+		if v.IsNil() {
+			p.nil(t, typed)
+			return
+		}
+		if !p.push(v) {
+			p.nil(t, typed)
+			p.b.WriteString(" /* cycle */")
+			return
+		}
+		defer p.pop(v)
+		p.typ(t, ctx)
+		p.b.WriteString("{")
+		if v.Len() > 0 {
+			p.b.WriteString("\n")
+		}
+		for _, k := range value.SortKeys(v.MapKeys()) {
+			p.print(k, contextElided)
+			p.b.WriteString(": ")
+			p.print(v.MapIndex(k), contextElided)
+			p.b.WriteString(",\n")
+		}
+		p.b.WriteString("}")
+	default:
+		panic(fmt.Sprintf("%v kind not handled", t.Kind()))
+	}
+}
+
+// isGoSyntaxScalar reports whether values of type t are printed as
+// a single constant.
+func isGoSyntaxScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+// constant prints the literal s of a value of type t, which is converted
+// to t unless the type is implied.
+func (p *goSyntaxPrinter) constant(t reflect.Type, s string, implied bool) {
+	if implied {
+		p.b.WriteString(s)
+		return
+	}
+	p.conversion(t, s)
+}
+
+// nil prints the nil value of type t, which is converted to t
+// unless the type is implied.
+func (p *goSyntaxPrinter) nil(t reflect.Type, implied bool) {
+	if implied || t.Kind() == reflect.Interface {
+		p.b.WriteString("nil")
+		return
+	}
+	p.conversion(t, "nil")
+}
+
+// conversion prints the conversion of s to type t.
+func (p *goSyntaxPrinter) conversion(t reflect.Type, s string) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.Func:
+		fmt.Fprintf(&p.b, "(%v)(%s)", t, s)
+	default:
+		fmt.Fprintf(&p.b, "%v(%s)", t, s)
+	}
+}
+
+// typ prints the type of a composite literal, unless it may be elided.
+func (p *goSyntaxPrinter) typ(t reflect.Type, ctx goSyntaxContext) {
+	if ctx != contextElided {
+		p.b.WriteString(t.String())
+	}
+}
+
+// push records that the reference v is on the current path,
+// and reports false if it already was, since it forms a cycle.
+func (p *goSyntaxPrinter) push(v reflect.Value) bool {
+	k := value.PointerOf(v)
+	if p.visited[k] {
+		return false
+	}
+	if p.visited == nil {
+		p.visited = make(map[value.Pointer]bool)
+	}
+	p.visited[k] = true
+	return true
+}
+
+func (p *goSyntaxPrinter) pop(v reflect.Value) {
+	delete(p.visited, value.PointerOf(v))
+}
+
+// formatGoFloat formats f as a Go expression of a floating-point value
+// with the given precision, which is always a floating-point constant
+// unless f is NaN or infinite.
+func formatGoFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, +1):
+		return "math.Inf(+1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+// isZeroValue reports whether v is the zero value of its type.
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0 && !math.Signbit(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.UnsafePointer:
+		return v.Pointer() == 0
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZeroValue(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZeroValue(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
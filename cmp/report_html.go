@@ -0,0 +1,148 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// DiffHTML writes a standalone HTML page to w that reports the comparison of
+// x and y with the given options. The page presents every visited value as a
+// collapsible tree, where differing nodes are expanded and highlighted.
+// It provides filters for only showing differences or also showing ignored
+// nodes, and a search box for finding nodes by path. The page has no external
+// dependencies, making it suitable for attaching as a build artifact.
+//
+// The options that configure how Diff formats values and paths, such as
+// Formatter and FieldNamesByTag, also apply to DiffHTML.
+//
+// Do not depend on the structure of the page being stable.
+func DiffHTML(w io.Writer, x, y interface{}, opts ...Option) error {
+	s := newState(opts)
+	r := s.newReporter()
+	root := s.diffTree(x, y)
+	conf := r.format
+	conf.UseStringer = true
+	conf.UseError = true
+	return htmlTemplate.Execute(w, htmlReport{
+		NumDiffs: root.numDiffs(),
+		Root:     newHTMLNode(root, conf),
+	})
+}
+
+type htmlReport struct {
+	NumDiffs int
+	Root     htmlNode
+}
+
+type htmlNode struct {
+	Name     string // Path step to this node
+	Path     string // Full path to this node
+	Class    string // One of "eq", "ne", or "ig"
+	NumDiffs int
+	X, Y     string // Formatted values, only set for leaf nodes
+	Children []htmlNode
+}
+
+// newHTMLNode returns the htmlNode for n, formatting values with conf.
+func newHTMLNode(n *diffNode, conf value.FormatConfig) htmlNode {
+	p := n.path()
+	hn := htmlNode{
+		Name:     n.step.String(),
+		Path:     p.goString(conf.FieldTag),
+		Class:    "eq",
+		NumDiffs: n.numDiffs(),
+	}
+	if len(p) == 1 {
+		hn.Name = hn.Path
+	}
+	if sf, ok := n.step.(*structField); ok && conf.FieldTag != "" && len(p) > 1 {
+		if name := value.FieldTagName(p.Index(-2).Type().Field(sf.idx), conf.FieldTag); name != "" {
+			hn.Name = "." + name
+		}
+	}
+	switch {
+	case n.flags&reportIgnored > 0:
+		hn.Class = "ig"
+	case hn.NumDiffs > 0:
+		hn.Class = "ne"
+	}
+	if len(n.children) == 0 && n.flags&reportIgnored == 0 {
+		vx, vy := n.step.Values()
+		hn.X = value.Format(vx, conf)
+		hn.Y = value.Format(vy, conf)
+	}
+	for _, c := range n.children {
+		hn.Children = append(hn.Children, newHTMLNode(c, conf))
+	}
+	return hn
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cmp report: {{.NumDiffs}} differences</title>
+<style>
+body { font-family: sans-serif; }
+ul { list-style: none; padding-left: 1.5em; margin: 0; }
+summary, .leaf { font-family: monospace; white-space: pre-wrap; }
+.ne > details > summary, .ne > .leaf { color: #a00; }
+.ig > details > summary, .ig > .leaf { color: #888; font-style: italic; }
+.x { background: #fdd; }
+.y { background: #dfd; }
+body.diffs-only li.eq { display: none; }
+body:not(.show-ignored) li.ig { display: none; }
+li.nomatch { display: none; }
+#controls { margin-bottom: 1em; }
+</style>
+</head>
+<body class="show-ignored">
+<h1>{{.NumDiffs}} differences</h1>
+<div id="controls">
+<label><input type="checkbox" id="diffs-only"> Only show differences</label>
+<label><input type="checkbox" id="show-ignored" checked> Show ignored</label>
+<input type="search" id="search" placeholder="Search paths">
+</div>
+<ul id="tree">{{template "node" .Root}}</ul>
+<script>
+(function() {
+	var body = document.body;
+	document.getElementById("diffs-only").onchange = function() {
+		body.classList.toggle("diffs-only", this.checked);
+	};
+	document.getElementById("show-ignored").onchange = function() {
+		body.classList.toggle("show-ignored", this.checked);
+	};
+	document.getElementById("search").oninput = function() {
+		var text = this.value;
+		var items = document.querySelectorAll("#tree li");
+		for (var i = items.length - 1; i >= 0; i--) {
+			var li = items[i];
+			var match = text === "" || li.dataset.path.indexOf(text) >= 0 ||
+				li.querySelector("li:not(.nomatch)") !== null;
+			li.classList.toggle("nomatch", !match);
+			if (match && text !== "") {
+				var d = li.querySelector("details");
+				if (d) { d.open = true; }
+			}
+		}
+	};
+})();
+</script>
+</body>
+</html>
+{{define "node"}}<li class="{{.Class}}" data-path="{{.Path}}">
+{{- if .Children}}<details{{if .NumDiffs}} open{{end}}><summary>{{.Name}}{{if .NumDiffs}} ({{.NumDiffs}} differences){{end}}</summary>
+<ul>{{range .Children}}{{template "node" .}}{{end}}</ul></details>
+{{- else}}<div class="leaf">{{.Name}}:
+{{- if eq .Class "ig"}} (ignored)
+{{- else if eq .Class "ne"}} <span class="x">-: {{.X}}</span> <span class="y">+: {{.Y}}</span>
+{{- else}} {{.X}}{{end}}</div>
+{{- end}}</li>
+{{end}}`))
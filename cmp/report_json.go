@@ -0,0 +1,65 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"encoding/json"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// DiffJSON returns the differences between x and y as a JSON array for
+// consumption by other programs, such as continuous integration systems.
+// The array contains an object for every unequal node in the order that they
+// were compared, which is empty if and only if Equal returns true for
+// the same inputs. Each object has the following members:
+//	• "path": the GoString of the Path to the node
+//	• "kind": one of "modified", "inserted", or "removed"
+//	• "old": the formatted value of the node in x, unless it was inserted
+//	• "new": the formatted value of the node in y, unless it was removed
+//
+// Values are formatted in the same way as by Diff, including the effects of
+// options such as FieldNamesByTag, but without any limits on the size of
+// the output. The members of each object are always in the order above.
+func DiffJSON(x, y interface{}, opts ...Option) ([]byte, error) {
+	s := newState(opts)
+	dr := s.newReporter()
+	r := new(differenceReporter)
+	s.reporters = append(s.reporters, reporterOption{r})
+	s.compareRoot(x, y)
+
+	conf := dr.format
+	conf.UseStringer = true
+	conf.UseError = true
+	diffs := []jsonDifference{} // Marshal an empty list as [] rather than null
+	for _, d := range r.diffs {
+		if d.Ignored {
+			continue
+		}
+		sx, sy := value.Format(d.X, conf), value.Format(d.Y, conf)
+		if sx == sy {
+			// Unhelpful output, so use more exact formatting.
+			conf := dr.format
+			conf.PrintPrimitiveType = true
+			sx, sy = value.Format(d.X, conf), value.Format(d.Y, conf)
+		}
+		jd := jsonDifference{Path: d.Path.goString(conf.FieldTag), Kind: d.Edit().String()}
+		if d.X.IsValid() {
+			jd.Old = &sx
+		}
+		if d.Y.IsValid() {
+			jd.New = &sy
+		}
+		diffs = append(diffs, jd)
+	}
+	return json.Marshal(diffs)
+}
+
+type jsonDifference struct {
+	Path string  `json:"path"`
+	Kind string  `json:"kind"`
+	Old  *string `json:"old,omitempty"`
+	New  *string `json:"new,omitempty"`
+}
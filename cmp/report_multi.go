@@ -0,0 +1,74 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report accumulates the differences of many comparisons, such as those of
+// every case in a table-driven test, into a single report. Every comparison
+// that finds differences is labeled, and all comparisons share the same
+// budget for the size of the report.
+//
+// A Report must be created with NewReport and is not safe for concurrent use.
+type Report struct {
+	opts    []Option
+	r       *defaultReporter
+	ncalls  int // Number of comparisons performed
+	nfailed int // Number of comparisons that found differences
+}
+
+// NewReport returns an empty Report, where opts are used for every comparison.
+func NewReport(opts ...Option) *Report {
+	s := newState(opts)
+	return &Report{opts: opts, r: s.newReporter()}
+}
+
+// Compare compares x and y using the options of the Report and any
+// additional opts. If they are unequal, it adds the differences to the report
+// under the given label. It reports whether x and y are equal.
+func (r *Report) Compare(label string, x, y interface{}, opts ...Option) bool {
+	s := newState(append(append([]Option(nil), r.opts...), opts...))
+	s.reporters = append(s.reporters, reporterOption{r.r})
+
+	// Speculatively add the label, which is removed if x and y are equal.
+	dr := r.r
+	ndiffs, nshown, nbytes, nlines := len(dr.diffs), dr.nshown, dr.nbytes, dr.nlines
+	dr.append(fmt.Sprintf("--- %s ---\n", label), 0)
+	s.compareRoot(x, y)
+	if s.result.Equal() {
+		dr.diffs, dr.nshown, dr.nbytes, dr.nlines = dr.diffs[:ndiffs], nshown, nbytes, nlines
+	} else {
+		r.nfailed++
+	}
+	r.ncalls++
+	return s.result.Equal()
+}
+
+// Equal reports whether all comparisons found their values to be equal.
+func (r *Report) Equal() bool {
+	return r.nfailed == 0
+}
+
+// NumCompared reports the number of comparisons performed,
+// and how many of them found differences.
+func (r *Report) NumCompared() (total, failed int) {
+	return r.ncalls, r.nfailed
+}
+
+// String returns a human-readable report of the differences found by all
+// comparisons. It returns an empty string if and only if Equal returns true.
+func (r *Report) String() string {
+	if r.Equal() {
+		return ""
+	}
+	d := r.r.String()
+	if !strings.HasSuffix(d, "\n") {
+		d += "\n" // The report was truncated
+	}
+	return fmt.Sprintf("%s%d of %d comparisons found differences\n", d, r.nfailed, r.ncalls)
+}
@@ -0,0 +1,918 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"go/parser"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ancestorReporter records the name of the parent struct of every unequal
+// leaf node, which is only obtainable from the ancestors in the Path.
+type ancestorReporter struct{ names []string }
+
+func (r *ancestorReporter) PushStep(PathStep) {}
+func (r *ancestorReporter) Report(p Path, f reportFlags) {
+	if f&reportUnequal == 0 {
+		return
+	}
+	for i := len(p) - 1; i >= 0; i-- {
+		vx, vy := p[i].Values()
+		if vx.Kind() == reflect.Struct && vx.Type().Name() == "node" {
+			r.names = append(r.names, fmt.Sprintf("%v/%v", vx.Field(0), vy.Field(0)))
+			return
+		}
+	}
+}
+func (r *ancestorReporter) PopStep() {}
+
+func TestReporterAncestors(t *testing.T) {
+	type node struct {
+		Name  string
+		Value int
+	}
+	x := []node{{"a", 1}, {"b", 2}, {"c", 3}}
+	y := []node{{"a", 1}, {"b", 5}, {"c", 6}}
+
+	r := new(ancestorReporter)
+	Equal(x, y, reporter(r))
+	got := fmt.Sprint(r.names)
+	want := "[b/b c/c]"
+	if got != want {
+		t.Errorf("ancestor names = %v, want %v", got, want)
+	}
+}
+
+func TestDiffHTML(t *testing.T) {
+	type node struct {
+		Name  string
+		Value int
+		Skip  string
+	}
+	x := []node{{"a", 1, "x"}, {"b", 2, "x"}}
+	y := []node{{"a", 1, "y"}, {"b", 5, "y"}}
+
+	var b bytes.Buffer
+	opts := []Option{FilterPath(func(p Path) bool { return p.Last().String() == ".Skip" }, Ignore())}
+	if err := DiffHTML(&b, x, y, opts...); err != nil {
+		t.Fatalf("DiffHTML error: %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>cmp report: 1 differences</title>",
+		`<li class="ne" data-path="{[]cmp.node}[1].Value">`,
+		`<span class="x">-: 2</span> <span class="y">+: 5</span>`,
+		`<li class="eq" data-path="{[]cmp.node}[0].Value">`,
+		`<li class="ig" data-path="{[]cmp.node}[1].Skip">`,
+		`<details open><summary>{[]cmp.node} (1 differences)</summary>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("DiffHTML output missing %q:\n%s", want, got)
+		}
+	}
+
+	// Values and paths are formatted according to the report options.
+	b.Reset()
+	type tagged struct {
+		Value int `json:"value"`
+	}
+	opts = []Option{Formatter(func(v int) string { return fmt.Sprintf("#%d", v) }), FieldNamesByTag("json")}
+	if err := DiffHTML(&b, tagged{2}, tagged{5}, opts...); err != nil {
+		t.Fatalf("DiffHTML error: %v", err)
+	}
+	got = b.String()
+	for _, want := range []string{
+		`<li class="ne" data-path="{cmp.tagged}.value">`,
+		`<span class="x">-: #2</span> <span class="y">+: #5</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("DiffHTML output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDiffTree(t *testing.T) {
+	type user struct {
+		Name   string
+		Age    int
+		Groups []string
+		Attrs  map[string]interface{}
+		Next   *user
+	}
+	x := user{Name: "Alice", Age: 5, Groups: []string{"a", "b"}, Attrs: map[string]interface{}{"j": 1, "k": 1}, Next: &user{Name: "c"}}
+	y := user{Name: "Bob", Age: 5, Groups: []string{"a", "b", "admin"}, Attrs: map[string]interface{}{"j": 1, "k": 2}, Next: &user{Name: "d"}}
+
+	tests := []struct {
+		x, y interface{}
+		opts []Option
+		want string
+	}{{
+		x: x,
+		y: y,
+		want: `  cmp.user{
+- 	Name: "Alice",
++ 	Name: "Bob",
+  	...
+  	Groups: []string{
+  		...
++ 		[?->2]: "admin",
+  	},
+  	Attrs: map[string]interface {}{
+  		...
+- 		"k": 1,
++ 		"k": 2,
+  	},
+  	Next: &cmp.user{
+- 		Name: "c",
++ 		Name: "d",
+  		...
+  	},
+  }
+`,
+	}, {
+		x:    x,
+		y:    y,
+		opts: []Option{FilterPath(func(p Path) bool { return len(p) == 2 && p.Last().String() != ".Age" }, Ignore())},
+		want: "",
+	}, {
+		x:    1,
+		y:    2,
+		opts: []Option{Colorize()},
+		want: "\x1b[31m- 1\x1b[0m\n\x1b[32m+ 2\x1b[0m\n",
+	}, {
+		x: []int{1, 2, 3},
+		y: []int{1, 2, 4},
+		opts: []Option{Transformer("Neg", func(s []int) []int {
+			n := make([]int, len(s))
+			for i := range s {
+				n[i] = -s[i]
+			}
+			return n
+		})},
+		want: `  Neg([]int{
+  	...
+- 	[2]: -3,
++ 	[2]: -4,
+  })
+`,
+	}}
+
+	for i, tt := range tests {
+		got := DiffTree(tt.x, tt.y, tt.opts...)
+		if got != tt.want {
+			t.Errorf("test %d, DiffTree mismatch:\ngot:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+		if (got == "") != Equal(tt.x, tt.y, tt.opts...) {
+			t.Errorf("test %d, DiffTree and Equal are inconsistent", i)
+		}
+	}
+}
+
+func TestDiffTreeOneSided(t *testing.T) {
+	// A node with children that only exists in y is printed with its type.
+	type pair struct{ A, B int }
+	vy := reflect.ValueOf(pair{1, 2})
+	root := &diffNode{step: &pathStep{vy.Type(), reflect.Value{}, vy}}
+	root.children = []*diffNode{{
+		step:   &pathStep{vy.Field(0).Type(), reflect.Value{}, vy.Field(0)},
+		flags:  reportUnequal,
+		parent: root,
+	}}
+	p := treePrinter{r: new(defaultReporter)}
+	p.print(root, 0, "", "")
+	want := "  cmp.pair{\n+ \t1,\n  }\n"
+	if got := p.b.String(); got != want {
+		t.Errorf("print:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestDiffGoSyntax(t *testing.T) {
+	type user struct {
+		Name   string
+		Age    uint8
+		Groups []string
+		Attrs  map[string]interface{}
+		Next   *user
+		Score  float32
+		Fn     func()
+	}
+	loop := &user{Name: "loop"}
+	loop.Next = loop
+
+	tests := []struct {
+		x, y interface{}
+		opts []Option
+		want string
+	}{{
+		x: user{Name: "Alice"},
+		y: &user{Name: "Bob", Age: 5, Groups: []string{"admin"}, Attrs: map[string]interface{}{"k": int8(1), "j": nil}, Next: &user{}, Score: 1.5},
+		want: `-: cmp.user{
+	Name: "Alice",
+}
++: &cmp.user{
+	Name:   "Bob",
+	Age:    5,
+	Groups: []string{"admin"},
+	Attrs: map[string]interface{}{
+		"j": nil,
+		"k": int8(1),
+	},
+	Next:  &cmp.user{},
+	Score: 1.5,
+}
+`,
+	}, {
+		x:    user{Name: "Alice"},
+		y:    user{Name: "Alice"},
+		want: "",
+	}, {
+		x:    user{Name: "Alice", Age: 1},
+		y:    user{Name: "Alice", Age: 2},
+		opts: []Option{FilterPath(func(p Path) bool { return p.Last().String() == ".Age" }, Ignore())},
+		want: "",
+	}, {
+		x: []*user{nil},
+		y: []*user{loop},
+		want: `-: []*cmp.user{
+	nil,
+}
++: []*cmp.user{
+	{
+		Name: "loop",
+		Next: nil, /* cycle */
+	},
+}
+`,
+	}, {
+		x:    map[[2]int]float64{{1, 2}: 3},
+		y:    map[[2]int]float64{{1, 2}: math.Inf(+1)},
+		want: "-: map[[2]int]float64{\n\t{1, 2}: 3.0,\n}\n+: map[[2]int]float64{\n\t{1, 2}: math.Inf(+1),\n}\n",
+	}, {
+		x:    []interface{}{uint(1), "a", nil},
+		y:    []interface{}{1, "a", user{Fn: func() {}}},
+		want: "-: []interface{}{\n\tuint(1),\n\t\"a\",\n\tnil,\n}\n+: []interface{}{\n\t1,\n\t\"a\",\n\tcmp.user{\n\t\tFn: nil, /* non-nil func */\n\t},\n}\n",
+	}}
+
+	for i, tt := range tests {
+		got := DiffGoSyntax(tt.x, tt.y, tt.opts...)
+		if got != tt.want {
+			t.Errorf("test %d, DiffGoSyntax mismatch:\ngot:\n%s\nwant:\n%s", i, got, tt.want)
+		}
+		if (got == "") != Equal(tt.x, tt.y, tt.opts...) {
+			t.Errorf("test %d, DiffGoSyntax and Equal are inconsistent", i)
+		}
+		if i := strings.Index(got, "\n+: "); i >= 0 {
+			if _, err := parser.ParseExpr(got[i+len("\n+: "):]); err != nil {
+				t.Errorf("test %d, cannot parse y as Go expression: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	type record struct {
+		Name  string `json:"name"`
+		Tags  []string
+		Attrs map[string]int
+		Skip  int
+	}
+	x := record{Name: "a", Tags: []string{"x", "y"}, Attrs: map[string]int{"k": 1, "old": 2}, Skip: 1}
+	y := record{Name: "b", Tags: []string{"x", "y", "z"}, Attrs: map[string]int{"k": 1, "new": 3}, Skip: 2}
+	opts := []Option{
+		FieldNamesByTag("json"),
+		FilterPath(func(p Path) bool { return p.Last().String() == ".Skip" }, Ignore()),
+	}
+
+	got, err := DiffJSON(x, y, opts...)
+	if err != nil {
+		t.Fatalf("DiffJSON error: %v", err)
+	}
+	want := `[` +
+		`{"path":"{cmp.record}.name","kind":"modified","old":"\"a\"","new":"\"b\""},` +
+		`{"path":"{cmp.record}.Tags[?-\u003e2]","kind":"inserted","new":"\"z\""},` +
+		`{"path":"{cmp.record}.Attrs[\"new\"]","kind":"inserted","new":"3"},` +
+		`{"path":"{cmp.record}.Attrs[\"old\"]","kind":"removed","old":"2"}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("DiffJSON mismatch:\ngot:  %s\nwant: %s", got, want)
+	}
+
+	got, err = DiffJSON(x, x)
+	if err != nil || string(got) != "[]" {
+		t.Errorf("DiffJSON(x, x) = (%s, %v), want ([], nil)", got, err)
+	}
+}
+
+func TestDiffBytes(t *testing.T) {
+	type blob struct{ B []byte }
+	x := make([]byte, 100)
+	y := make([]byte, 90)
+	y[50], y[51] = 1, 'a'
+
+	got := Diff(blob{x}, blob{y})
+	want := `{cmp.blob}.B:
+	          -: 100 bytes                         +: 90 bytes
+	  ... 48 identical bytes ...
+	00000030  00 00 00 00 00 00 00 00  |........|  00 00 01 61 00 00 00 00  |...a....|
+	  ... 32 identical bytes ...
+	00000058  00 00 00 00 00 00 00 00  |........|  00 00                    |..|
+	00000060  00 00 00 00              |....|
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Short byte slices are still reported element by element.
+	got = Diff([]byte("abc"), []byte("abd"))
+	want = `{[]uint8}[2]:
+	-: 0x63
+	+: 0x64
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMaxDiffsPerPath(t *testing.T) {
+	type tuple struct{ A, B, C int }
+	x := map[string]tuple{"bad": {1, 2, 3}, "good": {1, 2, 3}}
+	y := map[string]tuple{"bad": {4, 5, 6}, "good": {1, 2, 4}}
+
+	got := Diff(x, y, MaxDiffsPerPath(1))
+	want := `{map[string]cmp.tuple}["bad"].A:
+	-: 1
+	+: 4
+{map[string]cmp.tuple}["bad"]:
+	... 2 more differences under this path ...
+{map[string]cmp.tuple}["good"].C:
+	-: 3
+	+: 4
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Diff(x, y, MaxDiffsPerPath(3)); got != Diff(x, y) {
+		t.Errorf("Diff with large limit mismatch:\ngot:\n%s\nwant:\n%s", got, Diff(x, y))
+	}
+}
+
+func TestMaxDiffsPerContainer(t *testing.T) {
+	type tuple struct{ K, V int }
+	type pair struct{ A, B []tuple }
+	x := pair{A: []tuple{{1, 1}, {2, 2}, {3, 3}}, B: []tuple{{1, 1}, {2, 2}}}
+	y := pair{A: []tuple{{1, 0}, {2, 0}, {3, 0}}, B: []tuple{{1, 0}, {2, 2}}}
+
+	got := Diff(x, y, MaxDiffsPerContainer(1))
+	want := `{cmp.pair}.A[0].V:
+	-: 1
+	+: 0
+{cmp.pair}.A:
+	... 2 more differing elements ...
+{cmp.pair}.B[0].V:
+	-: 1
+	+: 0
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Diff(x, y, MaxDiffsPerContainer(3)); got != Diff(x, y) {
+		t.Errorf("Diff with large limit mismatch:\ngot:\n%s\nwant:\n%s", got, Diff(x, y))
+	}
+}
+
+func TestMaxReportSize(t *testing.T) {
+	x := make([]int, 10)
+	y := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	got := Diff(x, y, MaxReportSize(0, 4))
+	want := `{[]int}[0]:
+	-: 0
+	+: 1
+{[]int}[1]:
+	-: 0
+	+: 2
+... 8 more differences ...`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	want = `{[]int}[0]:
+	-: 0
+	+: 1
+... 9 more differences ...`
+	if got := Diff(x, y, MaxReportSize(20, 0)); got != want {
+		t.Errorf("Diff with byte limit mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	long := make([]int, 1000)
+	if got := Diff(long, make([]int, 0), MaxReportSize(1<<20, 1<<20)); strings.Contains(got, "more differences") {
+		t.Errorf("Diff with large limits is truncated:\n%s", got)
+	}
+	if got := Diff(long, make([]int, 0)); !strings.Contains(got, "more differences") {
+		t.Errorf("Diff with default limits is not truncated:\n%s", got)
+	}
+}
+
+func TestMaxDiffs(t *testing.T) {
+	x := make(map[int]int)
+	y := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		x[i], y[i] = i, -i
+	}
+
+	var n int
+	opt := Comparer(func(x, y int) bool { n++; return x == y })
+	got := Diff(x, y, opt, MaxDiffs(2))
+	want := `{map[int]int}[1] (entry modified):
+	-: 1
+	+: -1
+{map[int]int}[2] (entry modified):
+	-: 2
+	+: -2
+... at least 1 more differences ...`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if n >= len(x) {
+		t.Errorf("compared %d entries, want the comparison to stop early", n)
+	}
+
+	// The comparison is not stopped early if the report is verbose.
+	n = 0
+	if got := Diff(x, y, opt, MaxDiffs(2), Verbose()); strings.Contains(got, "more differences") {
+		t.Errorf("Diff with Verbose is truncated:\n%s", got)
+	}
+	if n < len(x) {
+		t.Errorf("compared %d entries with Verbose, want at least %d", n, len(x))
+	}
+}
+
+func TestVerbose(t *testing.T) {
+	type tuple struct {
+		A []interface{}
+		D time.Duration
+	}
+	x := tuple{A: []interface{}{[]int{1, 2}}, D: time.Second}
+	y := tuple{A: []interface{}{map[string]uint8{"a": 2}}, D: time.Minute}
+
+	got := Diff(x, y, Verbose())
+	want := `{cmp.tuple}.A[0] (type mismatch: []int vs map[string]uint8):
+	-: []int{int(1), int(2)}
+	+: map[string]uint8{string("a"): uint8(0x02)}
+{cmp.tuple}.D:
+	-: time.Duration(1000000000)
+	+: time.Duration(60000000000)
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	long := make([]int, 1000)
+	got = Diff(long, make([]int, 0), Verbose(), MaxDiffs(1), MaxDiffsPerContainer(1))
+	if strings.Contains(got, "more") {
+		t.Errorf("Diff with Verbose is truncated:\n%s", got[len(got)-100:])
+	}
+	if n := strings.Count(got, "(element removed)"); n != len(long) {
+		t.Errorf("Diff with Verbose reported %d elements, want %d", n, len(long))
+	}
+}
+
+func TestColorize(t *testing.T) {
+	x := map[string]interface{}{"a": 1, "b": 2, "c": "1\n2\n3\n"}
+	y := map[string]interface{}{"a": 3, "c": "1\n3\n4\n", "d": 4}
+
+	got := Diff(x, y, Colorize())
+	want := "root[\"a\"]:\n" +
+		"\t\x1b[31m-: 1\x1b[0m\n" +
+		"\t\x1b[32m+: 3\x1b[0m\n" +
+		"root[\"b\"] (entry removed):\n" +
+		"\t\x1b[31m-: 2\x1b[0m\n" +
+		"root[\"c\"]:\n" +
+		"\t  1\n" +
+		"\t\x1b[31m- 2\x1b[0m\n" +
+		"\t\x1b[31m- 3\x1b[0m\n" +
+		"\t\x1b[32m+ 3\x1b[0m\n" +
+		"\t\x1b[32m+ 4\x1b[0m\n" +
+		"root[\"d\"] (entry added):\n" +
+		"\t\x1b[32m+: 4\x1b[0m\n"
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:  %q\nwant: %q", got, want)
+	}
+	if got := Diff(x, y); strings.Contains(got, "\x1b") {
+		t.Errorf("Diff without Colorize contains escape sequences: %q", got)
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	type counters struct{ Reads, Writes int64 }
+	x := counters{Reads: 1234567890, Writes: 10}
+	y := counters{Reads: 1234568890, Writes: 10}
+
+	got := Diff(x, y, GroupDigits())
+	want := `{cmp.counters}.Reads:
+	-: 1_234_567_890
+	+: 1_234_568_890
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFloatExponentRange(t *testing.T) {
+	x := []float64{1e-7, 0.25}
+	y := []float64{1.0000000000000002e-07, 0.25}
+
+	got := Diff(x, y, FloatExponentRange(-10, 21))
+	want := `{[]float64}[0]:
+	-: 0.0000001
+	+: 0.00000010000000000000002
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEnumNames(t *testing.T) {
+	type status int
+	type job struct {
+		Name   string
+		Status status
+	}
+	names := map[status]string{1: "StatusPending", 2: "StatusActive"}
+	x := []job{{"a", 1}, {"b", 3}}
+	y := []job{{"a", 2}, {"b", 4}}
+
+	got := Diff(x, y, EnumNames(names))
+	want := `{[]cmp.job}[0].Status:
+	-: StatusPending (1)
+	+: StatusActive (2)
+{[]cmp.job}[1].Status:
+	-: cmp.status(3)
+	+: cmp.status(4)
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	type id uint32
+	type record struct {
+		ID   id
+		Tags []string
+	}
+	x := map[string]record{"a": {ID: 0xbeef}}
+	y := map[string]record{"a": {ID: 0xcafe}, "b": {ID: 1, Tags: []string{"x", "y"}}}
+
+	hex := Formatter(func(v id) string { return fmt.Sprintf("ID<%x>", uint32(v)) })
+	tags := Formatter(func(v []string) string { return strings.Join(v, "|") })
+	got := Diff(x, y, hex, tags)
+	want := `{map[string]cmp.record}["a"].ID:
+	-: ID<beef>
+	+: ID<cafe>
+{map[string]cmp.record}["b"] (entry added):
+	+: cmp.record{ID: ID<1>, Tags: x|y}
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	type event struct {
+		When    time.Time
+		Payload []byte
+	}
+	when := time.Date(2019, 8, 1, 12, 0, 0, 0, time.UTC)
+	x := map[string]event{"a": {When: when}}
+	y := map[string]event{"a": {When: when.Add(time.Hour)}, "b": {When: when, Payload: []byte("hi")}}
+
+	rfc3339 := FormatValue(func(v interface{}) (string, bool) {
+		if t, ok := v.(time.Time); ok {
+			return t.Format(time.RFC3339), true
+		}
+		return "", false
+	})
+	b64 := FormatValue(func(v interface{}) (string, bool) {
+		if b, ok := v.([]byte); ok {
+			return fmt.Sprintf("base64(%q)", base64.StdEncoding.EncodeToString(b)), true
+		}
+		return "", false
+	})
+	got := Diff(x, y, rfc3339, b64)
+	want := `{map[string]cmp.event}["a"].When:
+	-: 2019-08-01T12:00:00Z
+	+: 2019-08-01T13:00:00Z
+{map[string]cmp.event}["b"] (entry added):
+	+: cmp.event{When: 2019-08-01T12:00:00Z, Payload: base64("aGk=")}
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+type panicStringer struct{ A int }
+
+func (panicStringer) String() string { panic("boom") }
+
+func TestDiffPanickingStringer(t *testing.T) {
+	got := Diff([]panicStringer{{1}}, []panicStringer{{1}, {2}})
+	want := `{[]cmp.panicStringer}[?->1] (element inserted):
+	+: cmp.panicStringer{A: 2} (String method panicked: boom)
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+type pathError struct{ Op, Path string }
+
+func (e *pathError) Error() string { return e.Op + " " + e.Path + ": file does not exist" }
+
+func TestDiffErrors(t *testing.T) {
+	type result struct{ Err error }
+	x := result{&pathError{"open", "a.txt"}}
+	y := result{&pathError{"open", "b.txt"}}
+
+	got := Diff(x, y)
+	want := `{cmp.result}.Err.Path:
+	-: "a.txt"
+	+: "b.txt"
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	opt := Comparer(func(x, y *pathError) bool { return *x == *y })
+	got = Diff(x, y, opt)
+	want = `{cmp.result}.Err:
+	-: e"open a.txt: file does not exist"
+	+: e"open b.txt: file does not exist"
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = Diff(x, y, opt, ErrorDetails())
+	want = `{cmp.result}.Err:
+	-: e"open a.txt: file does not exist" &cmp.pathError{Op: "open", Path: "a.txt"}
+	+: e"open b.txt: file does not exist" &cmp.pathError{Op: "open", Path: "b.txt"}
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// editReporter records the edit kind of every unequal leaf node.
+type editReporter struct{ edits []string }
+
+func (r *editReporter) PushStep(PathStep) {}
+func (r *editReporter) Report(p Path, f reportFlags) {
+	if f&reportUnequal > 0 {
+		r.edits = append(r.edits, fmt.Sprintf("%#v: %v", p, p.Edit()))
+	}
+}
+func (r *editReporter) PopStep() {}
+
+func TestPathEdit(t *testing.T) {
+	type record struct {
+		Old, New []string
+		Map      map[string]int
+	}
+	x := record{[]string{"a", "b"}, []string{"a"}, map[string]int{"a": 1, "b": 2}}
+	y := record{[]string{"a"}, []string{"a", "b"}, map[string]int{"b": 3, "c": 4}}
+
+	r := new(editReporter)
+	Equal(x, y, reporter(r))
+	got := strings.Join(r.edits, "\n")
+	want := strings.Join([]string{
+		`{cmp.record}.Old[1->?]: removed`,
+		`{cmp.record}.New[?->1]: inserted`,
+		`{cmp.record}.Map["a"]: removed`,
+		`{cmp.record}.Map["b"]: modified`,
+		`{cmp.record}.Map["c"]: inserted`,
+	}, "\n")
+	if got != want {
+		t.Errorf("edits mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFieldNamesByTag(t *testing.T) {
+	type address struct {
+		City string `json:"city,omitempty"`
+		Zip  string `json:"-"`
+	}
+	type person struct {
+		Name    string `json:"full_name"`
+		Home    *address
+		Aliases map[string]address `json:"aliases"`
+	}
+	x := person{"Ann", &address{"Paris", "75001"}, map[string]address{"a": {City: "Oslo"}}}
+	y := person{"Ann", &address{"Rome", "00100"}, map[string]address{}}
+
+	got := Diff(x, y, FieldNamesByTag("json"))
+	want := `{cmp.person}.Home.city:
+	-: "Paris"
+	+: "Rome"
+{cmp.person}.Home.Zip:
+	-: "75001"
+	+: "00100"
+{cmp.person}.aliases["a"] (entry removed):
+	-: cmp.address{city: "Oslo"}
+`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffTypeMismatch(t *testing.T) {
+	type config struct{ Port interface{} }
+	tests := []struct {
+		x, y interface{}
+		want string
+	}{{
+		x: 8080,
+		y: "8080",
+		want: `root (type mismatch: int vs string):
+	-: 8080
+	+: "8080"
+`,
+	}, {
+		x: config{8080},
+		y: config{int64(8080)},
+		want: `{cmp.config}.Port (type mismatch: int vs int64):
+	-: int(8080)
+	+: int64(8080)
+`,
+	}, {
+		x:    config{nil},
+		y:    config{8080},
+		want: "{cmp.config}.Port:\n\t-: interface {}(nil)\n\t+: 8080\n",
+	}}
+	for _, tt := range tests {
+		if got := Diff(tt.x, tt.y); got != tt.want {
+			t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	type point struct{ X, Y int }
+	r := NewReport(MaxDiffsPerPath(1))
+	tests := []struct {
+		label string
+		x, y  point
+	}{
+		{"origin", point{0, 0}, point{0, 0}},
+		{"x-axis", point{1, 0}, point{2, 0}},
+		{"diagonal", point{1, 1}, point{2, 2}},
+	}
+	for _, tt := range tests {
+		r.Compare(tt.label, tt.x, tt.y)
+	}
+	r.Compare("ignored", point{1, 1}, point{1, 2}, FilterPath(func(p Path) bool { return p.String() == "Y" }, Ignore()))
+
+	if r.Equal() {
+		t.Errorf("Equal() = true, want false")
+	}
+	if total, failed := r.NumCompared(); total != 4 || failed != 2 {
+		t.Errorf("NumCompared() = (%d, %d), want (4, 2)", total, failed)
+	}
+	got := r.String()
+	want := `--- x-axis ---
+{cmp.point}.X:
+	-: 1
+	+: 2
+--- diagonal ---
+{cmp.point}.X:
+	-: 1
+	+: 2
+{cmp.point}.Y:
+	-: 1
+	+: 2
+2 of 4 comparisons found differences
+`
+	if got != want {
+		t.Errorf("String() mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := NewReport().String(); got != "" {
+		t.Errorf("empty Report String() = %q, want empty", got)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	type counters struct{ A, B, C int }
+	x := counters{1000, 2000, 3000}
+	y := counters{1001, 2001, 3001}
+
+	cfg := Config{MaxDifferences: 2, GroupDigits: true}
+	got := cfg.Diff(x, y)
+	want := `{cmp.counters}.A:
+	-: 1_000
+	+: 1_001
+{cmp.counters}.B:
+	-: 2_000
+	+: 2_001
+... at least 1 more differences ...`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Diff(x, y, cfg); got != want {
+		t.Errorf("Diff with Config option mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if cfg.Equal(x, y) {
+		t.Errorf("Equal() = true, want false")
+	}
+
+	got = Config{MaxOutput: 10}.Diff(x, y)
+	want = `{cmp.counters}.A:
+	-: 1000
+	+: 1001
+... 2 more differences ...`
+	if got != want {
+		t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := (Config{}).Diff(x, y); got != Diff(x, y) {
+		t.Errorf("Diff with zero Config mismatch:\ngot:\n%s\nwant:\n%s", got, Diff(x, y))
+	}
+
+	type node struct {
+		P    *int
+		Tags []string `cmp:"unordered"`
+	}
+	one1, one2 := 1, 1
+	nx := []node{{&one1, []string{"a", "b"}}}
+	ny := []node{{&one2, []string{"b", "a"}}}
+	if !(Config{UseTags: "cmp", Parallelism: 4}).Equal(nx, ny) {
+		t.Errorf("Equal with UseTags and Parallelism = false, want true")
+	}
+	if (Config{UseTags: "cmp", MaxDepth: 2}).Equal(nx, ny) {
+		t.Errorf("Equal with MaxDepth = true, want false")
+	}
+	if got, want := (Config{MaxOutputLines: 3}).Diff(x, y), Diff(x, y, MaxReportSize(0, 3)); got != want {
+		t.Errorf("Diff with MaxOutputLines mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffMultilineStrings(t *testing.T) {
+	lines := func(s ...string) string { return strings.Join(s, "\n") + "\n" }
+	tests := []struct {
+		x, y interface{}
+		want string
+	}{{
+		x: lines("a", "b", "c"),
+		y: lines("a", "B", "c"),
+		want: `{string}:
+	  a
+	- b
+	+ B
+	  c
+`,
+	}, {
+		x: lines("1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14"),
+		y: lines("1", "2", "3", "4", "5", "six", "7", "8", "9", "10", "11", "12", "13", "14", "15"),
+		want: `{string}:
+	  ... 2 identical lines ...
+	  3
+	  4
+	  5
+	- 6
+	+ six
+	  7
+	  8
+	  9
+	  ... 2 identical lines ...
+	  12
+	  13
+	  14
+	+ 15
+`,
+	}, {
+		x: map[string]interface{}{"config": "port: 80\nhost: a"},
+		y: map[string]interface{}{"config": "port: 80\nhost: b\n"},
+		want: `root["config"]:
+	  port: 80
+	- host: a
+	+ host: b
+	+ 
+`,
+	}, {
+		x:    "a\n",
+		y:    "b\n",
+		want: "{string}:\n\t-: \"a\\n\"\n\t+: \"b\\n\"\n",
+	}}
+	for _, tt := range tests {
+		if got := Diff(tt.x, tt.y); got != tt.want {
+			t.Errorf("Diff mismatch:\ngot:\n%s\nwant:\n%s", got, tt.want)
+		}
+	}
+}
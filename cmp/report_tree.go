@@ -0,0 +1,190 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// diffNode is a node in the tree of every value visited by a comparison.
+type diffNode struct {
+	step     PathStep    // A retained copy of the step to this node
+	flags    reportFlags // What was reported for this node, if anything
+	parent   *diffNode
+	children []*diffNode
+}
+
+// path returns the Path from the root to n.
+func (n *diffNode) path() Path {
+	var p Path
+	for ; n != nil; n = n.parent {
+		p = append(p, n.step)
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// numDiffs reports the number of unequal nodes at or beneath n.
+func (n *diffNode) numDiffs() int {
+	nd := 0
+	if n.flags&reportUnequal > 0 {
+		nd++
+	}
+	for _, c := range n.children {
+		nd += c.numDiffs()
+	}
+	return nd
+}
+
+// treeReporter is a reporter that builds a tree of diffNodes.
+type treeReporter struct {
+	root, cur *diffNode
+}
+
+func (r *treeReporter) PushStep(ps PathStep) {
+	n := &diffNode{step: copyStep(ps), parent: r.cur}
+	if r.cur == nil {
+		r.root = n
+	} else {
+		r.cur.children = append(r.cur.children, n)
+	}
+	r.cur = n
+}
+func (r *treeReporter) Report(p Path, f reportFlags) {
+	r.cur.flags |= f
+}
+func (r *treeReporter) PopStep() {
+	r.cur = r.cur.parent
+}
+
+// diffTree compares x and y and returns the root of the resulting tree.
+func (s *state) diffTree(x, y interface{}) *diffNode {
+	r := new(treeReporter)
+	s.reporters = append(s.reporters, reporterOption{r})
+	s.compareRoot(x, y)
+	return r.root
+}
+
+// DiffTree returns a human-readable report of the differences between x and y
+// that is laid out like a Go literal of the enclosing values, so that it is
+// easy to see where in a large value each difference is located.
+// Values and elements that are equal are elided as "...", while the lines of
+// differing values are prefixed by "-" for x and "+" for y.
+// It returns an empty string if and only if Equal returns true for
+// the same inputs. The options that configure the report from Diff,
+// such as Colorize and GroupDigits, also apply to DiffTree.
+//
+// For example:
+//	  cmp.User{
+//	  	...
+//	- 	Name: "Alice",
+//	+ 	Name: "Bob",
+//	  	Groups: []string{
+//	  		...
+//	+ 		[?->2]: "admin",
+//	  	},
+//	  }
+//
+// Do not depend on this output being stable.
+func DiffTree(x, y interface{}, opts ...Option) string {
+	s := newState(opts)
+	r := s.newReporter()
+	root := s.diffTree(x, y)
+	if root.numDiffs() == 0 {
+		return ""
+	}
+	p := treePrinter{r: r}
+	p.print(root, 0, "", "")
+	return p.b.String()
+}
+
+// treePrinter formats a tree of diffNodes for DiffTree.
+type treePrinter struct {
+	r *defaultReporter // Provides the configuration for printing values
+	b bytes.Buffer
+}
+
+// print prints the node n at the given depth, where label and suffix are
+// printed before and after the value of n (e.g., a struct field name).
+func (p *treePrinter) print(n *diffNode, depth int, label, suffix string) {
+	comma := ","
+	if depth == 0 {
+		comma = ""
+	}
+	indent := strings.Repeat("\t", depth)
+
+	// Steps that do not represent a level of nesting in a Go literal
+	// are printed as part of the label of the value they lead to.
+	if n.flags&reportUnequal == 0 && len(n.children) == 1 {
+		switch c := n.children[0]; c.step.(type) {
+		case Indirect:
+			p.print(c, depth, label+"&", suffix)
+			return
+		case TypeAssertion:
+			p.print(c, depth, label, suffix)
+			return
+		case Transform:
+			p.print(c, depth, label+c.step.(Transform).Name()+"(", ")"+suffix)
+			return
+		}
+	}
+
+	vx, vy := n.step.Values()
+	if n.flags&reportUnequal > 0 || len(n.children) == 0 {
+		conf := p.r.format
+		conf.UseStringer = true
+		conf.UseError = true
+		if vx.IsValid() {
+			p.line("-", indent+label+value.Format(vx, conf)+suffix+comma)
+		}
+		if vy.IsValid() {
+			p.line("+", indent+label+value.Format(vy, conf)+suffix+comma)
+		}
+		return
+	}
+
+	t := vy.Type()
+	if vx.IsValid() {
+		t = vx.Type()
+	}
+	p.line(" ", indent+label+t.String()+"{")
+	var elided bool
+	for _, c := range n.children {
+		if c.numDiffs() == 0 {
+			if !elided {
+				p.line(" ", indent+"\t...")
+			}
+			elided = true
+			continue
+		}
+		elided = false
+		p.print(c, depth+1, stepLabel(c.step), "")
+	}
+	p.line(" ", indent+"}"+suffix+comma)
+}
+
+// line prints a single line of the report with the given marker.
+func (p *treePrinter) line(mark, s string) {
+	p.b.WriteString(p.r.paint(mark, mark+" "+s) + "\n")
+}
+
+// stepLabel returns the label that precedes the value of a step
+// within the literal of its parent.
+func stepLabel(ps PathStep) string {
+	switch ps := ps.(type) {
+	case StructField:
+		return ps.Name() + ": "
+	case MapIndex:
+		return value.Format(ps.Key(), value.FormatConfig{}) + ": "
+	case SliceIndex:
+		return ps.String() + ": "
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Stats are statistics about the work performed by one or more calls to
+// Equal or Diff. They can be used to track the cost of expensive comparisons
+// and to spot pathological sets of options.
+//
+// Work performed while searching for a good alignment of slice elements is
+// included, so a node may be visited (and a Comparer invoked) more than once.
+//
+// The zero value is ready for use. The fields must not be accessed while the
+// Stats are being recorded into by a concurrent comparison.
+type Stats struct {
+	Calls        int           // Number of calls to Equal or Diff
+	Nodes        int           // Number of nodes visited in the value trees
+	Comparers    int           // Number of times a Comparer was invoked
+	Transformers int           // Number of times a Transformer was applied
+	EqualMethods int           // Number of times an Equal method was invoked
+	Duration     time.Duration // Total wall time spent comparing
+}
+
+// statsMu protects all Stats being recorded into by the RecordStats option.
+var statsMu sync.Mutex
+
+func (st *Stats) add(w Stats) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	st.Calls += w.Calls
+	st.Nodes += w.Nodes
+	st.Comparers += w.Comparers
+	st.Transformers += w.Transformers
+	st.EqualMethods += w.EqualMethods
+	st.Duration += w.Duration
+}
+
+// RecordStats returns an Option that adds the statistics of every comparison
+// to st once the comparison completes.
+func RecordStats(st *Stats) Option {
+	if st == nil {
+		panic("invalid nil Stats")
+	}
+	return statsOption{st}
+}
+
+type statsOption struct{ st *Stats }
+
+func (statsOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
@@ -0,0 +1,64 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"io"
+	"reflect"
+)
+
+// OnDifference returns an Option that calls f with every node in the value
+// tree that is unequal or ignored, in the same order as the list returned by
+// DiffResult, as the comparison proceeds. This permits processing the
+// differences of very large comparisons without retaining all of them.
+//
+// Differences are not always reported as soon as they are found.
+// The differences within the elements of a slice or array are only reported
+// once all of its elements have been aligned, which requires comparing them
+// first. The events of a bounded number of these comparisons are buffered
+// in the meantime, and the remaining ones are compared again when reported.
+// Similarly, the Parallel option buffers the events of each element that
+// it compares until the preceding elements are reported.
+func OnDifference(f func(Difference)) Option {
+	if f == nil {
+		panic("invalid nil difference function")
+	}
+	return reporter(differenceFunc(f))
+}
+
+type differenceFunc func(Difference)
+
+func (differenceFunc) PushStep(PathStep) {}
+func (f differenceFunc) Report(p Path, rf reportFlags) {
+	if rf&(reportUnequal|reportIgnored) != 0 {
+		f(newDifference(p, rf))
+	}
+}
+func (differenceFunc) PopStep() {}
+
+// WriteDiffs returns an Option that writes the report of the differences
+// to w as the comparison proceeds, rather than building the entire report in
+// memory, which permits streaming the report of very large comparisons
+// (e.g., to a log file). Differences are written when they are reported,
+// which is subject to the same buffering as documented by OnDifference.
+// The report is formatted in the same way as by Diff,
+// including the effects of options such as Colorize and GroupDigits.
+// The limits that MaxReportSize places on the size of the report do not apply,
+// while differences beyond the limit of MaxDiffs are not written.
+//
+// Errors returned by w are ignored, so w should retain any error itself
+// for the caller to check afterwards, as bufio.Writer does.
+func WriteDiffs(w io.Writer) Option {
+	if w == nil {
+		panic("invalid nil writer")
+	}
+	return writeDiffsOption{w}
+}
+
+type writeDiffsOption struct{ w io.Writer }
+
+func (writeDiffsOption) filter(_ *state, _ reflect.Type, _, _ reflect.Value) applicableOption {
+	panic("not implemented")
+}
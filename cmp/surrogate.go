@@ -0,0 +1,69 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var surrogates struct {
+	sync.RWMutex
+	m map[reflect.Type]*transformer // Copied on write, so that it may be shared
+}
+
+// RegisterSurrogate registers a function that converts values of a foreign
+// type with unexported fields (e.g., a vendored type that cannot be modified)
+// into an exported surrogate that can be compared. Once registered,
+// the surrogate is used automatically by every call to Equal and Diff whenever
+// values of that type are compared and neither an option nor an Equal method
+// applies.
+//
+// The function f must be a function "func(T) R", where T is a concrete type
+// and R is a different type. The name is used as with Transformer.
+// Only one surrogate may be registered for each type T.
+//
+// Like the options registered with RegisterOptions, registered surrogates
+// are disabled for a single call by the NoRegisteredOptions option.
+//
+// RegisterSurrogate is intended to be called from init functions,
+// for types whose owners provide no means of comparing them.
+// Where possible, prefer passing a Transformer option to individual calls.
+func RegisterSurrogate(name string, f interface{}) {
+	tr := Transformer(name, f).(*transformer)
+	t := tr.fnc.Type()
+	if tr.typ == nil || tr.typ.Kind() == reflect.Interface || t.Out(0) == t.In(0) {
+		panic(fmt.Sprintf("invalid surrogate function: %T", f))
+	}
+
+	surrogates.Lock()
+	defer surrogates.Unlock()
+	if prev, ok := surrogates.m[tr.typ]; ok {
+		panic(fmt.Sprintf("surrogate for %v already registered: %v", tr.typ, prev))
+	}
+	m := make(map[reflect.Type]*transformer, len(surrogates.m)+1)
+	for t, tr := range surrogates.m {
+		m[t] = tr
+	}
+	m[tr.typ] = tr
+	surrogates.m = m
+}
+
+// registeredSurrogates returns all surrogates registered with RegisterSurrogate.
+func registeredSurrogates() map[reflect.Type]*transformer {
+	surrogates.RLock()
+	defer surrogates.RUnlock()
+	return surrogates.m
+}
+
+func (s *state) trySurrogate(t reflect.Type, vx, vy reflect.Value) bool {
+	tr := s.surrogates[t]
+	if tr == nil {
+		return false
+	}
+	tr.apply(s, vx, vy)
+	return true
+}
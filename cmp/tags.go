@@ -0,0 +1,183 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-cmp/cmp/internal/value"
+)
+
+// UseTags returns an Option that configures how struct fields are compared
+// according to their struct tags under the given key, such that the policy for
+// comparing a type can be declared alongside its definition.
+// The tag value is a comma-separated list of the following directives:
+//	• "-" ignores the field.
+//	• "approx=M" determines floating-point values within the field, including
+//	those nested within its elements and sub-fields, to be equal if they are
+//	within an absolute margin M of each other. NaN and infinite values are
+//	still compared by ==. A nested field with its own margin takes precedence.
+//	• "unordered" compares the elements of a slice or array field regardless of
+//	their order, by sorting both sides by the formatted value of each element
+//	before comparing them.
+//
+// For example, with UseTags("cmp"), the fields of the following type are
+// compared by ignoring Cache, by comparing Weights approximately, and by
+// comparing Tags as a set:
+//
+//	type Model struct {
+//		Cache   map[string]int `cmp:"-"`
+//		Weights []float64      `cmp:"approx=1e-6"`
+//		Tags    []string       `cmp:"unordered"`
+//	}
+//
+// UseTags panics if the key is empty. Equal panics if a tag under the key is
+// invalid, or if "unordered" is used on a field that is not a slice or array.
+func UseTags(key string) Option {
+	if key == "" {
+		panic("invalid empty tag key")
+	}
+	return &tagOption{key: key, fields: make(map[tagField]*fieldTags)}
+}
+
+type tagOption struct {
+	core
+	key string
+
+	mu     sync.Mutex // Guards fields, since comparisons may run concurrently
+	fields map[tagField]*fieldTags
+}
+
+// tagField identifies a struct field.
+type tagField struct {
+	typ   reflect.Type // Type of the parent struct
+	index int
+}
+
+// fieldTags are the directives parsed from the tag of a struct field.
+type fieldTags struct {
+	ignore    bool
+	unordered *transformer // Non-nil if the elements are unordered
+	approx    *comparer    // Non-nil if floating-point values are approximate
+}
+
+func (o *tagOption) filter(s *state, t reflect.Type, _, _ reflect.Value) applicableOption {
+	for i := len(s.curPath) - 1; i > 0; i-- {
+		sf, ok := s.curPath[i].(*structField)
+		if !ok {
+			continue
+		}
+		ft := o.tags(s.curPath[i-1].Type(), sf)
+		if i == len(s.curPath)-1 {
+			switch {
+			case ft.ignore:
+				return ignore{}
+			case ft.unordered != nil:
+				if k := t.Kind(); k != reflect.Slice && k != reflect.Array {
+					panic(fmt.Sprintf("invalid %q tag on field %v.%s: unordered requires a slice or array, not %v", o.key, s.curPath[i-1].Type(), sf.name, k))
+				}
+				return ft.unordered
+			}
+		}
+		if k := t.Kind(); ft.approx != nil && (k == reflect.Float32 || k == reflect.Float64) {
+			return ft.approx
+		}
+		if ft.approx != nil {
+			break // Margins of enclosing fields are overridden
+		}
+	}
+	return nil
+}
+
+// tags returns the parsed tag of the struct field sf of the struct type t.
+func (o *tagOption) tags(t reflect.Type, sf *structField) *fieldTags {
+	k := tagField{t, sf.idx}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if ft, ok := o.fields[k]; ok {
+		return ft
+	}
+	ft := new(fieldTags)
+	tag, ok := t.Field(sf.idx).Tag.Lookup(o.key)
+	for _, d := range strings.Split(tag, ",") {
+		switch {
+		case !ok || d == "":
+		case d == "-":
+			ft.ignore = true
+		case d == "unordered":
+			ft.unordered = &transformer{name: "Unordered", fnc: reflect.ValueOf(sortUnordered)}
+		case strings.HasPrefix(d, "approx="):
+			m, err := strconv.ParseFloat(strings.TrimPrefix(d, "approx="), 64)
+			if err != nil || m < 0 || math.IsNaN(m) {
+				panic(fmt.Sprintf("invalid %q tag on field %v.%s: margin must be a non-negative number: %q", o.key, t, sf.name, d))
+			}
+			ft.approx = &comparer{fnc: reflect.ValueOf(approxMargin(m).equal)}
+		default:
+			panic(fmt.Sprintf("invalid %q tag on field %v.%s: unknown directive %q", o.key, t, sf.name, d))
+		}
+	}
+	o.fields[k] = ft
+	return ft
+}
+
+func (o *tagOption) String() string {
+	return fmt.Sprintf("UseTags(%q)", o.key)
+}
+
+// approxMargin is the margin of the "approx" directive.
+type approxMargin float64
+
+// equal reports whether the floating-point values x and y are within
+// the margin of each other.
+func (m approxMargin) equal(x, y interface{}) bool {
+	fx, fy := reflect.ValueOf(x).Float(), reflect.ValueOf(y).Float()
+	if math.IsNaN(fx) || math.IsNaN(fy) || math.IsInf(fx, 0) || math.IsInf(fy, 0) {
+		return fx == fy
+	}
+	return math.Abs(fx-fy) <= float64(m)
+}
+
+// sortUnordered returns a copy of the slice or array v, where the elements are
+// sorted by their formatted values, for the "unordered" directive.
+func sortUnordered(v interface{}) interface{} {
+	src := reflect.ValueOf(v)
+	var dst reflect.Value
+	if src.Kind() == reflect.Slice {
+		if src.IsNil() {
+			return v
+		}
+		dst = reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+	} else {
+		dst = reflect.New(src.Type()).Elem()
+	}
+	reflect.Copy(dst, src)
+
+	keys := make([]string, dst.Len())
+	for i := range keys {
+		keys[i] = value.Format(dst.Index(i), value.FormatConfig{})
+	}
+	swap := reflect.Swapper(dst.Slice(0, dst.Len()).Interface())
+	sort.Stable(unorderedSorter{keys, swap})
+	return dst.Interface()
+}
+
+// unorderedSorter sorts the elements of a slice by their keys.
+type unorderedSorter struct {
+	keys []string
+	swap func(i, j int)
+}
+
+func (s unorderedSorter) Len() int           { return len(s.keys) }
+func (s unorderedSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s unorderedSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
@@ -0,0 +1,53 @@
+// Copyright 2019, The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE.md file.
+
+package cmp
+
+// TraceOptions returns an Option that calls f for every node in the value tree
+// with the option that determined how the node was compared. When many
+// Comparers, Transformers, and filters are combined, this explains why a node
+// compared as equal or was ignored.
+//
+// The opt passed to f is the top-level option that applied to the node,
+// such as a Comparer, Transformer, or Ignore option, or a filter that
+// contains one, which describes itself through its String method.
+// An Options value is never passed to f, but rather the option within it
+// that applied.
+// If no option applied, then opt is nil and the node was compared by
+// its Equal method or, by default, by recursively comparing its sub-values.
+// The sub-values of a transformed node are traced in their transformed form.
+// The validity of p is limited as documented on Path.
+func TraceOptions(f func(p Path, opt Option)) Option {
+	if f == nil {
+		panic("invalid nil trace function")
+	}
+	return reporter(traceReporter(f))
+}
+
+// optionReporter is implemented by reporters that need to know which option
+// applied to each node. ReportOption is called for every node before
+// the node is compared, with a nil Option if no option applied.
+type optionReporter interface {
+	ReportOption(Path, Option)
+}
+
+// reportOption forwards the option that applied to the current node
+// to every optionReporter.
+func (s *state) reportOption(opt Option) {
+	if !s.tracing {
+		return
+	}
+	for _, r := range s.reporters {
+		if or, ok := r.reporterIface.(optionReporter); ok {
+			or.ReportOption(s.curPath, opt)
+		}
+	}
+}
+
+type traceReporter func(Path, Option)
+
+func (traceReporter) PushStep(PathStep)                 {}
+func (traceReporter) Report(Path, reportFlags)          {}
+func (r traceReporter) ReportOption(p Path, opt Option) { r(p, opt) }
+func (traceReporter) PopStep()                          {}